@@ -34,6 +34,10 @@ type Config struct {
 	AccessTTL             time.Duration `conf:"default:15m"`
 	RefreshTTL            time.Duration `conf:"default:24h"`
 	PasswordSalt          string        `conf:"required,mask,notzero"`
+	PdfFontPath           string        `conf:"default:/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"`
+	HtmlTemplatesDir      string
+	// CsvProfilesPath json file with csv export profiles, profiles stored in database are used besides them
+	CsvProfilesPath string
 }
 
 func main() {
@@ -51,6 +55,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	var csvProfiles []exporter.CsvProfile
+	if cfg.CsvProfilesPath != "" {
+		csvProfiles, err = exporter.LoadCsvProfiles(cfg.CsvProfilesPath)
+		if err != nil {
+			logger.Error("Load csv profiles error", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -67,8 +80,20 @@ func main() {
 	}
 
 	repo := repository.NewPostgresRepository(db.DB())
-	exp := exporter.NewExporterFactory(repo, logger, exporter.CsvDelimeter(';'))
+	exp, err := exporter.NewExporterFactory(
+		repo,
+		logger,
+		exporter.CsvDelimeter(';'),
+		exporter.CsvProfiles(csvProfiles...),
+		exporter.PdfFontPath(cfg.PdfFontPath),
+		exporter.HtmlTemplatesDir(cfg.HtmlTemplatesDir),
+	)
+	if err != nil {
+		logger.Error("Create exporter factory error", "error", err)
+		os.Exit(1)
+	}
 	authSvc := services.NewAuthorizationService(repo)
+	calendarSvc := services.NewAcademicCalendarService(repo)
 	tokenSvc := token.NewTokenService(
 		cfg.AccessTokenSecret,
 		cfg.RefreshTokenSecret,
@@ -83,10 +108,13 @@ func main() {
 		usecases.NewEduGroupUsecase(authSvc, repo, logger),
 		usecases.NewEduPlanUsecase(authSvc, repo, logger),
 		usecases.NewFacultyUsecase(authSvc, repo, logger),
-		usecases.NewScheduleUsecase(authSvc, repo, exp, logger),
+		usecases.NewScheduleUsecase(authSvc, calendarSvc, repo, exp, logger),
 		usecases.NewTeacherUsecase(authSvc, repo, logger),
 		usecases.NewCabinetUsecase(authSvc, repo, logger),
 		usecases.NewUserUsecase(authSvc, pwdSvc, tokenSvc, repo, logger),
+		usecases.NewTimetableUsecase(authSvc, calendarSvc, repo, exp, logger),
+		usecases.NewAcademicYearUsecase(authSvc, repo, logger),
+		usecases.NewUsageUsecase(authSvc, repo, logger),
 		logger,
 	)
 
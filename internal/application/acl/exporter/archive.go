@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const ArchiveManifestName = "manifest.json"
+
+// ArchiveManifest describes files of archive with exported schedules
+type ArchiveManifest struct {
+	FacultyID   uuid.UUID      `json:"faculty_id"`
+	FacultyName string         `json:"faculty_name"`
+	Format      string         `json:"format"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Files       []ArchiveEntry `json:"files"`
+}
+
+type ArchiveEntry struct {
+	File            string    `json:"file"`
+	ScheduleID      uuid.UUID `json:"schedule_id"`
+	ScheduleVersion int       `json:"schedule_version"`
+	EduGroupID      uuid.UUID `json:"edu_group_id"`
+	EduGroupNumber  string    `json:"edu_group_number"`
+	Semester        int       `json:"semester"`
+	ExportedAt      time.Time `json:"exported_at"`
+}
+
+// ArchiveWriter writes exported schedules into zip archive, manifest is written last on Close
+type ArchiveWriter struct {
+	zw       *zip.Writer
+	manifest ArchiveManifest
+	names    map[string]int
+}
+
+func NewArchiveWriter(dst io.Writer, manifest ArchiveManifest) *ArchiveWriter {
+	manifest.Files = nil
+
+	return &ArchiveWriter{
+		zw:       zip.NewWriter(dst),
+		manifest: manifest,
+		names:    make(map[string]int),
+	}
+}
+
+// Add writes file of schedule named by group number and semester. File name and export time of entry are filled
+func (a *ArchiveWriter) Add(entry ArchiveEntry, export func(dst io.Writer) error) error {
+	entry.File = a.fileName(entry)
+	entry.ExportedAt = time.Now()
+
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: entry.File, Method: zip.Deflate, Modified: entry.ExportedAt})
+	if err != nil {
+		return fmt.Errorf("create archive entry %s error: %w", entry.File, err)
+	}
+
+	if err := export(w); err != nil {
+		return err
+	}
+
+	a.manifest.Files = append(a.manifest.Files, entry)
+
+	return nil
+}
+
+// Close writes manifest and finishes archive
+func (a *ArchiveWriter) Close() error {
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: ArchiveManifestName, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("create archive manifest error: %w", err)
+	}
+
+	if a.manifest.Files == nil {
+		a.manifest.Files = []ArchiveEntry{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(a.manifest); err != nil {
+		return fmt.Errorf("write archive manifest error: %w", err)
+	}
+
+	return a.zw.Close()
+}
+
+// fileName forms name like "ИВТ-21-3.pdf", group numbers repeated in archive get numeric suffix
+func (a *ArchiveWriter) fileName(entry ArchiveEntry) string {
+	number := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+
+		return r
+	}, strings.TrimSpace(entry.EduGroupNumber))
+
+	base := fmt.Sprintf("%s-%d", number, entry.Semester)
+
+	a.names[base]++
+	if n := a.names[base]; n > 1 {
+		base = fmt.Sprintf("%s (%d)", base, n)
+	}
+
+	return base + "." + a.manifest.Format
+}
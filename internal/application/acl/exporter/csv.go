@@ -9,79 +9,40 @@ import (
 	"log/slog"
 	"schedule-generator/internal/domain/schedules"
 	"strconv"
+	"strings"
+	"time"
 )
 
-var cycledCsvHeader = []string{
-	"Group",
-	"Day",
-	"Les",
-	"Aud",
-	"Week",
-	"Subg",
-	"Name",
-	"Caf",
-	"Subject",
-	"Subj_Type",
-	"Start",
-	"End",
-	"Subj_CafID",
-	"PrepID",
-}
-
-var calendarCsvHeader = []string{
-	"Group",
-	"StudInLesson",
-	"Day",
-	"Les",
-	"Aud",
-	"Week",
-	"Subg",
-	"Name",
-	"CafID",
-	"Subject",
-	"Subj_Type",
-	"Date",
-	"Subj_CafID",
-	"PrepID",
-	"Themas",
-	"Substitution_Name",       // leaved empty
-	"Substitution_PrepID",     // leaved empty
-	"Substitution_Subject",    // leaved empty
-	"Substitution_Subj_type",  // leaved empty
-	"Substitution_Subj_CafID", // leaved empty
-	"Lesson_ID",               // leaved empty
-	"Lesson_Num",              // leaved empty
-}
-
-type scheduleItemHandler func(ctx context.Context, groupNumber string, item schedules.ScheduleItem) ([]string, error)
+// cycledCsvHeader header of cycled schedules written with default profile, expected by csv import
+var cycledCsvHeader = csvHeader(defaultCsvProfile.CycledColumns)
 
 type csvExporter struct {
-	repo      ExporterRepository
-	delimeter rune
-	logger    *slog.Logger
+	repo    ExporterRepository
+	refs    *exportRefs
+	profile CsvProfile
+	logger  *slog.Logger
 }
 
 func (exp *csvExporter) Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error {
-	var header []string
-	var handler scheduleItemHandler
+	var columns []CsvColumn
+	var cycleLength int
 	var listItems []schedules.ScheduleItem
 
 	switch schedule.Type {
 	case schedules.ScheduleTypeCycled:
-		header = cycledCsvHeader
-		handler = exp.cycledScheduleItemHandler
+		columns = exp.profile.CycledColumns
+		cycleLength = schedule.Cycled.CycleLength
 		listItems = schedule.Cycled.ListItem()
 
 	case schedules.ScheduleTypeCalendar:
-		header = calendarCsvHeader
-		handler = exp.calendarScheduleItemHandler
+		columns = exp.profile.CalendarColumns
 		listItems = schedule.Calendar.ListItem()
 
 	default:
 		return errors.New("unsupported schedule type")
 	}
 
-	logger := exp.logger.With("schedule_id", schedule.ID)
+	logger := exp.logger.With("schedule_id", schedule.ID, "profile", exp.profile.Name)
 
 	group, err := exp.repo.GetEduGroup(ctx, schedule.EduGroupID)
 	if err != nil {
@@ -89,125 +50,91 @@ func (exp *csvExporter) Export(ctx context.Context, schedule *schedules.Schedule
 		return err
 	}
 
-	stream := csv.NewWriter(dst)
-	stream.Comma = exp.delimeter
-	stream.Write(header)
+	if err := exp.refs.load(ctx, listItems); err != nil {
+		logger.Error("Load references error", "error", err)
+		return err
+	}
+
+	w, err := exp.profile.encode(dst)
+	if err != nil {
+		logger.Error("Get csv encoding error", "error", err)
+		return err
+	}
+
+	stream := csv.NewWriter(w)
+	stream.Comma = exp.profile.delimiter()
+	stream.Write(csvHeader(columns))
 
 	for _, item := range listItems {
-		row, err := handler(ctx, group.Number, item)
-		if err != nil {
-			logger.Error("Handler schedule item error", "error", err)
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		stream.Write(row)
+		for _, rowItem := range expandCsvItem(item) {
+			row, err := exp.formRow(group.Number, cycleLength, rowItem)
+			if err != nil {
+				logger.Error("Form csv row error", "error", err)
+				return err
+			}
+
+			if err := stream.Write(exp.profile.row(columns, row)); err != nil {
+				logger.Error("Write csv row error", "error", err)
+				return err
+			}
+		}
 	}
 
 	stream.Flush()
-	return nil
-}
-
-func (exp *csvExporter) cycledScheduleItemHandler(ctx context.Context, groupNumber string, item schedules.ScheduleItem) ([]string, error) {
-	var weekType string
-
-	if item.Weektype != nil {
-		switch *item.Weektype {
-		case schedules.WeekTypeEven:
-			weekType = "Ч"
-		case schedules.WeekTypeUneven:
-			weekType = "Н"
-		default:
-			//leave weektype empty
-		}
+	if err := stream.Error(); err != nil {
+		return err
 	}
 
-	var subgroup string
-	if item.Subgroup > 0 {
-		subgroup = strconv.FormatInt(int64(item.Subgroup), 10)
-	}
+	return w.Close()
+}
 
-	teacher, err := exp.repo.GetTeacher(ctx, item.TeacherID)
-	if err != nil {
-		return nil, fmt.Errorf("get teacher error: %w", err)
-	}
+// expandCsvItem splits item into items with single lesson period and single teacher. Downstream system knows
+// nothing about durations and co-teaching, so such lesson is written as row per period and teacher
+func expandCsvItem(item schedules.ScheduleItem) []schedules.ScheduleItem {
+	var result []schedules.ScheduleItem
 
-	department, err := exp.repo.GetDepartment(ctx, teacher.DepartmentID)
-	if err != nil {
-		return nil, fmt.Errorf("get department error: %w", err)
-	}
+	for _, lessonNumber := range item.LessonNumbers() {
+		for _, teacher := range item.Teachers {
+			rowItem := item
+			rowItem.LessonNumber = lessonNumber
+			rowItem.Duration = schedules.DefaultItemDuration
+			rowItem.Teachers = []schedules.ItemTeacher{teacher}
 
-	lessonType, err := formLessonType(item.LessonType)
-	if err != nil {
-		return nil, err
+			result = append(result, rowItem)
+		}
 	}
 
-	return []string{
-		groupNumber,
-		strconv.FormatInt(int64(item.Weekday), 10),
-		strconv.FormatInt(int64(item.LessonNumber)+1, 10),
-		formCabinetAddress(item.Cabinet),
-		weekType,
-		subgroup,
-		teacher.Name,
-		department.ExternalID,
-		item.Discipline,
-		lessonType,
-		"-100",
-		"-100",
-		"0",
-		teacher.ExternalID,
-	}, nil
+	return result
 }
 
-func (exp *csvExporter) calendarScheduleItemHandler(ctx context.Context, groupNumber string, item schedules.ScheduleItem) ([]string, error) {
-	var subgroup string
-	if item.Subgroup > 0 {
-		subgroup = strconv.FormatInt(int64(item.Subgroup), 10)
-	}
-
-	teacher, err := exp.repo.GetTeacher(ctx, item.TeacherID)
+func (exp *csvExporter) formRow(groupNumber string, cycleLength int, item schedules.ScheduleItem) (*csvRow, error) {
+	teacher, err := exp.refs.teacher(item.MainTeacherID())
 	if err != nil {
-		return nil, fmt.Errorf("get teacher error: %w", err)
+		return nil, err
 	}
 
-	department, err := exp.repo.GetDepartment(ctx, teacher.DepartmentID)
+	department, err := exp.refs.department(teacher)
 	if err != nil {
-		return nil, fmt.Errorf("get department error: %w", err)
+		return nil, err
 	}
 
-	lessonType, err := formLessonType(item.LessonType)
+	lessonType, err := exp.profile.lessonType(item.LessonType)
 	if err != nil {
 		return nil, err
 	}
 
-	weeknum := "0"
-	if item.Weeknum != nil {
-		weeknum = strconv.FormatInt(int64(*item.Weeknum), 10)
-	}
-
-	return []string{
-		groupNumber,
-		strconv.FormatInt(int64(item.StudentsCount), 10),
-		strconv.FormatInt(int64(item.Weekday), 10),
-		strconv.FormatInt(int64(item.LessonNumber)+1, 10),
-		formCabinetAddress(item.Cabinet),
-		weeknum,
-		subgroup,
-		teacher.Name,
-		department.ExternalID,
-		item.Discipline,
-		lessonType,
-		item.Date.Format("02.01.2006"),
-		"",
-		teacher.ExternalID,
-		"",
-		"",
-		"",
-		"",
-		"",
-		"",
-		"",
-		"",
+	return &csvRow{
+		groupNumber: groupNumber,
+		cycleLength: cycleLength,
+		item:        item,
+		teacher:     teacher,
+		department:  department,
+		lessonType:  lessonType,
+		profile:     &exp.profile,
 	}, nil
 }
 
@@ -228,6 +155,57 @@ func formLessonType(lessonType schedules.ItemLessonType) (string, error) {
 	}
 }
 
+// formWeek forms Week column value: "Ч"/"Н" for even/odd weeks, "1,3/4" for weeks of 4-week cycle,
+// followed by explicit semester weeks like "Н 1-8". Empty value means every week
+func formWeek(item schedules.ScheduleItem, cycleLength int) string {
+	return formWeekWithLabels(item, cycleLength, defaultCsvProfile.OddWeek, defaultCsvProfile.EvenWeek)
+}
+
+// formWeekWithLabels forms Week column value with odd and even weeks written as provided labels
+func formWeekWithLabels(item schedules.ScheduleItem, cycleLength int, odd, even string) string {
+	var parts []string
+
+	if len(item.CycleWeeks) > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%d", item.CycleWeeks.String(), cycleLength))
+	} else if item.Weektype != nil {
+		switch {
+		case *item.Weektype == schedules.WeekTypeEven && even != "":
+			parts = append(parts, even)
+		case *item.Weektype == schedules.WeekTypeUneven && odd != "":
+			parts = append(parts, odd)
+		default:
+			//leave weektype empty
+		}
+	}
+
+	if len(item.Weeks) > 0 {
+		parts = append(parts, item.Weeks.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formWeekday forms Day column value counting from monday as 1, sunday is 7
+func formWeekday(weekday time.Weekday) string {
+	if weekday == time.Sunday {
+		return "7"
+	}
+
+	return strconv.FormatInt(int64(weekday), 10)
+}
+
+// formLocation forms Aud column value: cabinet address, "ДОТ" for online lessons or address of off-site lesson
+func formLocation(item schedules.ScheduleItem) string {
+	switch item.Location.Kind {
+	case schedules.LocationKindOnline:
+		return "ДОТ"
+	case schedules.LocationKindExternal:
+		return item.Location.Address
+	default:
+		return formCabinetAddress(item.Cabinet)
+	}
+}
+
 func formCabinetAddress(cabinet schedules.Cabinet) string {
 	if _, err := strconv.Atoi(cabinet.Building); err == nil {
 		return fmt.Sprintf("УК%s-%s", cabinet.Building, cabinet.Auditorium)
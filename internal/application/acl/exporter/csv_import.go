@@ -0,0 +1,353 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"schedule-generator/internal/domain/schedules"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CycledCsvItem item of cycled schedule read from file of cycledCsvHeader format. Rows of the same lesson split by
+// teachers and lesson periods are joined back, references to group, teachers and cabinet are left unresolved
+type CycledCsvItem struct {
+	// Lines numbers of file lines the item was read from
+	Lines        []int
+	GroupNumber  string
+	Weekday      time.Weekday
+	LessonNumber int8
+	Duration     int8
+	Subgroup     int8
+	Weektype     schedules.Weektype
+	CycleWeeks   []int
+	// CycleLength length of cycle written with cycle weeks, zero when item follows odd/even weeks
+	CycleLength int
+	Weeks       []int
+	Discipline  string
+	LessonType  schedules.ItemLessonType
+	// TeacherExternalIDs first teacher is the main one, the rest are assistants
+	TeacherExternalIDs []string
+	Location           schedules.Location
+	// Cabinet address parsed from Aud column, set for lessons in cabinet only
+	Cabinet schedules.Cabinet
+	// Aud raw value of Aud column
+	Aud string
+}
+
+// CsvLineError error of single line of imported file
+type CsvLineError struct {
+	Line int
+	Err  error
+}
+
+func (e CsvLineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e CsvLineError) Unwrap() error {
+	return e.Err
+}
+
+// ReadCycledCsv reads file written by csv exporter for cycled schedules. Delimeter is detected from header,
+// invalid lines are returned as line errors and skipped
+func ReadCycledCsv(src io.Reader) ([]CycledCsvItem, []CsvLineError, error) {
+	br := bufio.NewReader(src)
+
+	head, err := br.Peek(64)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+
+	stream := csv.NewReader(br)
+	stream.Comma = DefaultCsvDelimeter
+	if i := strings.IndexAny(string(head), ";,\t"); i >= 0 {
+		stream.Comma = rune(head[i])
+	}
+	stream.FieldsPerRecord = -1
+
+	header, err := stream.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header error: %w", err)
+	}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF")
+	}
+
+	if !slices.Equal(header, cycledCsvHeader) {
+		return nil, nil, errors.New("csv header does not match cycled schedule format")
+	}
+
+	var rows []cycledCsvRow
+	var lineErrs []CsvLineError
+
+	for {
+		record, err := stream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+
+			lineErrs = append(lineErrs, CsvLineError{Line: parseErr.Line, Err: parseErr.Err})
+			continue
+		}
+
+		// position is known only for successfully read record
+		line, _ := stream.FieldPos(0)
+
+		row, err := parseCycledCsvRecord(record)
+		if err != nil {
+			lineErrs = append(lineErrs, CsvLineError{Line: line, Err: err})
+			continue
+		}
+
+		row.line = line
+		rows = append(rows, row)
+	}
+
+	return joinCycledCsvRows(rows), lineErrs, nil
+}
+
+// cycledCsvRow row of file with single teacher and single lesson period
+type cycledCsvRow struct {
+	line int
+	// key columns of row except lesson number and teacher
+	key          string
+	lessonNumber int8
+	teacherID    string
+	item         CycledCsvItem
+}
+
+func parseCycledCsvRecord(record []string) (cycledCsvRow, error) {
+	if len(record) != len(cycledCsvHeader) {
+		return cycledCsvRow{}, fmt.Errorf("expected %d columns, got %d", len(cycledCsvHeader), len(record))
+	}
+
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+	}
+
+	group, day, lesson, aud, week, subgroup, discipline, lessonType, teacherID :=
+		record[0], record[1], record[2], record[3], record[4], record[5], record[8], record[9], record[13]
+
+	var argErr error
+
+	if group == "" {
+		argErr = errors.Join(argErr, errors.New("empty group"))
+	}
+
+	weekday, err := parseWeekday(day)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	lessonNumber, err := strconv.ParseInt(lesson, 10, 8)
+	if err != nil || lessonNumber < 1 {
+		argErr = errors.Join(argErr, fmt.Errorf("invalid lesson number %q", lesson))
+	}
+
+	var subgroupNumber int64
+	if subgroup != "" {
+		subgroupNumber, err = strconv.ParseInt(subgroup, 10, 8)
+		if err != nil || subgroupNumber < 0 {
+			argErr = errors.Join(argErr, fmt.Errorf("invalid subgroup %q", subgroup))
+		}
+	}
+
+	lt, err := parseLessonType(lessonType)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	if teacherID == "" {
+		argErr = errors.Join(argErr, errors.New("empty teacher id"))
+	}
+
+	item := CycledCsvItem{
+		GroupNumber: group,
+		Weekday:     weekday,
+		Duration:    schedules.DefaultItemDuration,
+		Subgroup:    int8(subgroupNumber),
+		Discipline:  discipline,
+		LessonType:  lt,
+		Aud:         aud,
+	}
+
+	if err := parseWeek(week, &item); err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	item.Location, item.Cabinet = parseLocation(aud)
+
+	if argErr != nil {
+		return cycledCsvRow{}, argErr
+	}
+
+	return cycledCsvRow{
+		key:          strings.Join([]string{group, day, aud, week, subgroup, discipline, lessonType}, "\x00"),
+		lessonNumber: int8(lessonNumber - 1),
+		teacherID:    teacherID,
+		item:         item,
+	}, nil
+}
+
+// joinCycledCsvRows reverts expandCsvItem: rows of the same lesson period are joined into item with several
+// teachers, then items with the same teachers taking consecutive periods are joined into one longer item
+func joinCycledCsvRows(rows []cycledCsvRow) []CycledCsvItem {
+	type lessonKey struct {
+		key          string
+		lessonNumber int8
+	}
+
+	var lessons []lessonKey
+	byLesson := make(map[lessonKey]*CycledCsvItem)
+
+	for _, row := range rows {
+		lk := lessonKey{key: row.key, lessonNumber: row.lessonNumber}
+
+		item, ok := byLesson[lk]
+		if !ok {
+			item = &row.item
+			item.LessonNumber = row.lessonNumber
+			byLesson[lk] = item
+			lessons = append(lessons, lk)
+		}
+
+		item.Lines = append(item.Lines, row.line)
+		if !slices.Contains(item.TeacherExternalIDs, row.teacherID) {
+			item.TeacherExternalIDs = append(item.TeacherExternalIDs, row.teacherID)
+		}
+	}
+
+	slices.SortStableFunc(lessons, func(a, b lessonKey) int {
+		if c := strings.Compare(a.key, b.key); c != 0 {
+			return c
+		}
+
+		return int(a.lessonNumber) - int(b.lessonNumber)
+	})
+
+	var items []CycledCsvItem
+	for i, lk := range lessons {
+		item := byLesson[lk]
+
+		if n := len(items); n > 0 && lessons[i-1].key == lk.key {
+			prev := &items[n-1]
+			if prev.LessonNumber+prev.Duration == item.LessonNumber && prev.Duration < schedules.MaxItemDuration &&
+				slices.Equal(prev.TeacherExternalIDs, item.TeacherExternalIDs) {
+				prev.Duration++
+				prev.Lines = append(prev.Lines, item.Lines...)
+				continue
+			}
+		}
+
+		items = append(items, *item)
+	}
+
+	for i := range items {
+		slices.Sort(items[i].Lines)
+	}
+
+	slices.SortStableFunc(items, func(a, b CycledCsvItem) int {
+		return a.Lines[0] - b.Lines[0]
+	})
+
+	return items
+}
+
+// parseWeekday reverts formWeekday
+func parseWeekday(s string) (time.Weekday, error) {
+	day, err := strconv.Atoi(s)
+	if err != nil || day < 1 || day > 7 {
+		return 0, fmt.Errorf("invalid day %q", s)
+	}
+
+	return time.Weekday(day % 7), nil
+}
+
+// parseLessonType reverts formLessonType
+func parseLessonType(s string) (schedules.ItemLessonType, error) {
+	for _, lt := range []schedules.ItemLessonType{
+		schedules.ItemTypeLecture,
+		schedules.ItemTypePractice,
+		schedules.ItemTypeSeminar,
+		schedules.ItemTypeExam,
+		schedules.ItemTypeLaboratory,
+	} {
+		if name, _ := formLessonType(lt); name == s {
+			return lt, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown lesson type %q", s)
+}
+
+// parseWeek reverts formWeek filling weektype, cycle weeks and semester weeks of item
+func parseWeek(s string, item *CycledCsvItem) error {
+	item.Weektype = schedules.WeekTypeBoth
+
+	for i, part := range strings.Fields(s) {
+		switch {
+		case i == 0 && part == "Н":
+			item.Weektype = schedules.WeekTypeUneven
+		case i == 0 && part == "Ч":
+			item.Weektype = schedules.WeekTypeEven
+		case i == 0 && strings.Contains(part, "/"):
+			weeks, length, _ := strings.Cut(part, "/")
+
+			cycleLength, err := strconv.Atoi(length)
+			if err != nil || cycleLength < 1 || cycleLength > schedules.MaxCycleLength {
+				return fmt.Errorf("invalid cycle length in week %q", s)
+			}
+
+			cycleWeeks, err := schedules.ParseWeeks(weeks, cycleLength)
+			if err != nil {
+				return fmt.Errorf("invalid cycle weeks in week %q: %w", s, err)
+			}
+
+			item.CycleWeeks, item.CycleLength = cycleWeeks, cycleLength
+		case len(item.Weeks) == 0:
+			weeks, err := schedules.ParseWeeks(part, schedules.MaxWeekNumber)
+			if err != nil {
+				return fmt.Errorf("invalid weeks in week %q: %w", s, err)
+			}
+
+			item.Weeks = weeks
+		default:
+			return fmt.Errorf("invalid week %q", s)
+		}
+	}
+
+	return nil
+}
+
+// parseLocation reverts formLocation. Address like "УК1-101" or "Лаб-5" without spaces is cabinet, "ДОТ" is online
+// lesson, anything else is address of off-site lesson
+func parseLocation(s string) (schedules.Location, schedules.Cabinet) {
+	if s == "ДОТ" {
+		return schedules.Location{Kind: schedules.LocationKindOnline}, schedules.Cabinet{}
+	}
+
+	building, auditorium, ok := strings.Cut(s, "-")
+	if !ok || building == "" || auditorium == "" || strings.ContainsAny(s, " \t") {
+		return schedules.Location{Kind: schedules.LocationKindExternal, Address: s}, schedules.Cabinet{}
+	}
+
+	if number, found := strings.CutPrefix(building, "УК"); found {
+		if _, err := strconv.Atoi(number); err == nil {
+			building = number
+		}
+	}
+
+	return schedules.Location{Kind: schedules.LocationKindCabinet}, schedules.Cabinet{Building: building, Auditorium: auditorium}
+}
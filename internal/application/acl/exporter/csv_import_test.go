@@ -0,0 +1,250 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+
+	"github.com/google/uuid"
+)
+
+// newTestCsvSchedule returns cycled schedule of four week cycle with co-taught double lesson, odd week lesson
+// of semester weeks, lesson of cycle weeks and lessons outside of cabinet
+func newTestCsvSchedule(t *testing.T, e *testExport) *schedules.Schedule {
+	t.Helper()
+
+	assistant := teachers.Teacher{ID: uuid.New(), ExternalID: "1002", Name: "Петров Пётр Петрович", DepartmentID: e.teacher.DepartmentID}
+	e.repo.teachers[assistant.ID] = assistant
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(e.schedule.EduGroupID, 1, start, start.AddDate(0, 0, 27), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schedule.Cycled.SetCycleLength(4); err != nil {
+		t.Fatal(err)
+	}
+
+	main := []schedules.ItemTeacher{{TeacherID: e.teacher.ID}}
+	coTaught := []schedules.ItemTeacher{{TeacherID: e.teacher.ID}, {TeacherID: assistant.ID, Role: schedules.TeacherRoleAssistant}}
+	cabinet := schedules.Cabinet{ID: uuid.New(), Building: "1", Auditorium: "101"}
+
+	add := []func() error{
+		func() error {
+			return schedule.Cycled.AddItem("Программирование", coTaught, time.Monday, 12, 0, 2, 1, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLaboratory), schedules.Location{}, cabinet)
+		},
+		func() error {
+			return schedule.Cycled.AddItem("Математика", main, time.Tuesday, 25, 1, 0, 0, int8(schedules.WeekTypeUneven), nil, []int{1, 3}, int8(schedules.ItemTypeLecture), schedules.Location{}, cabinet)
+		},
+		func() error {
+			return schedule.Cycled.AddItem("Физика", main, time.Wednesday, 25, 2, 0, 0, int8(schedules.WeekTypeBoth), []int{1, 3}, nil, int8(schedules.ItemTypePractice), schedules.Location{Kind: schedules.LocationKindOnline, MeetingURL: "https://meet.example.com/physics"}, schedules.Cabinet{})
+		},
+		func() error {
+			return schedule.Cycled.AddItem("Практика", main, time.Saturday, 10, 3, 3, 2, int8(schedules.WeekTypeEven), nil, nil, int8(schedules.ItemTypeSeminar), schedules.Location{Kind: schedules.LocationKindExternal, Address: "ул. Ленина, 1"}, schedules.Cabinet{})
+		},
+	}
+
+	for _, f := range add {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return schedule
+}
+
+func TestReadCycledCsv_RoundTrip(t *testing.T) {
+	e := newTestExport(t)
+	schedule := newTestCsvSchedule(t, e)
+
+	exp, err := e.factory(t).ByFormat("csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := exp.Export(context.Background(), schedule, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	items, lineErrs, err := ReadCycledCsv(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lineErrs) != 0 {
+		t.Fatalf("expected no line errors, got: %v", lineErrs)
+	}
+
+	source := schedule.Cycled.ListItem()
+	if len(items) != len(source) {
+		t.Fatalf("expected %d items, got: %d", len(source), len(items))
+	}
+
+	externalIDs := map[uuid.UUID]string{}
+	for id, teacher := range e.repo.teachers {
+		externalIDs[id] = teacher.ExternalID
+	}
+
+	for i, item := range source {
+		got := items[i]
+
+		want := CycledCsvItem{
+			Lines:        got.Lines,
+			GroupNumber:  "101",
+			Weekday:      item.Weekday,
+			LessonNumber: item.LessonNumber,
+			Duration:     item.Span(),
+			Subgroup:     item.Subgroup,
+			Weektype:     *item.Weektype,
+			Weeks:        item.Weeks,
+			Discipline:   item.Discipline,
+			LessonType:   item.LessonType,
+			// meeting url is not written into csv
+			Location: schedules.Location{Kind: item.Location.Kind, Address: item.Location.Address},
+			Cabinet:  schedules.Cabinet{Building: item.Cabinet.Building, Auditorium: item.Cabinet.Auditorium},
+			Aud:      got.Aud,
+		}
+
+		if len(item.CycleWeeks) > 0 {
+			want.CycleWeeks, want.CycleLength = item.CycleWeeks, schedule.Cycled.CycleLength
+		}
+
+		for _, it := range item.Teachers {
+			want.TeacherExternalIDs = append(want.TeacherExternalIDs, externalIDs[it.TeacherID])
+		}
+
+		// rows of item are written per lesson period and teacher
+		if len(got.Lines) != int(item.Span())*len(item.Teachers) {
+			t.Errorf("item %s: expected %d lines, got: %v", item.Discipline, int(item.Span())*len(item.Teachers), got.Lines)
+		}
+
+		if !equalCsvItems(want, got) {
+			t.Errorf("item %s: expected %+v, got: %+v", item.Discipline, want, got)
+		}
+	}
+}
+
+func equalCsvItems(a, b CycledCsvItem) bool {
+	return slices.Equal(a.Lines, b.Lines) &&
+		a.GroupNumber == b.GroupNumber &&
+		a.Weekday == b.Weekday &&
+		a.LessonNumber == b.LessonNumber &&
+		a.Duration == b.Duration &&
+		a.Subgroup == b.Subgroup &&
+		a.Weektype == b.Weektype &&
+		slices.Equal(a.CycleWeeks, b.CycleWeeks) &&
+		a.CycleLength == b.CycleLength &&
+		slices.Equal(a.Weeks, b.Weeks) &&
+		a.Discipline == b.Discipline &&
+		a.LessonType == b.LessonType &&
+		slices.Equal(a.TeacherExternalIDs, b.TeacherExternalIDs) &&
+		a.Location == b.Location &&
+		a.Cabinet == b.Cabinet &&
+		a.Aud == b.Aud
+}
+
+func TestReadCycledCsv_LineErrors(t *testing.T) {
+	header := strings.Join(cycledCsvHeader, ";")
+	valid := "101;1;1;УК1-101;;;Иванов И.И.;42;Математика;лек.;-100;-100;0;1001"
+
+	src := strings.Join([]string{
+		header,
+		valid,
+		// quote in the first field fails parsing of record before position of any field is known
+		`1"01;1;2;УК1-101;;;Иванов И.И.;42;Математика;лек.;-100;-100;0;1001`,
+		"101;8;2;УК1-101;;;Иванов И.И.;42;Математика;лек.;-100;-100;0;1001",
+		"101;1;3;УК1-101",
+		"",
+	}, "\n")
+
+	items, lineErrs, err := ReadCycledCsv(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || !slices.Equal(items[0].Lines, []int{2}) {
+		t.Errorf("expected item of line 2, got: %+v", items)
+	}
+
+	var lines []int
+	for _, lineErr := range lineErrs {
+		lines = append(lines, lineErr.Line)
+	}
+
+	if !slices.Equal(lines, []int{3, 4, 5}) {
+		t.Errorf("expected errors of lines 3, 4 and 5, got: %v", lineErrs)
+	}
+}
+
+func TestJoinCycledCsvRows(t *testing.T) {
+	row := func(line int, key string, lessonNumber int8, teacherID string) cycledCsvRow {
+		return cycledCsvRow{
+			line:         line,
+			key:          key,
+			lessonNumber: lessonNumber,
+			teacherID:    teacherID,
+			item:         CycledCsvItem{Discipline: key, Duration: schedules.DefaultItemDuration},
+		}
+	}
+
+	type joined struct {
+		discipline   string
+		lessonNumber int8
+		duration     int8
+		teachers     []string
+		lines        []int
+	}
+
+	cases := map[string]struct {
+		rows []cycledCsvRow
+		want []joined
+	}{
+		"co-taught double lesson": {
+			rows: []cycledCsvRow{row(2, "a", 0, "1"), row(3, "a", 0, "2"), row(4, "a", 1, "1"), row(5, "a", 1, "2")},
+			want: []joined{{"a", 0, 2, []string{"1", "2"}, []int{2, 3, 4, 5}}},
+		},
+		"different teachers of periods": {
+			rows: []cycledCsvRow{row(2, "a", 0, "1"), row(3, "a", 1, "2")},
+			want: []joined{{"a", 0, 1, []string{"1"}, []int{2}}, {"a", 1, 1, []string{"2"}, []int{3}}},
+		},
+		"not consecutive periods": {
+			rows: []cycledCsvRow{row(2, "a", 0, "1"), row(3, "a", 2, "1")},
+			want: []joined{{"a", 0, 1, []string{"1"}, []int{2}}, {"a", 2, 1, []string{"1"}, []int{3}}},
+		},
+		"different lessons": {
+			rows: []cycledCsvRow{row(2, "b", 0, "1"), row(3, "a", 1, "1")},
+			want: []joined{{"b", 0, 1, []string{"1"}, []int{2}}, {"a", 1, 1, []string{"1"}, []int{3}}},
+		},
+		"longer than max duration": {
+			rows: []cycledCsvRow{row(2, "a", 0, "1"), row(3, "a", 1, "1"), row(4, "a", 2, "1"), row(5, "a", 3, "1"), row(6, "a", 4, "1")},
+			want: []joined{{"a", 0, schedules.MaxItemDuration, []string{"1"}, []int{2, 3, 4, 5}}, {"a", 4, 1, []string{"1"}, []int{6}}},
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			items := joinCycledCsvRows(c.rows)
+
+			got := make([]joined, len(items))
+			for i, item := range items {
+				got[i] = joined{item.Discipline, item.LessonNumber, item.Duration, item.TeacherExternalIDs, item.Lines}
+			}
+
+			if !slices.EqualFunc(got, c.want, func(a, b joined) bool {
+				return a.discipline == b.discipline && a.lessonNumber == b.lessonNumber && a.duration == b.duration &&
+					slices.Equal(a.teachers, b.teachers) && slices.Equal(a.lines, b.lines)
+			}) {
+				t.Errorf("expected items %+v, got: %+v", c.want, got)
+			}
+		})
+	}
+}
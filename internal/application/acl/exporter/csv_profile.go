@@ -0,0 +1,342 @@
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"schedule-generator/internal/domain/departments"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// DefaultCsvProfile name of profile with columns of the first downstream system, used when profile is not chosen
+const DefaultCsvProfile = "default"
+
+var ErrUnknownCsvProfile = errors.New("unknown csv profile")
+
+// CsvProfile describes csv layout expected by downstream system. Empty settings are taken from default profile
+type CsvProfile struct {
+	Name string `json:"name"`
+	// Encoding one of csvEncodings
+	Encoding string `json:"encoding"`
+	// Delimiter single character separating columns
+	Delimiter string `json:"delimiter"`
+	// DateFormat go layout of dates, e.g. "02.01.2006"
+	DateFormat string `json:"date_format"`
+	// LessonTypes values of lesson types by names of csvLessonTypes, missing types are written as abbreviations
+	LessonTypes map[string]string `json:"lesson_types,omitempty"`
+	// OddWeek and EvenWeek values of week types in week column
+	OddWeek         string      `json:"odd_week"`
+	EvenWeek        string      `json:"even_week"`
+	CycledColumns   []CsvColumn `json:"cycled_columns"`
+	CalendarColumns []CsvColumn `json:"calendar_columns"`
+}
+
+// CsvColumn column of csv file filled with value of domain field, or with constant Value when Field is empty
+type CsvColumn struct {
+	Header string `json:"header"`
+	// Field one of csvFields
+	Field string `json:"field,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+var csvEncodings = map[string]encoding.Encoding{
+	"utf-8":        encoding.Nop,
+	"windows-1251": charmap.Windows1251,
+	"koi8-r":       charmap.KOI8R,
+	"ibm866":       charmap.CodePage866,
+}
+
+var csvLessonTypes = map[string]schedules.ItemLessonType{
+	"lecture":    schedules.ItemTypeLecture,
+	"practice":   schedules.ItemTypePractice,
+	"seminar":    schedules.ItemTypeSeminar,
+	"exam":       schedules.ItemTypeExam,
+	"laboratory": schedules.ItemTypeLaboratory,
+}
+
+// csvRow single csv row: item with single lesson period and single teacher and its references
+type csvRow struct {
+	groupNumber string
+	cycleLength int
+	item        schedules.ScheduleItem
+	teacher     teachers.Teacher
+	department  departments.Department
+	lessonType  string
+	profile     *CsvProfile
+}
+
+var csvFields = map[string]func(r *csvRow) string{
+	"group": func(r *csvRow) string {
+		return r.groupNumber
+	},
+	"students_count": func(r *csvRow) string {
+		return strconv.FormatInt(int64(r.item.StudentsCount), 10)
+	},
+	"weekday": func(r *csvRow) string {
+		return formWeekday(r.item.Weekday)
+	},
+	"lesson_number": func(r *csvRow) string {
+		return strconv.FormatInt(int64(r.item.LessonNumber)+1, 10)
+	},
+	"location": func(r *csvRow) string {
+		return formLocation(r.item)
+	},
+	// week weeks of cycle with explicit weeks of semester, written for cycled schedules
+	"week": func(r *csvRow) string {
+		return formWeekWithLabels(r.item, r.cycleLength, r.profile.OddWeek, r.profile.EvenWeek)
+	},
+	// weeknum number of week of dated item, "0" when unknown
+	"weeknum": func(r *csvRow) string {
+		if r.item.Weeknum == nil {
+			return "0"
+		}
+
+		return strconv.FormatInt(int64(*r.item.Weeknum), 10)
+	},
+	"subgroup": func(r *csvRow) string {
+		if r.item.Subgroup > 0 {
+			return strconv.FormatInt(int64(r.item.Subgroup), 10)
+		}
+
+		return ""
+	},
+	"teacher_name": func(r *csvRow) string {
+		return r.teacher.Name
+	},
+	"teacher_external_id": func(r *csvRow) string {
+		return r.teacher.ExternalID
+	},
+	"department_external_id": func(r *csvRow) string {
+		return r.department.ExternalID
+	},
+	"discipline": func(r *csvRow) string {
+		return r.item.Discipline
+	},
+	"lesson_type": func(r *csvRow) string {
+		return r.lessonType
+	},
+	"date": func(r *csvRow) string {
+		if r.item.Date == nil {
+			return ""
+		}
+
+		return r.item.Date.Format(r.profile.DateFormat)
+	},
+}
+
+var defaultCsvProfile = CsvProfile{
+	Name:       DefaultCsvProfile,
+	Encoding:   "utf-8",
+	Delimiter:  string(DefaultCsvDelimeter),
+	DateFormat: "02.01.2006",
+	OddWeek:    "Н",
+	EvenWeek:   "Ч",
+	CycledColumns: []CsvColumn{
+		{Header: "Group", Field: "group"},
+		{Header: "Day", Field: "weekday"},
+		{Header: "Les", Field: "lesson_number"},
+		{Header: "Aud", Field: "location"},
+		{Header: "Week", Field: "week"},
+		{Header: "Subg", Field: "subgroup"},
+		{Header: "Name", Field: "teacher_name"},
+		{Header: "Caf", Field: "department_external_id"},
+		{Header: "Subject", Field: "discipline"},
+		{Header: "Subj_Type", Field: "lesson_type"},
+		{Header: "Start", Value: "-100"},
+		{Header: "End", Value: "-100"},
+		{Header: "Subj_CafID", Value: "0"},
+		{Header: "PrepID", Field: "teacher_external_id"},
+	},
+	CalendarColumns: []CsvColumn{
+		{Header: "Group", Field: "group"},
+		{Header: "StudInLesson", Field: "students_count"},
+		{Header: "Day", Field: "weekday"},
+		{Header: "Les", Field: "lesson_number"},
+		{Header: "Aud", Field: "location"},
+		{Header: "Week", Field: "weeknum"},
+		{Header: "Subg", Field: "subgroup"},
+		{Header: "Name", Field: "teacher_name"},
+		{Header: "CafID", Field: "department_external_id"},
+		{Header: "Subject", Field: "discipline"},
+		{Header: "Subj_Type", Field: "lesson_type"},
+		{Header: "Date", Field: "date"},
+		{Header: "Subj_CafID"},
+		{Header: "PrepID", Field: "teacher_external_id"},
+		{Header: "Themas"},
+		{Header: "Substitution_Name"},       // leaved empty
+		{Header: "Substitution_PrepID"},     // leaved empty
+		{Header: "Substitution_Subject"},    // leaved empty
+		{Header: "Substitution_Subj_type"},  // leaved empty
+		{Header: "Substitution_Subj_CafID"}, // leaved empty
+		{Header: "Lesson_ID"},               // leaved empty
+		{Header: "Lesson_Num"},              // leaved empty
+	},
+}
+
+// LoadCsvProfiles reads json array of csv profiles from file
+func LoadCsvProfiles(path string) ([]CsvProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read csv profiles error: %w", err)
+	}
+
+	var profiles []CsvProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("parse csv profiles of %s error: %w", path, err)
+	}
+
+	names := make(map[string]struct{})
+	for _, p := range profiles {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+
+		if _, ok := names[p.Name]; ok {
+			return nil, fmt.Errorf("csv profile %q is defined twice", p.Name)
+		}
+
+		names[p.Name] = struct{}{}
+	}
+
+	return profiles, nil
+}
+
+// Validate checks names of encoding, lesson types and fields of profile and that week values are set together
+func (p CsvProfile) Validate() error {
+	if p.Name == "" {
+		return errors.New("csv profile name is empty")
+	}
+
+	if p.Name == DefaultCsvProfile {
+		return fmt.Errorf("csv profile name %q is reserved", p.Name)
+	}
+
+	if _, ok := csvEncodings[p.Encoding]; p.Encoding != "" && !ok {
+		return fmt.Errorf("csv profile %q: unknown encoding %q", p.Name, p.Encoding)
+	}
+
+	if (p.OddWeek == "") != (p.EvenWeek == "") {
+		return fmt.Errorf("csv profile %q: odd and even week values must be set together", p.Name)
+	}
+
+	if p.Delimiter != "" {
+		r, size := utf8.DecodeRuneInString(p.Delimiter)
+		if size != len(p.Delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+			return fmt.Errorf("csv profile %q: invalid delimiter %q", p.Name, p.Delimiter)
+		}
+	}
+
+	for name := range p.LessonTypes {
+		if _, ok := csvLessonTypes[name]; !ok {
+			return fmt.Errorf("csv profile %q: unknown lesson type %q", p.Name, name)
+		}
+	}
+
+	for _, c := range slices.Concat(p.CycledColumns, p.CalendarColumns) {
+		if c.Field == "" {
+			continue
+		}
+
+		if _, ok := csvFields[c.Field]; !ok {
+			return fmt.Errorf("csv profile %q: unknown field %q of column %q", p.Name, c.Field, c.Header)
+		}
+
+		if c.Value != "" {
+			return fmt.Errorf("csv profile %q: column %q has both field and value", p.Name, c.Header)
+		}
+	}
+
+	return nil
+}
+
+// withDefaults returns profile with empty settings taken from default profile. Week values are taken only
+// together, so labels of different profiles are never mixed
+func (p CsvProfile) withDefaults() CsvProfile {
+	if p.Encoding == "" {
+		p.Encoding = defaultCsvProfile.Encoding
+	}
+
+	if p.Delimiter == "" {
+		p.Delimiter = defaultCsvProfile.Delimiter
+	}
+
+	if p.DateFormat == "" {
+		p.DateFormat = defaultCsvProfile.DateFormat
+	}
+
+	if p.OddWeek == "" && p.EvenWeek == "" {
+		p.OddWeek, p.EvenWeek = defaultCsvProfile.OddWeek, defaultCsvProfile.EvenWeek
+	}
+
+	if len(p.CycledColumns) == 0 {
+		p.CycledColumns = defaultCsvProfile.CycledColumns
+	}
+
+	if len(p.CalendarColumns) == 0 {
+		p.CalendarColumns = defaultCsvProfile.CalendarColumns
+	}
+
+	return p
+}
+
+func (p *CsvProfile) delimiter() rune {
+	r, _ := utf8.DecodeRuneInString(p.Delimiter)
+	return r
+}
+
+// encode wraps dst into writer converting utf-8 into profile encoding, runes missing in encoding are replaced.
+// Writer must be closed to flush converted text
+func (p *CsvProfile) encode(dst io.Writer) (io.WriteCloser, error) {
+	enc, ok := csvEncodings[p.Encoding]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q", p.Encoding)
+	}
+
+	return transform.NewWriter(dst, encoding.ReplaceUnsupported(enc.NewEncoder())), nil
+}
+
+func (p *CsvProfile) lessonType(lessonType schedules.ItemLessonType) (string, error) {
+	for name, lt := range csvLessonTypes {
+		if lt != lessonType {
+			continue
+		}
+
+		if v, ok := p.LessonTypes[name]; ok {
+			return v, nil
+		}
+	}
+
+	return formLessonType(lessonType)
+}
+
+func (p *CsvProfile) row(columns []CsvColumn, r *csvRow) []string {
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		if form, ok := csvFields[c.Field]; ok {
+			record[i] = form(r)
+		} else {
+			record[i] = c.Value
+		}
+	}
+
+	return record
+}
+
+func csvHeader(columns []CsvColumn) []string {
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header
+	}
+
+	return header
+}
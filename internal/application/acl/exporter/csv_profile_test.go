@@ -0,0 +1,345 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"schedule-generator/internal/domain/departments"
+	"schedule-generator/internal/domain/schedules"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestCsvProfile_Validate(t *testing.T) {
+	cases := map[string]struct {
+		profile CsvProfile
+		fail    bool
+	}{
+		"only name": {
+			profile: CsvProfile{Name: "lms"},
+		},
+		"full profile": {
+			profile: CsvProfile{
+				Name:            "lms",
+				Encoding:        "windows-1251",
+				Delimiter:       "\t",
+				DateFormat:      "2006-01-02",
+				LessonTypes:     map[string]string{"lecture": "Лекция"},
+				OddWeek:         "odd",
+				EvenWeek:        "even",
+				CycledColumns:   []CsvColumn{{Header: "Group", Field: "group"}, {Header: "Source", Value: "schedule"}},
+				CalendarColumns: []CsvColumn{{Header: "Date", Field: "date"}, {Header: "Empty"}},
+			},
+		},
+		"empty name":              {profile: CsvProfile{}, fail: true},
+		"reserved name":           {profile: CsvProfile{Name: DefaultCsvProfile}, fail: true},
+		"unknown encoding":        {profile: CsvProfile{Name: "lms", Encoding: "latin-1"}, fail: true},
+		"long delimiter":          {profile: CsvProfile{Name: "lms", Delimiter: ";;"}, fail: true},
+		"quote delimiter":         {profile: CsvProfile{Name: "lms", Delimiter: `"`}, fail: true},
+		"unknown lesson type":     {profile: CsvProfile{Name: "lms", LessonTypes: map[string]string{"consultation": "конс."}}, fail: true},
+		"odd week without even":   {profile: CsvProfile{Name: "lms", OddWeek: "odd"}, fail: true},
+		"even week without odd":   {profile: CsvProfile{Name: "lms", EvenWeek: "even"}, fail: true},
+		"unknown field":           {profile: CsvProfile{Name: "lms", CalendarColumns: []CsvColumn{{Header: "Room", Field: "room"}}}, fail: true},
+		"both field and constant": {profile: CsvProfile{Name: "lms", CycledColumns: []CsvColumn{{Header: "Group", Field: "group", Value: "101"}}}, fail: true},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			err := c.profile.Validate()
+			if (err != nil) != c.fail {
+				t.Errorf("expected failure: %v, got error: %v", c.fail, err)
+			}
+		})
+	}
+}
+
+func TestCsvProfile_WithDefaults(t *testing.T) {
+	p := CsvProfile{Name: "lms"}.withDefaults()
+
+	if p.Encoding != defaultCsvProfile.Encoding || p.Delimiter != defaultCsvProfile.Delimiter || p.DateFormat != defaultCsvProfile.DateFormat {
+		t.Errorf("expected default encoding, delimiter and date format, got: %+v", p)
+	}
+
+	if p.OddWeek != defaultCsvProfile.OddWeek || p.EvenWeek != defaultCsvProfile.EvenWeek {
+		t.Errorf("expected default week values, got: %q and %q", p.OddWeek, p.EvenWeek)
+	}
+
+	if len(p.CycledColumns) != len(defaultCsvProfile.CycledColumns) || len(p.CalendarColumns) != len(defaultCsvProfile.CalendarColumns) {
+		t.Error("expected default columns")
+	}
+
+	columns := []CsvColumn{{Header: "Group", Field: "group"}}
+	p = CsvProfile{Name: "lms", Encoding: "koi8-r", Delimiter: ",", OddWeek: "odd", EvenWeek: "even", CycledColumns: columns}.withDefaults()
+
+	if p.Encoding != "koi8-r" || p.Delimiter != "," || p.OddWeek != "odd" || p.EvenWeek != "even" || len(p.CycledColumns) != 1 {
+		t.Errorf("expected settings of profile to be kept, got: %+v", p)
+	}
+}
+
+func TestLoadCsvProfiles(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		names   []string
+		fail    bool
+	}{
+		"profiles": {
+			content: `[{"name": "lms", "encoding": "windows-1251"}, {"name": "portal", "delimiter": ","}]`,
+			names:   []string{"lms", "portal"},
+		},
+		"defined twice": {
+			content: `[{"name": "lms"}, {"name": "lms"}]`,
+			fail:    true,
+		},
+		"invalid profile": {
+			content: `[{"name": "lms", "odd_week": "odd"}]`,
+			fail:    true,
+		},
+		"invalid json": {
+			content: `{"name": "lms"}`,
+			fail:    true,
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profiles.json")
+			if err := os.WriteFile(path, []byte(c.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			profiles, err := LoadCsvProfiles(path)
+			if (err != nil) != c.fail {
+				t.Fatalf("expected failure: %v, got error: %v", c.fail, err)
+			}
+
+			var names []string
+			for _, p := range profiles {
+				names = append(names, p.Name)
+			}
+
+			if !slices.Equal(names, c.names) {
+				t.Errorf("expected profiles %v, got: %v", c.names, names)
+			}
+		})
+	}
+
+	if _, err := LoadCsvProfiles(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error on missing file, got nil")
+	}
+}
+
+func TestCsvProfile_Encode(t *testing.T) {
+	cases := map[string]struct {
+		text string
+		want []byte
+	}{
+		"utf-8":        {text: "Иванов", want: []byte("Иванов")},
+		"windows-1251": {text: "Иванов", want: []byte{0xC8, 0xE2, 0xE0, 0xED, 0xEE, 0xE2}},
+		"koi8-r":       {text: "Иванов", want: []byte{0xE9, 0xD7, 0xC1, 0xCE, 0xCF, 0xD7}},
+		"ibm866":       {text: "Иванов", want: []byte{0x88, 0xA2, 0xA0, 0xAD, 0xAE, 0xA2}},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			p := CsvProfile{Encoding: n}
+
+			var buf bytes.Buffer
+			w, err := p.encode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := w.Write([]byte(c.text)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), c.want) {
+				t.Errorf("expected % X, got: % X", c.want, buf.Bytes())
+			}
+		})
+	}
+
+	t.Run("unsupported rune", func(t *testing.T) {
+		p := CsvProfile{Encoding: "windows-1251"}
+
+		var buf bytes.Buffer
+		w, err := p.encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Write([]byte("Ж→Ж"))
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := charmap.Windows1251.NewDecoder().String(buf.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != "Ж\x1aЖ" {
+			t.Errorf("expected unsupported rune to be replaced, got: %q", got)
+		}
+	})
+
+	if _, err := (&CsvProfile{Encoding: "latin-1"}).encode(&bytes.Buffer{}); err == nil {
+		t.Error("expected error on unknown encoding, got nil")
+	}
+}
+
+func TestCsvProfile_Row(t *testing.T) {
+	e := newTestExport(t)
+
+	p := CsvProfile{
+		Name:        "lms",
+		DateFormat:  "2006-01-02",
+		LessonTypes: map[string]string{"laboratory": "Лабораторная работа"},
+		OddWeek:     "odd",
+		EvenWeek:    "even",
+	}
+
+	row := func(item schedules.ScheduleItem) *csvRow {
+		t.Helper()
+
+		lessonType, err := p.lessonType(item.LessonType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &csvRow{
+			groupNumber: "101",
+			item:        item,
+			teacher:     e.teacher,
+			department:  departments.Department{ExternalID: "42"},
+			lessonType:  lessonType,
+			profile:     &p,
+		}
+	}
+
+	columns := []CsvColumn{
+		{Header: "Group", Field: "group"},
+		{Header: "Day", Field: "weekday"},
+		{Header: "Les", Field: "lesson_number"},
+		{Header: "Aud", Field: "location"},
+		{Header: "Week", Field: "week"},
+		{Header: "Subg", Field: "subgroup"},
+		{Header: "Subject", Field: "discipline"},
+		{Header: "Type", Field: "lesson_type"},
+		{Header: "Teacher", Field: "teacher_external_id"},
+		{Header: "Department", Field: "department_external_id"},
+		{Header: "Source", Value: "schedule"},
+		{Header: "Empty"},
+	}
+
+	cycled := e.schedule.Cycled.ListItem()
+
+	cases := map[string]struct {
+		row  *csvRow
+		want []string
+	}{
+		"lecture of both weeks": {
+			row:  row(cycled[0]),
+			want: []string{"101", "1", "1", "УК1-101", "", "", "Математика; анализ, часть 1", "лек.", "1001", "42", "schedule", ""},
+		},
+		"laboratory of odd weeks": {
+			row:  row(cycled[1]),
+			want: []string{"101", "1", "2", "ДОТ", "odd", "1", "Программирование", "Лабораторная работа", "1001", "42", "schedule", ""},
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			if got := p.row(columns, c.row); !slices.Equal(got, c.want) {
+				t.Errorf("expected %q, got: %q", c.want, got)
+			}
+		})
+	}
+
+	t.Run("dated item", func(t *testing.T) {
+		item := e.calendar(t).Calendar.ListItem()[0]
+
+		got := p.row([]CsvColumn{{Header: "Date", Field: "date"}, {Header: "Week", Field: "weeknum"}}, row(item))
+		if !slices.Equal(got, []string{"2025-09-01", "1"}) {
+			t.Errorf("expected date and week number, got: %q", got)
+		}
+	})
+}
+
+func TestExporterFactory_ByCsvProfile(t *testing.T) {
+	e := newTestExport(t)
+	e.repo.csvProfiles = map[string]CsvProfile{
+		"stored": {
+			Name:          "stored",
+			Encoding:      "windows-1251",
+			Delimiter:     ",",
+			CycledColumns: []CsvColumn{{Header: "Группа", Field: "group"}, {Header: "Дисциплина", Field: "discipline"}},
+		},
+		"configured": {Name: "configured", Encoding: "koi8-r"},
+		"invalid":    {Name: "invalid", OddWeek: "odd"},
+	}
+
+	f := e.factory(t, CsvProfiles(CsvProfile{Name: "configured", Delimiter: "|", CycledColumns: []CsvColumn{{Header: "Group", Field: "group"}}}))
+
+	export := func(t *testing.T, name string) string {
+		t.Helper()
+
+		exp, err := f.ByCsvProfile(context.Background(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := exp.Export(context.Background(), e.schedule, &buf); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	t.Run("stored profile", func(t *testing.T) {
+		got, err := charmap.Windows1251.NewDecoder().String(export(t, "stored"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "Группа,Дисциплина\n101,\"Математика; анализ, часть 1\"\n101,Программирование\n101,Программирование\n"
+		if got != want {
+			t.Errorf("expected %q, got: %q", want, got)
+		}
+	})
+
+	t.Run("configured profile takes precedence", func(t *testing.T) {
+		if got, want := export(t, "configured"), "Group\n101\n101\n101\n"; got != want {
+			t.Errorf("expected %q, got: %q", want, got)
+		}
+	})
+
+	t.Run("default profile", func(t *testing.T) {
+		if got := export(t, DefaultCsvProfile); !strings.HasPrefix(got, strings.Join(cycledCsvHeader, ";")+"\n") {
+			t.Errorf("expected header of default profile, got: %q", got)
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		if _, err := f.ByCsvProfile(context.Background(), "missing"); !errors.Is(err, ErrUnknownCsvProfile) {
+			t.Errorf("expected unknown profile error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid stored profile", func(t *testing.T) {
+		if _, err := f.ByCsvProfile(context.Background(), "invalid"); err == nil || errors.Is(err, ErrUnknownCsvProfile) {
+			t.Errorf("expected validation error, got: %v", err)
+		}
+	})
+}
@@ -13,6 +13,20 @@ type Exporter interface {
 	Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error
 }
 
+// CalendarExporter exports dated items only, cycled schedule must be converted to calendar schedule before export
+type CalendarExporter interface {
+	Exporter
+	calendarOnly()
+}
+
+// WorkbookExporter exports several schedules into single document, e.g. faculty-wide workbook with sheet per group
+type WorkbookExporter interface {
+	Exporter
+	ExportWorkbook(ctx context.Context, list []*schedules.Schedule, dst io.Writer) error
+}
+
 type Factory interface {
 	ByFormat(format string) (Exporter, error)
+	// ByCsvProfile returns csv exporter writing files of named profile from configuration or repository
+	ByCsvProfile(ctx context.Context, name string) (Exporter, error)
 }
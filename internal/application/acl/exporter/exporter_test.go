@@ -0,0 +1,184 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"schedule-generator/internal/domain/departments"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/faculties"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"schedule-generator/internal/infrastructure/db"
+
+	"github.com/google/uuid"
+)
+
+// exporterRepoStub serves groups, teachers, departments, faculty of all groups and stored csv profiles from memory,
+// other methods are not used by exporters
+type exporterRepoStub struct {
+	ExporterRepository
+	groups      map[uuid.UUID]edugroups.EduGroup
+	teachers    map[uuid.UUID]teachers.Teacher
+	departments map[uuid.UUID]departments.Department
+	faculty     faculties.Faculty
+	csvProfiles map[string]CsvProfile
+}
+
+func (r *exporterRepoStub) GetEduGroup(ctx context.Context, id uuid.UUID) (*edugroups.EduGroup, error) {
+	group, ok := r.groups[id]
+	if !ok {
+		return nil, db.ErrorNotFound
+	}
+
+	return &group, nil
+}
+
+func (r *exporterRepoStub) GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error) {
+	if _, ok := r.groups[groupID]; !ok {
+		return uuid.Nil, db.ErrorNotFound
+	}
+
+	return r.faculty.ID, nil
+}
+
+func (r *exporterRepoStub) GetFaculty(ctx context.Context, id uuid.UUID) (*faculties.Faculty, error) {
+	if id != r.faculty.ID {
+		return nil, db.ErrorNotFound
+	}
+
+	return &r.faculty, nil
+}
+
+func (r *exporterRepoStub) MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error) {
+	result := make(map[uuid.UUID]teachers.Teacher, len(teacherIDs))
+	for _, id := range teacherIDs {
+		if t, ok := r.teachers[id]; ok {
+			result[id] = t
+		}
+	}
+
+	return result, nil
+}
+
+func (r *exporterRepoStub) MapDepartmentByIDs(ctx context.Context, departmentIDs uuid.UUIDs) (map[uuid.UUID]departments.Department, error) {
+	result := make(map[uuid.UUID]departments.Department, len(departmentIDs))
+	for _, id := range departmentIDs {
+		if d, ok := r.departments[id]; ok {
+			result[id] = d
+		}
+	}
+
+	return result, nil
+}
+
+func (r *exporterRepoStub) GetCsvProfile(ctx context.Context, name string) (*CsvProfile, error) {
+	p, ok := r.csvProfiles[name]
+	if !ok {
+		return nil, db.ErrorNotFound
+	}
+
+	return &p, nil
+}
+
+// testExport schedule with its references: group 101 with lecture and double laboratory of subgroup
+// on mondays of two weeks from 2025-09-01
+type testExport struct {
+	repo     *exporterRepoStub
+	schedule *schedules.Schedule
+	teacher  teachers.Teacher
+}
+
+func newTestExport(t *testing.T) *testExport {
+	t.Helper()
+
+	department := departments.Department{ID: uuid.New(), ExternalID: "42", Name: "Кафедра информатики"}
+	teacher := teachers.Teacher{ID: uuid.New(), ExternalID: "1001", Name: "Иванов Иван Иванович", DepartmentID: department.ID}
+	group := edugroups.EduGroup{ID: uuid.New(), Number: "101", AdmissionYear: 2025}
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	itemTeachers := []schedules.ItemTeacher{{TeacherID: teacher.ID}}
+
+	err = schedule.Cycled.AddItem("Математика; анализ, часть 1", itemTeachers, time.Monday, 25, 0, 0, 0, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLecture), schedules.Location{}, schedules.Cabinet{ID: uuid.New(), Building: "1", Auditorium: "101"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.Cycled.AddItem("Программирование", itemTeachers, time.Monday, 12, 1, 2, 1, int8(schedules.WeekTypeUneven), nil, nil, int8(schedules.ItemTypeLaboratory), schedules.Location{Kind: schedules.LocationKindOnline, MeetingURL: "https://meet.example.com/lab"}, schedules.Cabinet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testExport{
+		repo: &exporterRepoStub{
+			groups:      map[uuid.UUID]edugroups.EduGroup{group.ID: group},
+			teachers:    map[uuid.UUID]teachers.Teacher{teacher.ID: teacher},
+			departments: map[uuid.UUID]departments.Department{department.ID: department},
+			faculty:     faculties.Faculty{ID: uuid.New(), Name: "Факультет информатики"},
+		},
+		schedule: schedule,
+		teacher:  teacher,
+	}
+}
+
+// calendar returns schedule expanded into dated items of its weeks
+func (e *testExport) calendar(t *testing.T) *schedules.Schedule {
+	t.Helper()
+
+	calendar, err := schedules.CalendarScheduleFromCycled(e.schedule.EduGroupID, e.schedule.Semester, e.schedule.Cycled, e.schedule.Cycled.StartDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calendar.ID = e.schedule.ID
+
+	return calendar
+}
+
+func (e *testExport) factory(t *testing.T, opts ...Option) Factory {
+	t.Helper()
+
+	f, err := NewExporterFactory(e.repo, slog.New(slog.DiscardHandler), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func TestNewExporterFactory(t *testing.T) {
+	cases := map[string]struct {
+		opts []Option
+		fail bool
+	}{
+		"defaults": {},
+		"custom bells": {
+			opts: []Option{Bells(schedules.BellSchedule{{Start: 9 * time.Hour, End: 10 * time.Hour}})},
+		},
+		"empty bells": {
+			opts: []Option{Bells(schedules.BellSchedule{})},
+			fail: true,
+		},
+		"overlapping bells": {
+			opts: []Option{Bells(schedules.BellSchedule{{Start: 9 * time.Hour, End: 10 * time.Hour}, {Start: 9*time.Hour + 30*time.Minute, End: 11 * time.Hour}})},
+			fail: true,
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			_, err := NewExporterFactory(&exporterRepoStub{}, slog.New(slog.DiscardHandler), c.opts...)
+			if (err != nil) != c.fail {
+				t.Errorf("expected failure: %v, got error: %v", c.fail, err)
+			}
+		})
+	}
+}
@@ -1,11 +1,21 @@
 package exporter
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
 	"log/slog"
 	"schedule-generator/internal/domain/cabinets"
 	"schedule-generator/internal/domain/departments"
 	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/faculties"
+	"schedule-generator/internal/domain/schedules"
 	"schedule-generator/internal/domain/teachers"
+	"schedule-generator/internal/infrastructure/db"
+	"sync"
+
+	"github.com/google/uuid"
 )
 
 type ExporterRepository interface {
@@ -13,35 +23,112 @@ type ExporterRepository interface {
 	edugroups.Repository
 	departments.Repository
 	cabinets.Repository
+	faculties.Repository
+
+	GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error)
+	MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error)
+	MapDepartmentByIDs(ctx context.Context, departmentIDs uuid.UUIDs) (map[uuid.UUID]departments.Department, error)
+	GetCsvProfile(ctx context.Context, name string) (*CsvProfile, error)
 }
 
 type exporterFactory struct {
 	repo   ExporterRepository
 	opt    *Options
 	logger *slog.Logger
+	// csvProfiles configured profiles by names with default settings filled, take precedence over stored ones
+	csvProfiles map[string]CsvProfile
+	// pdfFont is loaded on first pdf export and shared by all exports
+	pdfFont func() (*pdfFont, error)
+	// htmlTemplates are parsed on factory creation and shared by all exports
+	htmlTemplates *template.Template
 }
 
-func NewExporterFactory(repo ExporterRepository, logger *slog.Logger, opts ...Option) Factory {
+// NewExporterFactory returns factory of exporters, options are validated once so invalid configuration fails on startup
+func NewExporterFactory(repo ExporterRepository, logger *slog.Logger, opts ...Option) (Factory, error) {
 	o := &Options{
 		CsvDelimeter: DefaultCsvDelimeter,
+		Bells:        schedules.DefaultBellSchedule,
+		PdfSigner:    DefaultPdfSigner,
 	}
 
 	for _, setter := range opts {
 		setter(o)
 	}
 
-	return &exporterFactory{
-		opt:    o,
-		repo:   repo,
-		logger: logger,
+	if err := o.Bells.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bell schedule: %w", err)
 	}
+
+	htmlTemplates, err := loadHtmlTemplates(o.HtmlTemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultProfile := defaultCsvProfile
+	defaultProfile.Delimiter = string(o.CsvDelimeter)
+
+	csvProfiles := map[string]CsvProfile{DefaultCsvProfile: defaultProfile}
+	for _, p := range o.CsvProfiles {
+		csvProfiles[p.Name] = p.withDefaults()
+	}
+
+	return &exporterFactory{
+		opt:         o,
+		repo:        repo,
+		logger:      logger,
+		csvProfiles: csvProfiles,
+		pdfFont: sync.OnceValues(func() (*pdfFont, error) {
+			return loadPdfFont(o.PdfFontPath)
+		}),
+		htmlTemplates: htmlTemplates,
+	}, nil
 }
 
+// ByFormat returns new exporter, references loaded by exporter are shared by all its exports
 func (f *exporterFactory) ByFormat(format string) (Exporter, error) {
+	refs := newExportRefs(f.repo)
+
 	switch format {
 	case "csv":
-		return &csvExporter{repo: f.repo, logger: f.logger.With("exporter", "csv"), delimeter: f.opt.CsvDelimeter}, nil
+		return f.csvExporter(f.csvProfiles[DefaultCsvProfile]), nil
+	case "ics":
+		return &icsExporter{repo: f.repo, refs: refs, logger: f.logger.With("exporter", "ics"), bells: f.opt.Bells}, nil
+	case "xlsx":
+		return &xlsxExporter{repo: f.repo, refs: refs, logger: f.logger.With("exporter", "xlsx"), bells: f.opt.Bells}, nil
+	case "pdf":
+		return &pdfExporter{repo: f.repo, refs: refs, logger: f.logger.With("exporter", "pdf"), bells: f.opt.Bells, font: f.pdfFont, signer: f.opt.PdfSigner}, nil
+	case "html":
+		return &htmlExporter{repo: f.repo, refs: refs, logger: f.logger.With("exporter", "html"), bells: f.opt.Bells, templates: f.htmlTemplates}, nil
+	case "json":
+		return &jsonExporter{repo: f.repo, refs: refs, logger: f.logger.With("exporter", "json")}, nil
 	default:
 		return nil, ErrUnknownFormat
 	}
 }
+
+// ByCsvProfile returns csv exporter of configured profile, profiles missing in configuration are loaded from
+// repository on each call so stored profiles are changed without restart
+func (f *exporterFactory) ByCsvProfile(ctx context.Context, name string) (Exporter, error) {
+	if profile, ok := f.csvProfiles[name]; ok {
+		return f.csvExporter(profile), nil
+	}
+
+	profile, err := f.repo.GetCsvProfile(ctx, name)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownCsvProfile, name)
+		}
+
+		return nil, fmt.Errorf("get csv profile %q error: %w", name, err)
+	}
+
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("stored %w", err)
+	}
+
+	return f.csvExporter(profile.withDefaults()), nil
+}
+
+func (f *exporterFactory) csvExporter(profile CsvProfile) Exporter {
+	return &csvExporter{repo: f.repo, refs: newExportRefs(f.repo), logger: f.logger.With("exporter", "csv"), profile: profile}
+}
@@ -0,0 +1,328 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule-generator/internal/domain/schedules"
+)
+
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "Понедельник",
+	time.Tuesday:   "Вторник",
+	time.Wednesday: "Среда",
+	time.Thursday:  "Четверг",
+	time.Friday:    "Пятница",
+	time.Saturday:  "Суббота",
+	time.Sunday:    "Воскресенье",
+}
+
+// scheduleGrid layout of schedule as printable grid: days as row blocks, lesson periods as rows split by weeks
+// of cycle and subgroups as columns. Drawing of grid is left to format exporters
+type scheduleGrid struct {
+	// WeekRows count of rows per lesson period, one per week of cycle
+	WeekRows  int
+	Lessons   int
+	Subgroups int
+	Days      []gridDay
+}
+
+type gridDay struct {
+	Label string
+	Cells []gridCell
+}
+
+// gridCell items taking rectangle of rows and columns, each item is kept as its text lines. Row is counted
+// from first row of day, column from first subgroup
+type gridCell struct {
+	Row, Col   int
+	Rows, Cols int
+	Items      [][]string
+}
+
+// Text returns text of cell for plain text formats: lines of item are joined by line breaks, items are
+// separated by empty line
+func (c gridCell) Text() string {
+	parts := make([]string, len(c.Items))
+	for i, lines := range c.Items {
+		parts[i] = strings.Join(lines, "\n")
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// DayRows returns count of rows in each day block
+func (g *scheduleGrid) DayRows() int {
+	return g.Lessons * g.WeekRows
+}
+
+// WeekLabel returns label of row of lesson period: "Н"/"Ч" for odd/even weeks, number of week for longer cycles
+func (g *scheduleGrid) WeekLabel(row int) string {
+	week := row%g.WeekRows + 1
+
+	switch g.WeekRows {
+	case 1:
+		return ""
+	case 2:
+		if week%2 == 1 {
+			return "Н"
+		}
+
+		return "Ч"
+	default:
+		return strconv.Itoa(week)
+	}
+}
+
+// newScheduleGrid places items of schedule into grid. Item takes rows of its lesson periods and weeks, and
+// column of its subgroup or all columns for whole group. Items taking the same rectangle are joined in one cell,
+// item overlapped partially by another one is split into single cells. Grid has at least minLessons lesson periods
+func newScheduleGrid(schedule *schedules.Schedule, minLessons int, itemLines func(item schedules.ScheduleItem) ([]string, error)) (*scheduleGrid, error) {
+	type dayItems struct {
+		label string
+		items []schedules.ScheduleItem
+	}
+
+	var days []dayItems
+	grid := scheduleGrid{WeekRows: 1, Lessons: minLessons, Subgroups: 1}
+	cycleLength := 0
+
+	switch schedule.Type {
+	case schedules.ScheduleTypeCycled:
+		cycleLength = schedule.Cycled.CycleLength
+		grid.WeekRows = max(cycleLength, 1)
+
+		for _, d := range schedule.GetWorkingWeek().Days() {
+			days = append(days, dayItems{label: weekdayNames[d], items: schedule.Cycled.ListItemByWeekday(d)})
+		}
+
+		// odd/even items of schedule without rotation still need both weeks to be drawn
+		if grid.WeekRows == 1 && slices.ContainsFunc(schedule.Cycled.ListItem(), func(item schedules.ScheduleItem) bool {
+			return len(item.CycleWeeks) == 0 && item.Weektype != nil && *item.Weektype != schedules.WeekTypeBoth
+		}) {
+			grid.WeekRows = 2
+		}
+	case schedules.ScheduleTypeCalendar:
+		items := slices.Clone(schedule.Calendar.ListItem())
+		slices.SortStableFunc(items, func(a, b schedules.ScheduleItem) int {
+			return a.Date.Compare(*b.Date)
+		})
+
+		for _, item := range items {
+			label := weekdayNames[item.Date.Weekday()] + " " + item.Date.Format("02.01.2006")
+			if len(days) == 0 || days[len(days)-1].label != label {
+				days = append(days, dayItems{label: label})
+			}
+
+			days[len(days)-1].items = append(days[len(days)-1].items, item)
+		}
+	default:
+		return nil, errors.New("unsupported schedule type")
+	}
+
+	for _, item := range schedule.ListItem() {
+		grid.Subgroups = max(grid.Subgroups, int(item.Subgroup))
+		grid.Lessons = max(grid.Lessons, int(item.LessonNumber+item.Span()))
+	}
+
+	for _, day := range days {
+		items := make([]gridItem, len(day.items))
+		for i, item := range day.items {
+			lines, err := itemLines(item)
+			if err != nil {
+				return nil, err
+			}
+
+			items[i] = gridItem{item: item, lines: lines, cycleLength: cycleLength}
+		}
+
+		grid.Days = append(grid.Days, gridDay{Label: day.label, Cells: grid.placeItems(items)})
+	}
+
+	return &grid, nil
+}
+
+// newTimetableGrid places lessons of timetable into grid with single column, subgroups are written into cell
+// text. Lessons of cycled schedules take rows of their weeks, grid has as many week rows as the longest cycle
+func newTimetableGrid(timetable *Timetable, minLessons int, lessonLines func(lesson TimetableLesson) []string) *scheduleGrid {
+	grid := scheduleGrid{WeekRows: 1, Lessons: minLessons, Subgroups: 1}
+
+	for _, day := range timetable.Days {
+		for _, lesson := range day.Lessons {
+			grid.WeekRows = max(grid.WeekRows, lesson.CycleLength)
+			grid.Lessons = max(grid.Lessons, int(lesson.LessonNumber+lesson.Span()))
+
+			if lesson.CycleLength == 1 && len(lesson.CycleWeeks) == 0 && lesson.Weektype != nil && *lesson.Weektype != schedules.WeekTypeBoth {
+				grid.WeekRows = max(grid.WeekRows, 2)
+			}
+		}
+	}
+
+	for _, day := range timetable.Days {
+		label := weekdayNames[day.Weekday]
+		if day.Date != nil {
+			label += " " + day.Date.Format("02.01.2006")
+		}
+
+		items := make([]gridItem, len(day.Lessons))
+		for i, lesson := range day.Lessons {
+			item := lesson.ScheduleItem
+			item.Subgroup = 0
+
+			items[i] = gridItem{item: item, lines: lessonLines(lesson), cycleLength: lesson.CycleLength}
+		}
+
+		grid.Days = append(grid.Days, gridDay{Label: label, Cells: grid.placeItems(items)})
+	}
+
+	return &grid
+}
+
+// gridItem item placed into grid with its text lines and length of cycle of its schedule, zero for dated items
+type gridItem struct {
+	item        schedules.ScheduleItem
+	lines       []string
+	cycleLength int
+}
+
+func (g *scheduleGrid) placeItems(items []gridItem) []gridCell {
+	type unit struct{ row, col int }
+
+	lines := make([][]string, len(items))
+	occupied := make([][]unit, len(items))
+	unitItems := make(map[unit][]int)
+
+	for idx, gi := range items {
+		item, cycleLength := gi.item, gi.cycleLength
+		lines[idx] = gi.lines
+
+		// semester weeks are written into cell text, rows stand for weeks of cycle only
+		cycleItem := item
+		cycleItem.Weeks = nil
+
+		fromCol, toCol := 0, g.Subgroups-1
+		if item.Subgroup > 0 {
+			fromCol = int(item.Subgroup) - 1
+			toCol = fromCol
+		}
+
+		for _, lesson := range item.LessonNumbers() {
+			for week := 1; week <= g.WeekRows; week++ {
+				if cycleLength > 0 && !cycleItem.OccursOnWeek(week, cycleLength) {
+					continue
+				}
+
+				for col := fromCol; col <= toCol; col++ {
+					u := unit{row: int(lesson)*g.WeekRows + week - 1, col: col}
+					occupied[idx] = append(occupied[idx], u)
+					unitItems[u] = append(unitItems[u], idx)
+				}
+			}
+		}
+	}
+
+	var cells []gridCell
+	merged := make(map[unit]bool)
+
+	for idx, units := range occupied {
+		if len(units) == 0 || merged[units[0]] {
+			continue
+		}
+
+		shared := unitItems[units[0]]
+		fromRow, toRow, fromCol, toCol := units[0].row, units[0].row, units[0].col, units[0].col
+		same := true
+
+		for _, u := range units {
+			same = same && slices.Equal(unitItems[u], shared)
+			fromRow, toRow = min(fromRow, u.row), max(toRow, u.row)
+			fromCol, toCol = min(fromCol, u.col), max(toCol, u.col)
+		}
+
+		area := (toRow - fromRow + 1) * (toCol - fromCol + 1)
+		if !same || area != len(units) || shared[0] != idx {
+			continue
+		}
+
+		for _, u := range units {
+			merged[u] = true
+		}
+
+		cells = append(cells, gridCell{
+			Row:   fromRow,
+			Col:   fromCol,
+			Rows:  toRow - fromRow + 1,
+			Cols:  toCol - fromCol + 1,
+			Items: gridCellItems(lines, shared),
+		})
+	}
+
+	for u, shared := range unitItems {
+		if !merged[u] {
+			cells = append(cells, gridCell{Row: u.row, Col: u.col, Rows: 1, Cols: 1, Items: gridCellItems(lines, shared)})
+		}
+	}
+
+	slices.SortFunc(cells, func(a, b gridCell) int {
+		if a.Row != b.Row {
+			return a.Row - b.Row
+		}
+
+		return a.Col - b.Col
+	})
+
+	return cells
+}
+
+func gridCellItems(lines [][]string, idxs []int) [][]string {
+	items := make([][]string, len(idxs))
+	for i, idx := range idxs {
+		items[i] = lines[idx]
+	}
+
+	return items
+}
+
+// formGridItemLines forms lines of item in cell: discipline with lesson type, teachers, location and weeks of
+// semester. Teachers of item must be loaded into refs
+func formGridItemLines(refs *exportRefs, item schedules.ScheduleItem) ([]string, error) {
+	lessonType, err := formLessonType(item.LessonType)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := refs.teacherNames(item)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s (%s)", item.Discipline, lessonType),
+		strings.Join(names, ", "),
+		formLocation(item),
+	}
+
+	if item.Date == nil && len(item.Weeks) > 0 {
+		lines = append(lines, "нед. "+item.Weeks.String())
+	}
+
+	return lines, nil
+}
+
+// formClockTime formats offset from midnight as 15:04
+func formClockTime(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formLessonTime forms time of lesson period, empty for period missing in bell schedule
+func formLessonTime(bells schedules.BellSchedule, lesson int) string {
+	if lesson < 0 || lesson >= len(bells) {
+		return ""
+	}
+
+	return formClockTime(bells[lesson].Start) + "-" + formClockTime(bells[lesson].End)
+}
@@ -0,0 +1,287 @@
+package exporter
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"schedule-generator/internal/domain/schedules"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed templates/*.html
+var htmlTemplatesFS embed.FS
+
+// loadHtmlTemplates parses default templates and then templates of dir, so operators can override any named
+// template, e.g. only "style" or "footer", keeping the rest
+func loadHtmlTemplates(dir string) (*template.Template, error) {
+	tmpl, err := template.ParseFS(htmlTemplatesFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse default html templates error: %w", err)
+	}
+
+	if dir == "" {
+		return tmpl, nil
+	}
+
+	tmpl, err = tmpl.ParseGlob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, fmt.Errorf("parse html templates of %s error: %w", dir, err)
+	}
+
+	return tmpl, nil
+}
+
+// htmlLessonTypes full names of lesson types shown in legend
+var htmlLessonTypes = []struct {
+	lessonType schedules.ItemLessonType
+	title      string
+}{
+	{schedules.ItemTypeLecture, "лекция"},
+	{schedules.ItemTypePractice, "практическое занятие"},
+	{schedules.ItemTypeSeminar, "семинар"},
+	{schedules.ItemTypeLaboratory, "лабораторная работа"},
+	{schedules.ItemTypeExam, "экзамен"},
+}
+
+// htmlExporter renders self-contained html page with schedule grid, legend and print styles
+type htmlExporter struct {
+	repo      ExporterRepository
+	refs      *exportRefs
+	bells     schedules.BellSchedule
+	templates *template.Template
+	logger    *slog.Logger
+}
+
+type htmlPage struct {
+	Title       string
+	Details     []string
+	Caption     string
+	Grid        htmlGrid
+	Legend      []htmlLegendEntry
+	GeneratedAt time.Time
+}
+
+type htmlLegendEntry struct {
+	Abbr  string
+	Title string
+}
+
+type htmlGrid struct {
+	// Weeks grid has row per week of cycle
+	Weeks bool
+	// OddEven weeks are labeled as odd and even
+	OddEven bool
+	Columns []string
+	Days    []htmlDay
+}
+
+type htmlDay struct {
+	Rows []htmlRow
+}
+
+type htmlRow struct {
+	Cells []htmlCell
+}
+
+// htmlCell cell of table row. Kind is one of "day", "lesson", "week" and "item", empty item cell has no items
+type htmlCell struct {
+	Kind    string
+	RowSpan int
+	ColSpan int
+	Lines   []string
+	Title   string
+	Items   []htmlItem
+}
+
+// htmlItem lines of item text, the first one is discipline with lesson type
+type htmlItem struct {
+	Lines []string
+}
+
+func (exp *htmlExporter) Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error {
+	logger := exp.logger.With("schedule_id", schedule.ID)
+
+	group, err := exp.repo.GetEduGroup(ctx, schedule.EduGroupID)
+	if err != nil {
+		logger.Error("Get edu group error", "error", err)
+		return err
+	}
+
+	if err := exp.refs.load(ctx, schedule.ListItem()); err != nil {
+		logger.Error("Load references error", "error", err)
+		return err
+	}
+
+	grid, err := newScheduleGrid(schedule, len(exp.bells), func(item schedules.ScheduleItem) ([]string, error) {
+		return formGridItemLines(exp.refs, item)
+	})
+	if err != nil {
+		logger.Error("Build schedule grid error", "error", err)
+		return err
+	}
+
+	details := []string{fmt.Sprintf("%d семестр", schedule.Semester)}
+	if from, to, ok := schedulePeriod(schedule); ok {
+		details = append(details, "Период: "+from.Format("02.01.2006")+" – "+to.Format("02.01.2006"))
+	}
+
+	return exp.render(dst, "Расписание занятий группы "+group.Number, details, grid)
+}
+
+// ExportTimetable
+func (exp *htmlExporter) ExportTimetable(ctx context.Context, timetable *Timetable, dst io.Writer) error {
+	grid := newTimetableGrid(timetable, len(exp.bells), func(lesson TimetableLesson) []string {
+		item := lesson.ScheduleItem
+
+		discipline := item.Discipline
+		if lessonType, err := formLessonType(item.LessonType); err == nil {
+			discipline += " (" + lessonType + ")"
+		}
+
+		group := "Группа " + lesson.EduGroupNumber
+		if item.Subgroup > 0 {
+			group += fmt.Sprintf(", подгруппа %d", item.Subgroup)
+		}
+
+		lines := []string{discipline, group, strings.Join(lesson.TeacherNames, ", "), formLocation(item)}
+		if item.Date == nil && len(item.Weeks) > 0 {
+			lines = append(lines, "нед. "+item.Weeks.String())
+		}
+
+		return lines
+	})
+
+	return exp.render(dst, "Расписание занятий: "+timetable.Title, timetable.Details, grid)
+}
+
+func (exp *htmlExporter) render(dst io.Writer, title string, details []string, grid *scheduleGrid) error {
+	page := htmlPage{
+		Title:       title,
+		Details:     details,
+		Caption:     title,
+		Grid:        exp.formGrid(grid),
+		GeneratedAt: time.Now(),
+	}
+
+	for _, lt := range htmlLessonTypes {
+		abbr, err := formLessonType(lt.lessonType)
+		if err != nil {
+			return err
+		}
+
+		page.Legend = append(page.Legend, htmlLegendEntry{Abbr: abbr, Title: lt.title})
+	}
+
+	if err := exp.templates.ExecuteTemplate(dst, "page", page); err != nil {
+		exp.logger.Error("Execute html template error", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// formGrid converts grid into table rows. Cells covered by spans of cells above or to the left are skipped,
+// units of grid without items are kept as empty cells
+func (exp *htmlExporter) formGrid(grid *scheduleGrid) htmlGrid {
+	res := htmlGrid{Weeks: grid.WeekRows > 1, OddEven: grid.WeekRows == 2}
+
+	for sg := range grid.Subgroups {
+		if grid.Subgroups > 1 {
+			res.Columns = append(res.Columns, "Подгруппа "+strconv.Itoa(sg+1))
+		} else {
+			res.Columns = append(res.Columns, "Занятия")
+		}
+	}
+
+	dayRows := grid.DayRows()
+
+	for _, day := range grid.Days {
+		type unit struct{ row, col int }
+
+		starts := make(map[unit]gridCell)
+		covered := make(map[unit]bool)
+		for _, cell := range day.Cells {
+			starts[unit{cell.Row, cell.Col}] = cell
+
+			for r := cell.Row; r < cell.Row+cell.Rows; r++ {
+				for c := cell.Col; c < cell.Col+cell.Cols; c++ {
+					covered[unit{r, c}] = true
+				}
+			}
+		}
+
+		rows := make([]htmlRow, dayRows)
+		for r := range rows {
+			var cells []htmlCell
+
+			if r == 0 {
+				cells = append(cells, htmlCell{Kind: "day", RowSpan: dayRows, Lines: []string{day.Label}})
+			}
+
+			if r%grid.WeekRows == 0 {
+				lesson := r / grid.WeekRows
+				lines := []string{strconv.Itoa(lesson + 1)}
+				if t := formLessonTime(exp.bells, lesson); t != "" {
+					lines = append(lines, t)
+				}
+
+				cells = append(cells, htmlCell{Kind: "lesson", RowSpan: grid.WeekRows, Lines: lines})
+			}
+
+			if res.Weeks {
+				cells = append(cells, htmlCell{Kind: "week", Lines: []string{grid.WeekLabel(r)}, Title: htmlWeekTitle(grid, r)})
+			}
+
+			for col := range grid.Subgroups {
+				u := unit{r, col}
+
+				cell, ok := starts[u]
+				switch {
+				case ok:
+					cells = append(cells, htmlCell{Kind: "item", RowSpan: cell.Rows, ColSpan: cell.Cols, Items: htmlItems(cell.Items)})
+				case !covered[u]:
+					cells = append(cells, htmlCell{Kind: "item", RowSpan: 1, ColSpan: 1})
+				}
+			}
+
+			rows[r] = htmlRow{Cells: cells}
+		}
+
+		res.Days = append(res.Days, htmlDay{Rows: rows})
+	}
+
+	return res
+}
+
+func htmlWeekTitle(grid *scheduleGrid, row int) string {
+	if grid.WeekRows == 2 {
+		if row%2 == 0 {
+			return "нечётная неделя"
+		}
+
+		return "чётная неделя"
+	}
+
+	return "неделя цикла " + grid.WeekLabel(row)
+}
+
+// htmlItems converts items of grid cell, empty lines, e.g. of items without teachers, are skipped
+func htmlItems(cellItems [][]string) []htmlItem {
+	items := make([]htmlItem, len(cellItems))
+
+	for i, lines := range cellItems {
+		for _, line := range lines {
+			if line != "" {
+				items[i].Lines = append(items[i].Lines, line)
+			}
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHtmlExporter_Export(t *testing.T) {
+	e := newTestExport(t)
+
+	exp, err := e.factory(t).ByFormat("html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := exp.Export(context.Background(), e.schedule, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"<title>Расписание занятий группы 101</title>",
+		// item lines are rendered as structured paragraph, discipline first
+		`<p><span class="discipline">Математика; анализ, часть 1 (лек.)</span><br>` + e.teacher.Name + "<br>УК1-101</p>",
+		`<span class="discipline">Программирование (лаб.)</span>`,
+		`<th scope="col">Занятия</th>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+}
+
+func TestNewExporterFactory_HtmlTemplates(t *testing.T) {
+	writeTemplates := func(t *testing.T, content string) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "custom.html"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return dir
+	}
+
+	cases := map[string]struct {
+		content string
+		fail    bool
+		want    string
+	}{
+		"overridden footer": {
+			content: `{{define "footer"}}<footer>Деканат</footer>{{end}}`,
+			want:    "<footer>Деканат</footer>",
+		},
+		"invalid template": {
+			content: `{{define "footer"}}{{.Missing`,
+			fail:    true,
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			e := newTestExport(t)
+
+			f, err := NewExporterFactory(e.repo, slog.New(slog.DiscardHandler), HtmlTemplatesDir(writeTemplates(t, c.content)))
+			if (err != nil) != c.fail {
+				t.Fatalf("expected failure: %v, got error: %v", c.fail, err)
+			}
+
+			if c.fail {
+				return
+			}
+
+			exp, err := f.ByFormat("html")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := exp.Export(context.Background(), e.schedule, &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(buf.String(), c.want) {
+				t.Errorf("expected page to contain %q", c.want)
+			}
+		})
+	}
+}
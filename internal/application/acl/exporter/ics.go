@@ -0,0 +1,177 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"schedule-generator/internal/common"
+	"schedule-generator/internal/domain/schedules"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icsTimeLayout = "20060102T150405Z"
+	// icsLineLimit max length of content line in octets, longer lines are folded
+	icsLineLimit = 75
+)
+
+// icsExporter writes dated items as iCalendar events. Cycled schedule must be converted to calendar before export
+type icsExporter struct {
+	repo   ExporterRepository
+	refs   *exportRefs
+	bells  schedules.BellSchedule
+	logger *slog.Logger
+}
+
+func (exp *icsExporter) calendarOnly() {}
+
+func (exp *icsExporter) Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error {
+	if schedule.Type != schedules.ScheduleTypeCalendar {
+		return errors.New("only calendar schedule can be exported as ics")
+	}
+
+	logger := exp.logger.With("schedule_id", schedule.ID)
+
+	group, err := exp.repo.GetEduGroup(ctx, schedule.EduGroupID)
+	if err != nil {
+		logger.Error("Get edu group error", "error", err)
+		return err
+	}
+
+	w := &icsWriter{w: bufio.NewWriter(dst)}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//schedule-generator//schedule//RU")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.property("X-WR-CALNAME", group.Number)
+	w.property("X-WR-TIMEZONE", common.DefaultTimezone.String())
+
+	stamp := time.Now().UTC().Format(icsTimeLayout)
+
+	if err := exp.refs.load(ctx, schedule.Calendar.ListItem()); err != nil {
+		logger.Error("Load references error", "error", err)
+		return err
+	}
+
+	for _, item := range schedule.Calendar.ListItem() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		lessonTime, ok := exp.bells.ItemTime(item)
+		if !ok || item.Date == nil {
+			logger.Warn("Item is out of bell schedule, skipped", "item_id", item.ID, "lesson_number", item.LessonNumber)
+			continue
+		}
+
+		names, err := exp.refs.teacherNames(item)
+		if err != nil {
+			logger.Error("Get teacher names error", "error", err)
+			return err
+		}
+
+		lessonType, err := formLessonType(item.LessonType)
+		if err != nil {
+			return err
+		}
+
+		y, m, d := item.Date.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, common.DefaultTimezone)
+
+		description := []string{
+			"Преподаватель: " + strings.Join(names, ", "),
+			"Тип занятия: " + lessonType,
+		}
+		if item.Subgroup > 0 {
+			description = append(description, "Подгруппа: "+strconv.FormatInt(int64(item.Subgroup), 10))
+		}
+
+		w.line("BEGIN:VEVENT")
+		// item ids are stable, so calendar applications update imported events instead of duplicating them
+		w.property("UID", item.ID.String()+"@schedule-generator")
+		w.property("SEQUENCE", strconv.Itoa(schedule.Version))
+		w.property("DTSTAMP", stamp)
+		w.property("DTSTART", day.Add(lessonTime.Start).UTC().Format(icsTimeLayout))
+		w.property("DTEND", day.Add(lessonTime.End).UTC().Format(icsTimeLayout))
+		w.text("SUMMARY", fmt.Sprintf("%s (%s)", item.Discipline, lessonType))
+		w.text("DESCRIPTION", strings.Join(description, "\n"))
+		w.text("LOCATION", formLocation(item))
+		if item.Location.Kind == schedules.LocationKindOnline {
+			w.property("URL", item.Location.MeetingURL)
+		}
+		w.text("CATEGORIES", lessonType)
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+
+	return w.flush()
+}
+
+// icsWriter writes content lines with CRLF line breaks and folding of long lines
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *icsWriter) property(name, value string) {
+	w.line(name + ":" + value)
+}
+
+// text writes property of TEXT value type with escaped special characters
+func (w *icsWriter) text(name, value string) {
+	w.property(name, escapeIcsText(value))
+}
+
+func (w *icsWriter) line(s string) {
+	if w.err != nil {
+		return
+	}
+
+	limit := icsLineLimit
+	for len(s) > limit {
+		// fold by octets without splitting multi-byte characters
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		_, w.err = w.w.WriteString(s[:cut] + "\r\n ")
+		if w.err != nil {
+			return
+		}
+
+		s = s[cut:]
+		// continuation line starts with space
+		limit = icsLineLimit - 1
+	}
+
+	_, w.err = w.w.WriteString(s + "\r\n")
+}
+
+func (w *icsWriter) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	return w.w.Flush()
+}
+
+var icsTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeIcsText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestIcsWriter_Line(t *testing.T) {
+	cases := map[string]struct {
+		line string
+		want []string
+	}{
+		"short line": {
+			line: "SUMMARY:Математика",
+			want: []string{"SUMMARY:Математика"},
+		},
+		"line of limit length": {
+			line: strings.Repeat("a", icsLineLimit),
+			want: []string{strings.Repeat("a", icsLineLimit)},
+		},
+		"long ascii line": {
+			line: strings.Repeat("a", 2*icsLineLimit),
+			want: []string{strings.Repeat("a", icsLineLimit), " " + strings.Repeat("a", icsLineLimit-1), " a"},
+		},
+		"multi-byte characters are not split": {
+			// 8 octets of property name and 2 octets per character
+			line: "SUMMARY:" + strings.Repeat("я", 40),
+			want: []string{"SUMMARY:" + strings.Repeat("я", 33), " " + strings.Repeat("я", 7)},
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w := &icsWriter{w: bufio.NewWriter(&buf)}
+			w.line(c.line)
+			if err := w.flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+			if !slices.Equal(got, c.want) {
+				t.Errorf("expected lines %q, got: %q", c.want, got)
+			}
+
+			for _, l := range got {
+				if len(l) > icsLineLimit || !utf8.ValidString(l) {
+					t.Errorf("invalid folded line %q", l)
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeIcsText(t *testing.T) {
+	cases := map[string]string{
+		"plain text":                  "plain text",
+		"a;b,c":                       `a\;b\,c`,
+		`back\slash`:                  `back\\slash`,
+		"first line\nsecond line":     `first line\nsecond line`,
+		"first line\r\nsecond line":   `first line\nsecond line`,
+		`already escaped \n sequence`: `already escaped \\n sequence`,
+	}
+
+	for in, want := range cases {
+		if got := escapeIcsText(in); got != want {
+			t.Errorf("escape %q: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestIcsExporter_Export(t *testing.T) {
+	e := newTestExport(t)
+	calendar := e.calendar(t)
+
+	export := func(t *testing.T) string {
+		exp, err := e.factory(t).ByFormat("ics")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := exp.Export(context.Background(), calendar, &buf); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	out := export(t)
+
+	t.Run("content lines", func(t *testing.T) {
+		if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+			t.Fatalf("expected calendar object, got: %q", out)
+		}
+
+		for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+			if len(l) > icsLineLimit || strings.Contains(l, "\n") {
+				t.Errorf("invalid content line %q", l)
+			}
+		}
+	})
+
+	// unfolded event properties
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+
+	t.Run("events", func(t *testing.T) {
+		// lecture on both weeks and laboratory on odd week
+		if n := strings.Count(unfolded, "BEGIN:VEVENT"); n != 3 {
+			t.Fatalf("expected 3 events, got: %d", n)
+		}
+
+		for _, want := range []string{
+			`SUMMARY:Математика\; анализ\, часть 1 (лек.)`,
+			"DTSTART:20250901T053000Z",
+			"DTEND:20250901T070000Z",
+			"LOCATION:УК1-101",
+			"URL:https://meet.example.com/lab",
+			"DESCRIPTION:Преподаватель: " + e.teacher.Name,
+		} {
+			if !strings.Contains(unfolded, want+"\r\n") && !strings.Contains(unfolded, want+`\n`) {
+				t.Errorf("expected export to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("stable uids", func(t *testing.T) {
+		uids := regexp.MustCompile(`UID:(\S+)`)
+
+		first := uids.FindAllString(unfolded, -1)
+		second := uids.FindAllString(strings.ReplaceAll(export(t), "\r\n ", ""), -1)
+
+		if len(first) != 3 || !slices.Equal(first, second) {
+			t.Errorf("expected the same uids on each export, got: %v and %v", first, second)
+		}
+
+		for _, item := range calendar.ListItem() {
+			if !slices.Contains(first, "UID:"+item.ID.String()+"@schedule-generator") {
+				t.Errorf("expected uid of item %s", item.ID)
+			}
+		}
+	})
+
+	t.Run("cycled schedule", func(t *testing.T) {
+		exp, err := e.factory(t).ByFormat("ics")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := exp.Export(context.Background(), e.schedule, &bytes.Buffer{}); err == nil {
+			t.Error("expected error on exporting cycled schedule, got nil")
+		}
+	})
+}
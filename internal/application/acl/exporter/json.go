@@ -0,0 +1,207 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"schedule-generator/internal/domain/schedules"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScheduleDocumentFormat = "schedule-generator/schedule"
+	// ScheduleDocumentVersion current version of document layout, documents of later versions are rejected
+	ScheduleDocumentVersion = 1
+)
+
+var ErrInvalidDocument = errors.New("invalid schedule document")
+
+var scheduleDocumentTypes = map[schedules.ScheduleType]string{
+	schedules.ScheduleTypeCycled:   "cycled",
+	schedules.ScheduleTypeCalendar: "calendar",
+}
+
+// ScheduleDocument versioned json representation of schedule which can be imported into another environment.
+// Teachers are referenced by external id and cabinets by building and auditorium, dates are written without time
+type ScheduleDocument struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	// EduGroupNumber informational, schedule is imported into group chosen by user
+	EduGroupNumber string                 `json:"edu_group_number"`
+	Semester       int                    `json:"semester"`
+	Type           string                 `json:"type"`
+	StartDate      string                 `json:"start_date,omitempty"`
+	EndDate        string                 `json:"end_date,omitempty"`
+	CycleLength    int                    `json:"cycle_length,omitempty"`
+	WorkingDays    []time.Weekday         `json:"working_days"`
+	Items          []ScheduleDocumentItem `json:"items"`
+}
+
+type ScheduleDocumentItem struct {
+	// ID item id in source environment, imported items get new ids
+	ID            uuid.UUID                 `json:"id"`
+	Discipline    string                    `json:"discipline"`
+	Teachers      []ScheduleDocumentTeacher `json:"teachers"`
+	Weekday       time.Weekday              `json:"weekday"`
+	Date          string                    `json:"date,omitempty"`
+	Weeknum       *int                      `json:"weeknum,omitempty"`
+	StudentsCount int16                     `json:"students_count"`
+	LessonNumber  int8                      `json:"lesson_number"`
+	Duration      int8                      `json:"duration"`
+	Subgroup      int8                      `json:"subgroup"`
+	Weektype      *int8                     `json:"weektype,omitempty"`
+	CycleWeeks    []int                     `json:"cycle_weeks,omitempty"`
+	Weeks         []int                     `json:"weeks,omitempty"`
+	LessonType    int8                      `json:"lesson_type"`
+	Location      ScheduleDocumentLocation  `json:"location"`
+	Cabinet       *ScheduleDocumentCabinet  `json:"cabinet,omitempty"`
+}
+
+type ScheduleDocumentTeacher struct {
+	ExternalID string `json:"external_id"`
+	// Name informational, teacher is resolved by external id
+	Name string `json:"name,omitempty"`
+	Role int8   `json:"role"`
+}
+
+type ScheduleDocumentLocation struct {
+	Kind       int8   `json:"kind"`
+	MeetingURL string `json:"meeting_url,omitempty"`
+	Address    string `json:"address,omitempty"`
+}
+
+type ScheduleDocumentCabinet struct {
+	Building   string `json:"building"`
+	Auditorium string `json:"auditorium"`
+}
+
+// ScheduleType returns type of documented schedule
+func (d *ScheduleDocument) ScheduleType() (schedules.ScheduleType, error) {
+	for t, name := range scheduleDocumentTypes {
+		if name == d.Type {
+			return t, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unknown schedule type %q", ErrInvalidDocument, d.Type)
+}
+
+// DecodeScheduleDocument reads schedule document checking its format and version
+func DecodeScheduleDocument(src io.Reader) (*ScheduleDocument, error) {
+	var doc ScheduleDocument
+	if err := json.NewDecoder(src).Decode(&doc); err != nil {
+		return nil, errors.Join(ErrInvalidDocument, err)
+	}
+
+	if doc.Format != ScheduleDocumentFormat {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidDocument, doc.Format)
+	}
+
+	if doc.Version < 1 || doc.Version > ScheduleDocumentVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidDocument, doc.Version)
+	}
+
+	return &doc, nil
+}
+
+// jsonExporter writes schedule as ScheduleDocument
+type jsonExporter struct {
+	repo   ExporterRepository
+	refs   *exportRefs
+	logger *slog.Logger
+}
+
+func (exp *jsonExporter) Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error {
+	logger := exp.logger.With("schedule_id", schedule.ID)
+
+	group, err := exp.repo.GetEduGroup(ctx, schedule.EduGroupID)
+	if err != nil {
+		logger.Error("Get edu group error", "error", err)
+		return err
+	}
+
+	doc := ScheduleDocument{
+		Format:         ScheduleDocumentFormat,
+		Version:        ScheduleDocumentVersion,
+		ExportedAt:     time.Now(),
+		EduGroupNumber: group.Number,
+		Semester:       schedule.Semester,
+		Type:           scheduleDocumentTypes[schedule.Type],
+		WorkingDays:    schedule.GetWorkingWeek().Days(),
+		Items:          []ScheduleDocumentItem{},
+	}
+
+	if schedule.Type == schedules.ScheduleTypeCycled {
+		doc.StartDate = schedule.Cycled.StartDate.Format(time.DateOnly)
+		doc.EndDate = schedule.Cycled.EndDate.Format(time.DateOnly)
+		doc.CycleLength = schedule.Cycled.CycleLength
+	}
+
+	if err := exp.refs.load(ctx, schedule.ListItem()); err != nil {
+		logger.Error("Load references error", "error", err)
+		return err
+	}
+
+	for _, item := range schedule.ListItem() {
+		docItem := ScheduleDocumentItem{
+			ID:            item.ID,
+			Discipline:    item.Discipline,
+			Weekday:       item.Weekday,
+			Weeknum:       item.Weeknum,
+			StudentsCount: item.StudentsCount,
+			LessonNumber:  item.LessonNumber,
+			Duration:      item.Duration,
+			Subgroup:      item.Subgroup,
+			LessonType:    int8(item.LessonType),
+			Location: ScheduleDocumentLocation{
+				Kind:       int8(item.Location.Kind),
+				MeetingURL: item.Location.MeetingURL,
+				Address:    item.Location.Address,
+			},
+		}
+
+		// dated items expanded from cycled schedule keep its weeks, they are not imported for dated items
+		if item.Date != nil {
+			docItem.Date = item.Date.Format(time.DateOnly)
+		} else {
+			docItem.CycleWeeks = item.CycleWeeks
+			docItem.Weeks = item.Weeks
+
+			if item.Weektype != nil {
+				wt := int8(*item.Weektype)
+				docItem.Weektype = &wt
+			}
+		}
+
+		if item.InCabinet() {
+			docItem.Cabinet = &ScheduleDocumentCabinet{Building: item.Cabinet.Building, Auditorium: item.Cabinet.Auditorium}
+		}
+
+		for _, it := range item.Teachers {
+			teacher, err := exp.refs.teacher(it.TeacherID)
+			if err != nil {
+				logger.Error("Get teacher error", "error", err)
+				return err
+			}
+
+			docItem.Teachers = append(docItem.Teachers, ScheduleDocumentTeacher{
+				ExternalID: teacher.ExternalID,
+				Name:       teacher.Name,
+				Role:       int8(it.Role),
+			})
+		}
+
+		doc.Items = append(doc.Items, docItem)
+	}
+
+	enc := json.NewEncoder(dst)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeScheduleDocument(t *testing.T) {
+	cases := map[string]struct {
+		doc  string
+		fail bool
+	}{
+		"current version": {
+			doc: `{"format": "schedule-generator/schedule", "version": 1, "type": "cycled", "items": []}`,
+		},
+		"later version": {
+			doc:  `{"format": "schedule-generator/schedule", "version": 2, "type": "cycled", "items": []}`,
+			fail: true,
+		},
+		"missing version": {
+			doc:  `{"format": "schedule-generator/schedule", "type": "cycled", "items": []}`,
+			fail: true,
+		},
+		"unknown format": {
+			doc:  `{"format": "timetable", "version": 1}`,
+			fail: true,
+		},
+		"malformed json": {
+			doc:  `{"format": "schedule-generator/schedule",`,
+			fail: true,
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			_, err := DecodeScheduleDocument(strings.NewReader(c.doc))
+			if (err != nil) != c.fail {
+				t.Fatalf("expected failure: %v, got error: %v", c.fail, err)
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidDocument) {
+				t.Errorf("expected invalid document error, got: %v", err)
+			}
+		})
+	}
+}
@@ -1,11 +1,25 @@
 package exporter
 
+import "schedule-generator/internal/domain/schedules"
+
 const (
 	DefaultCsvDelimeter = ';'
+	DefaultPdfSigner    = "Заместитель декана"
 )
 
 type Options struct {
+	// CsvDelimeter delimeter of default csv profile
 	CsvDelimeter rune
+	// CsvProfiles named csv layouts of downstream systems besides default one
+	CsvProfiles []CsvProfile
+	// Bells times of lesson periods used by formats with exact lesson time
+	Bells schedules.BellSchedule
+	// PdfFontPath TrueType font embedded into pdf documents, must contain cyrillic glyphs
+	PdfFontPath string
+	// PdfSigner position of person signing printed schedules
+	PdfSigner string
+	// HtmlTemplatesDir directory with *.html templates overriding default templates of html exports
+	HtmlTemplatesDir string
 }
 
 type Option func(*Options)
@@ -15,3 +29,33 @@ func CsvDelimeter(delim rune) Option {
 		o.CsvDelimeter = delim
 	}
 }
+
+func CsvProfiles(profiles ...CsvProfile) Option {
+	return func(o *Options) {
+		o.CsvProfiles = profiles
+	}
+}
+
+func Bells(bells schedules.BellSchedule) Option {
+	return func(o *Options) {
+		o.Bells = bells
+	}
+}
+
+func PdfFontPath(path string) Option {
+	return func(o *Options) {
+		o.PdfFontPath = path
+	}
+}
+
+func PdfSigner(signer string) Option {
+	return func(o *Options) {
+		o.PdfSigner = signer
+	}
+}
+
+func HtmlTemplatesDir(dir string) Option {
+	return func(o *Options) {
+		o.HtmlTemplatesDir = dir
+	}
+}
@@ -0,0 +1,505 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"schedule-generator/internal/domain/schedules"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pdfMargin       = 28.0
+	pdfTitleSize    = 14.0
+	pdfDetailSize   = 10.0
+	pdfHeaderSize   = 8.0
+	pdfCellSize     = 7.0
+	pdfMinRowHeight = 11.0
+	pdfLineWidth    = 0.4
+)
+
+// pdfExporter renders printable weekly grid of group schedule or list of lessons of teacher or cabinet
+// timetable, both finished with signature block
+type pdfExporter struct {
+	repo   ExporterRepository
+	refs   *exportRefs
+	bells  schedules.BellSchedule
+	font   func() (*pdfFont, error)
+	signer string
+	logger *slog.Logger
+}
+
+// pdfLayout tracks vertical position on current page
+type pdfLayout struct {
+	doc *pdfDocument
+	y   float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.doc.addPage()
+	l.y = pdfMargin
+}
+
+func (l *pdfLayout) fits(h float64) bool {
+	return l.y+h <= pdfPageHeight-pdfMargin
+}
+
+func (exp *pdfExporter) Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error {
+	logger := exp.logger.With("schedule_id", schedule.ID)
+
+	font, err := exp.font()
+	if err != nil {
+		logger.Error("Load pdf font error", "error", err)
+		return err
+	}
+
+	group, err := exp.repo.GetEduGroup(ctx, schedule.EduGroupID)
+	if err != nil {
+		logger.Error("Get edu group error", "error", err)
+		return err
+	}
+
+	facultyID, err := exp.repo.GetEduGroupFacultyID(ctx, group.ID)
+	if err != nil {
+		logger.Error("Get edu group faculty id error", "error", err)
+		return err
+	}
+
+	faculty, err := exp.repo.GetFaculty(ctx, facultyID)
+	if err != nil {
+		logger.Error("Get faculty error", "error", err)
+		return err
+	}
+
+	if err := exp.refs.load(ctx, schedule.ListItem()); err != nil {
+		logger.Error("Load references error", "error", err)
+		return err
+	}
+
+	grid, err := newScheduleGrid(schedule, 0, func(item schedules.ScheduleItem) ([]string, error) {
+		return formGridItemLines(exp.refs, item)
+	})
+	if err != nil {
+		logger.Error("Build schedule grid error", "error", err)
+		return err
+	}
+
+	details := []string{
+		"Факультет: " + faculty.Name,
+		fmt.Sprintf("Группа %s, %d семестр", group.Number, schedule.Semester),
+	}
+
+	if from, to, ok := schedulePeriod(schedule); ok {
+		details = append(details, "Период: "+from.Format("02.01.2006")+" – "+to.Format("02.01.2006"))
+	}
+
+	l := &pdfLayout{doc: newPdfDocument(font)}
+	l.newPage()
+
+	exp.drawTitle(l, "РАСПИСАНИЕ ЗАНЯТИЙ", details)
+	exp.drawGrid(l, grid)
+	exp.drawSignature(l)
+
+	return l.doc.write(dst)
+}
+
+// ExportTimetable
+func (exp *pdfExporter) ExportTimetable(ctx context.Context, timetable *Timetable, dst io.Writer) error {
+	font, err := exp.font()
+	if err != nil {
+		exp.logger.Error("Load pdf font error", "error", err)
+		return err
+	}
+
+	l := &pdfLayout{doc: newPdfDocument(font)}
+	l.newPage()
+
+	exp.drawTitle(l, "РАСПИСАНИЕ ЗАНЯТИЙ", append([]string{timetable.Title}, timetable.Details...))
+	exp.drawTimetable(l, timetable)
+	exp.drawSignature(l)
+
+	return l.doc.write(dst)
+}
+
+func (exp *pdfExporter) drawTitle(l *pdfLayout, title string, details []string) {
+	doc := l.doc
+
+	l.y += pdfTitleSize
+	doc.text((pdfPageWidth-doc.textWidth(title, pdfTitleSize))/2, l.y, pdfTitleSize, title, true)
+	l.y += pdfTitleSize * 0.6
+
+	for _, line := range details {
+		l.y += pdfDetailSize * 1.3
+		doc.text((pdfPageWidth-doc.textWidth(line, pdfDetailSize))/2, l.y, pdfDetailSize, line, false)
+	}
+
+	l.y += pdfDetailSize
+}
+
+// drawGrid draws days of grid splitting them between pages by lesson periods not crossed by double lessons
+func (exp *pdfExporter) drawGrid(l *pdfLayout, grid *scheduleGrid) {
+	doc := l.doc
+
+	dayW, numW, timeW, weekW := 24.0, 18.0, 52.0, 0.0
+	if grid.WeekRows > 1 {
+		weekW = 20
+	}
+
+	lessonsX := pdfMargin + dayW + numW + timeW + weekW
+	subW := (pdfPageWidth - pdfMargin - lessonsX) / float64(grid.Subgroups)
+
+	drawHeader := func() {
+		const h = 16.0
+
+		headers := []struct {
+			x, w float64
+			text string
+		}{
+			{pdfMargin, dayW, "День"},
+			{pdfMargin + dayW, numW, "№"},
+			{pdfMargin + dayW + numW, timeW, "Время"},
+			{pdfMargin + dayW + numW + timeW, weekW, "Нед."},
+		}
+
+		for sg := range grid.Subgroups {
+			text := "Занятия"
+			if grid.Subgroups > 1 {
+				text = "Подгруппа " + strconv.Itoa(sg+1)
+			}
+
+			headers = append(headers, struct {
+				x, w float64
+				text string
+			}{lessonsX + float64(sg)*subW, subW, text})
+		}
+
+		for _, hd := range headers {
+			if hd.w == 0 {
+				continue
+			}
+
+			doc.rect(hd.x, l.y, hd.w, h, pdfLineWidth)
+			doc.textBox(hd.x, l.y, hd.w, h, pdfHeaderSize-1, hd.text, pdfAlignCenter, true)
+		}
+
+		l.y += h
+	}
+
+	drawHeader()
+
+	for _, day := range grid.Days {
+		heights := exp.gridRowHeights(doc, grid, day, subW)
+
+		// bands of rows which can not be split between pages
+		var bands [][2]int
+		start := 0
+		for b := grid.WeekRows; b <= len(heights); b += grid.WeekRows {
+			crossed := false
+			for _, cell := range day.Cells {
+				crossed = crossed || cell.Row < b && b < cell.Row+cell.Rows
+			}
+
+			if !crossed {
+				bands = append(bands, [2]int{start, b})
+				start = b
+			}
+		}
+
+		segmentTop, segmentFrom := l.y, 0
+		closeSegment := func(to int) {
+			h := l.y - segmentTop
+			if h <= 0 {
+				return
+			}
+
+			doc.rect(pdfMargin, segmentTop, dayW, h, pdfLineWidth)
+
+			label := day.Label
+			if segmentFrom > 0 {
+				label += " (продолжение)"
+			}
+
+			width := doc.textWidth(label, pdfHeaderSize)
+			doc.verticalText(pdfMargin+dayW/2+pdfHeaderSize/3, segmentTop+h-max((h-width)/2, 2), pdfHeaderSize, label, true)
+			segmentFrom = to
+		}
+
+		for _, band := range bands {
+			var bandH float64
+			for r := band[0]; r < band[1]; r++ {
+				bandH += heights[r]
+			}
+
+			if !l.fits(bandH) {
+				closeSegment(band[0])
+				l.newPage()
+				drawHeader()
+				segmentTop = l.y
+			}
+
+			exp.drawGridBand(l, grid, day, heights, band, lessonsX, subW, dayW, numW, timeW, weekW)
+		}
+
+		closeSegment(len(heights))
+	}
+}
+
+// gridRowHeights returns heights of rows of day enough for texts of cells
+func (exp *pdfExporter) gridRowHeights(doc *pdfDocument, grid *scheduleGrid, day gridDay, subW float64) []float64 {
+	heights := make([]float64, grid.DayRows())
+	for r := range heights {
+		heights[r] = pdfMinRowHeight
+	}
+
+	for _, cell := range day.Cells {
+		if cell.Rows == 1 {
+			heights[cell.Row] = max(heights[cell.Row], doc.textHeight(cell.Text(), pdfCellSize, subW*float64(cell.Cols)))
+		}
+	}
+
+	for _, cell := range day.Cells {
+		if cell.Rows == 1 {
+			continue
+		}
+
+		var have float64
+		for r := cell.Row; r < cell.Row+cell.Rows; r++ {
+			have += heights[r]
+		}
+
+		need := doc.textHeight(cell.Text(), pdfCellSize, subW*float64(cell.Cols))
+		if need > have {
+			for r := cell.Row; r < cell.Row+cell.Rows; r++ {
+				heights[r] += (need - have) / float64(cell.Rows)
+			}
+		}
+	}
+
+	return heights
+}
+
+func (exp *pdfExporter) drawGridBand(
+	l *pdfLayout,
+	grid *scheduleGrid,
+	day gridDay,
+	heights []float64,
+	band [2]int,
+	lessonsX, subW, dayW, numW, timeW, weekW float64,
+) {
+	doc := l.doc
+
+	rowY := make(map[int]float64, band[1]-band[0]+1)
+	y := l.y
+	for r := band[0]; r <= band[1]; r++ {
+		rowY[r] = y
+		if r < band[1] {
+			y += heights[r]
+		}
+	}
+
+	for r := band[0]; r < band[1]; r++ {
+		if weekW > 0 {
+			doc.rect(pdfMargin+dayW+numW+timeW, rowY[r], weekW, heights[r], pdfLineWidth)
+			doc.textBox(pdfMargin+dayW+numW+timeW, rowY[r], weekW, heights[r], pdfCellSize, grid.WeekLabel(r), pdfAlignCenter, false)
+		}
+
+		for sg := range grid.Subgroups {
+			doc.rect(lessonsX+float64(sg)*subW, rowY[r], subW, heights[r], pdfLineWidth)
+		}
+
+		if r%grid.WeekRows == 0 {
+			lesson := r / grid.WeekRows
+			h := rowY[min(r+grid.WeekRows, band[1])] - rowY[r]
+
+			doc.rect(pdfMargin+dayW, rowY[r], numW, h, pdfLineWidth)
+			doc.textBox(pdfMargin+dayW, rowY[r], numW, h, pdfCellSize, strconv.Itoa(lesson+1), pdfAlignCenter, true)
+			doc.rect(pdfMargin+dayW+numW, rowY[r], timeW, h, pdfLineWidth)
+			doc.textBox(pdfMargin+dayW+numW, rowY[r], timeW, h, pdfCellSize, formLessonTime(exp.bells, lesson), pdfAlignCenter, false)
+		}
+	}
+
+	for _, cell := range day.Cells {
+		if cell.Row < band[0] || cell.Row >= band[1] {
+			continue
+		}
+
+		x, w := lessonsX+float64(cell.Col)*subW, subW*float64(cell.Cols)
+		h := rowY[cell.Row+cell.Rows] - rowY[cell.Row]
+
+		if cell.Rows > 1 || cell.Cols > 1 {
+			doc.clearRect(x, rowY[cell.Row], w, h)
+			doc.rect(x, rowY[cell.Row], w, h, pdfLineWidth)
+		}
+
+		doc.textBox(x, rowY[cell.Row], w, h, pdfCellSize, cell.Text(), pdfAlignCenter, false)
+	}
+
+	l.y = y
+}
+
+// drawTimetable draws lessons as table rows grouped by days
+func (exp *pdfExporter) drawTimetable(l *pdfLayout, timetable *Timetable) {
+	doc := l.doc
+
+	type column struct {
+		title string
+		width float64
+		align pdfAlign
+	}
+
+	columns := []column{
+		{"№", 24, pdfAlignCenter},
+		{"Время", 56, pdfAlignCenter},
+		{"Недели", 64, pdfAlignCenter},
+		{"Группа", 60, pdfAlignCenter},
+		{"Занятие", 0, pdfAlignLeft},
+		{"Преподаватели", 170, pdfAlignLeft},
+		{"Место", 110, pdfAlignLeft},
+	}
+
+	tableW := pdfPageWidth - 2*pdfMargin
+	fixed := 0.0
+	for _, c := range columns {
+		fixed += c.width
+	}
+
+	columns[4].width = tableW - fixed
+
+	drawRow := func(values []string, size float64, bold bool) {
+		h := pdfMinRowHeight
+		for i, v := range values {
+			h = max(h, doc.textHeight(v, size, columns[i].width))
+		}
+
+		if !l.fits(h) {
+			l.newPage()
+		}
+
+		x := pdfMargin
+		for i, v := range values {
+			doc.rect(x, l.y, columns[i].width, h, pdfLineWidth)
+			doc.textBox(x, l.y, columns[i].width, h, size, v, columns[i].align, bold)
+			x += columns[i].width
+		}
+
+		l.y += h
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.title
+	}
+
+	drawRow(header, pdfHeaderSize, true)
+
+	for _, day := range timetable.Days {
+		const dayH = 14.0
+
+		if !l.fits(dayH + pdfMinRowHeight) {
+			l.newPage()
+			drawRow(header, pdfHeaderSize, true)
+		}
+
+		label := weekdayNames[day.Weekday]
+		if day.Date != nil {
+			label += " " + day.Date.Format("02.01.2006")
+		}
+
+		doc.rect(pdfMargin, l.y, tableW, dayH, pdfLineWidth)
+		doc.textBox(pdfMargin, l.y, tableW, dayH, pdfHeaderSize, label, pdfAlignLeft, true)
+		l.y += dayH
+
+		if len(day.Lessons) == 0 {
+			drawRow([]string{"", "", "", "", "Занятий нет", "", ""}, pdfCellSize, false)
+			continue
+		}
+
+		for _, lesson := range day.Lessons {
+			drawRow(exp.timetableRow(lesson), pdfCellSize, false)
+		}
+	}
+}
+
+func (exp *pdfExporter) timetableRow(lesson TimetableLesson) []string {
+	item := lesson.ScheduleItem
+
+	numbers := strconv.Itoa(int(item.LessonNumber) + 1)
+	if item.Span() > 1 {
+		numbers += "-" + strconv.Itoa(int(item.LessonNumber+item.Span()))
+	}
+
+	var lessonTime string
+	if t, ok := exp.bells.ItemTime(item); ok {
+		lessonTime = formClockTime(t.Start) + "-" + formClockTime(t.End)
+	}
+
+	var weeks string
+	if lesson.CycleLength > 0 {
+		weeks = formWeek(item, lesson.CycleLength)
+	}
+
+	group := lesson.EduGroupNumber
+	if item.Subgroup > 0 {
+		group += fmt.Sprintf(" (%d)", item.Subgroup)
+	}
+
+	discipline := item.Discipline
+	if lessonType, err := formLessonType(item.LessonType); err == nil {
+		discipline += " (" + lessonType + ")"
+	}
+
+	return []string{
+		numbers,
+		lessonTime,
+		weeks,
+		group,
+		discipline,
+		strings.Join(lesson.TeacherNames, ", "),
+		formLocation(item),
+	}
+}
+
+// drawSignature draws signature of responsible person with date of approval
+func (exp *pdfExporter) drawSignature(l *pdfLayout) {
+	const h = 60.0
+
+	if !l.fits(h) {
+		l.newPage()
+	}
+
+	l.y += 28
+	l.doc.text(pdfMargin, l.y, pdfDetailSize, exp.signer+"  ____________________ / ____________________ /", false)
+	l.y += 22
+	l.doc.text(pdfMargin, l.y, pdfDetailSize, "«____» ________________ 20____ г.", false)
+	l.y += pdfDetailSize
+}
+
+// schedulePeriod returns first and last dates of schedule
+func schedulePeriod(schedule *schedules.Schedule) (time.Time, time.Time, bool) {
+	switch schedule.Type {
+	case schedules.ScheduleTypeCycled:
+		return schedule.Cycled.StartDate, schedule.Cycled.EndDate, true
+	case schedules.ScheduleTypeCalendar:
+		var from, to time.Time
+		for _, item := range schedule.Calendar.ListItem() {
+			if item.Date == nil {
+				continue
+			}
+
+			if from.IsZero() || item.Date.Before(from) {
+				from = *item.Date
+			}
+
+			if to.IsZero() || item.Date.After(to) {
+				to = *item.Date
+			}
+		}
+
+		return from, to, !from.IsZero()
+	}
+
+	return time.Time{}, time.Time{}, false
+}
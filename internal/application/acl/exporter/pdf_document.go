@@ -0,0 +1,377 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// A4 landscape page size in points
+	pdfPageWidth  = 841.89
+	pdfPageHeight = 595.28
+)
+
+type pdfAlign int8
+
+const (
+	pdfAlignLeft pdfAlign = iota
+	pdfAlignCenter
+)
+
+// pdfDocument minimal PDF writer: single TrueType font embedded as subset of drawn glyphs, text and lines. Coordinates are counted
+// from top left corner of page
+type pdfDocument struct {
+	font  *pdfFont
+	pages []*bytes.Buffer
+	// used glyphs with runes they were drawn for, needed for widths and text extraction
+	used map[uint16]rune
+}
+
+func newPdfDocument(font *pdfFont) *pdfDocument {
+	return &pdfDocument{font: font, used: make(map[uint16]rune)}
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	return d.pages[len(d.pages)-1]
+}
+
+// textWidth returns width of text in points
+func (d *pdfDocument) textWidth(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		w += d.font.advance(d.font.glyph(r))
+	}
+
+	return w * size / 1000
+}
+
+// text draws single line of text with baseline at y. Bold text is imitated by stroking glyph outlines
+func (d *pdfDocument) text(x, y, size float64, s string, bold bool) {
+	d.textMatrix(fmt.Sprintf("1 0 0 1 %s %s", pdfNum(x), pdfNum(pdfPageHeight-y)), size, s, bold)
+}
+
+// verticalText draws text rotated to be read bottom up with baseline at x
+func (d *pdfDocument) verticalText(x, y, size float64, s string, bold bool) {
+	d.textMatrix(fmt.Sprintf("0 1 -1 0 %s %s", pdfNum(x), pdfNum(pdfPageHeight-y)), size, s, bold)
+}
+
+func (d *pdfDocument) textMatrix(matrix string, size float64, s string, bold bool) {
+	if s == "" {
+		return
+	}
+
+	var hex strings.Builder
+	for _, r := range s {
+		glyph := d.font.glyph(r)
+		if _, ok := d.used[glyph]; !ok {
+			d.used[glyph] = r
+		}
+
+		fmt.Fprintf(&hex, "%04X", glyph)
+	}
+
+	p := d.page()
+	p.WriteString("BT\n")
+	if bold {
+		fmt.Fprintf(p, "2 Tr %s w\n", pdfNum(size/30))
+	}
+	fmt.Fprintf(p, "/F1 %s Tf\n%s Tm\n<%s> Tj\n", pdfNum(size), matrix, hex.String())
+	if bold {
+		p.WriteString("0 Tr\n")
+	}
+	p.WriteString("ET\n")
+}
+
+// line draws line of provided width
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s m %s %s l S\n",
+		pdfNum(width), pdfNum(x1), pdfNum(pdfPageHeight-y1), pdfNum(x2), pdfNum(pdfPageHeight-y2))
+}
+
+// rect draws rectangle border
+func (d *pdfDocument) rect(x, y, w, h, width float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s %s %s re S\n",
+		pdfNum(width), pdfNum(x), pdfNum(pdfPageHeight-y-h), pdfNum(w), pdfNum(h))
+}
+
+// clearRect fills rectangle with white color, e.g. to hide borders of cells under merged cell
+func (d *pdfDocument) clearRect(x, y, w, h float64) {
+	fmt.Fprintf(d.page(), "1 g %s %s %s %s re f 0 g\n", pdfNum(x), pdfNum(pdfPageHeight-y-h), pdfNum(w), pdfNum(h))
+}
+
+// wrapText splits text into lines fitting into width. Explicit line breaks are kept, words longer than width
+// are split by characters
+func (d *pdfDocument) wrapText(s string, size, width float64) []string {
+	var lines []string
+
+	for _, paragraph := range strings.Split(s, "\n") {
+		var line string
+
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+
+			if d.textWidth(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			line = ""
+			for _, r := range word {
+				if line != "" && d.textWidth(line+string(r), size) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+
+				line += string(r)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// textBox draws wrapped text centered vertically in box
+func (d *pdfDocument) textBox(x, y, w, h, size float64, s string, align pdfAlign, bold bool) {
+	const padding = 2
+
+	lines := d.wrapText(s, size, w-2*padding)
+	lineHeight := size * 1.2
+	top := y + (h-float64(len(lines))*lineHeight)/2
+
+	for i, line := range lines {
+		lx := x + padding
+		if align == pdfAlignCenter {
+			lx = x + (w-d.textWidth(line, size))/2
+		}
+
+		d.text(lx, top+float64(i)*lineHeight+size, size, line, bold)
+	}
+}
+
+// textHeight returns height of box needed for wrapped text
+func (d *pdfDocument) textHeight(s string, size, width float64) float64 {
+	const padding = 2
+
+	return float64(len(d.wrapText(s, size, width-2*padding)))*size*1.2 + 2*padding
+}
+
+func (d *pdfDocument) write(dst io.Writer) error {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	glyphs := d.usedGlyphs()
+
+	program, err := d.font.subset(glyphs)
+	if err != nil {
+		return fmt.Errorf("subset pdf font error: %w", err)
+	}
+
+	// subset fonts are named with tag unique for set of glyphs
+	fontName := subsetTag(glyphs) + "+ScheduleFont"
+
+	w := &pdfWriter{w: dst}
+	w.raw("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const (
+		catalogID = iota + 1
+		pagesID
+		fontID
+		cidFontID
+		descriptorID
+		fontFileID
+		toUnicodeID
+		firstPageID
+	)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageID+i*2)
+	}
+
+	w.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", fontName, cidFontID, toUnicodeID))
+	w.object(cidFontID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>", fontName, descriptorID, d.widths(glyphs)))
+
+	f := d.font
+	w.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		fontName, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]), f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), fontFileID))
+	w.stream(fontFileID, fmt.Sprintf("/Length1 %d", len(program)), program)
+	w.stream(toUnicodeID, "", []byte(d.toUnicode(glyphs)))
+
+	for i, content := range d.pages {
+		pageID := firstPageID + i*2
+		w.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), fontID, pageID+1))
+		w.stream(pageID+1, "", content.Bytes())
+	}
+
+	w.trailer(catalogID)
+
+	return w.err
+}
+
+// usedGlyphs returns sorted glyphs drawn in document
+func (d *pdfDocument) usedGlyphs() []uint16 {
+	glyphs := make([]uint16, 0, len(d.used))
+	for g := range d.used {
+		glyphs = append(glyphs, g)
+	}
+
+	slices.Sort(glyphs)
+
+	return glyphs
+}
+
+// subsetTag returns six uppercase letters tag of font subset
+func subsetTag(glyphs []uint16) string {
+	h := fnv.New32a()
+	for _, g := range glyphs {
+		binary.Write(h, binary.BigEndian, g)
+	}
+
+	sum := h.Sum32()
+
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(sum%26)
+		sum /= 26
+	}
+
+	return string(tag)
+}
+
+// widths returns W array of glyphs
+func (d *pdfDocument) widths(glyphs []uint16) string {
+	var b strings.Builder
+	for _, g := range glyphs {
+		fmt.Fprintf(&b, "%d [%s] ", g, pdfNum(d.font.advance(g)))
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// toUnicode returns CMap mapping glyphs back to text, so text can be copied and searched
+func (d *pdfDocument) toUnicode(glyphs []uint16) string {
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// bfchar blocks are limited to 100 entries
+	for chunk := range slices.Chunk(glyphs, 100) {
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			var hex strings.Builder
+			for _, u := range utf16.Encode([]rune{d.used[g]}) {
+				fmt.Fprintf(&hex, "%04X", u)
+			}
+
+			fmt.Fprintf(&b, "<%04X> <%s>\n", g, hex.String())
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+
+	return b.String()
+}
+
+// pdfWriter writes numbered objects and keeps their offsets for cross-reference table
+type pdfWriter struct {
+	w       io.Writer
+	offset  int
+	offsets []int
+	err     error
+}
+
+func (w *pdfWriter) raw(s string) {
+	if w.err != nil {
+		return
+	}
+
+	n, err := io.WriteString(w.w, s)
+	w.offset += n
+	w.err = err
+}
+
+func (w *pdfWriter) begin(id int) {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+
+	w.offsets[id-1] = w.offset
+	w.raw(fmt.Sprintf("%d 0 obj\n", id))
+}
+
+func (w *pdfWriter) object(id int, body string) {
+	w.begin(id)
+	w.raw(body + "\nendobj\n")
+}
+
+// stream writes stream object compressed with deflate
+func (w *pdfWriter) stream(id int, dict string, data []byte) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil && w.err == nil {
+		w.err = err
+	}
+	if err := zw.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+
+	w.begin(id)
+	w.raw(fmt.Sprintf("<< /Length %d /Filter /FlateDecode %s >>\nstream\n", compressed.Len(), dict))
+	w.raw(compressed.String())
+	w.raw("\nendstream\nendobj\n")
+}
+
+func (w *pdfWriter) trailer(rootID int) {
+	xref := w.offset
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, rootID, xref)
+
+	w.raw(b.String())
+}
+
+// pdfNum formats number with at most two decimals
+func pdfNum(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	return s
+}
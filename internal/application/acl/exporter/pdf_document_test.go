@@ -0,0 +1,219 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"schedule-generator/internal/domain/schedules"
+)
+
+var (
+	pdfObjectPattern = regexp.MustCompile(`(\d+) 0 obj\n`)
+	pdfStreamPattern = regexp.MustCompile(`(\d+) 0 obj\n<< /Length (\d+) /Filter /FlateDecode ([^\n]*)>>\nstream\n`)
+)
+
+// readTestPdf checks structure of document: header, cross-reference table pointing to objects and trailer.
+// Returns decompressed streams by object ids with their dictionaries
+func readTestPdf(t *testing.T, data []byte) (streams map[int][]byte, dicts map[int]string) {
+	t.Helper()
+
+	s := string(data)
+	if !strings.HasPrefix(s, "%PDF-1.4\n") || !strings.HasSuffix(s, "%%EOF\n") {
+		t.Fatalf("expected pdf document, got: %q", s[:min(len(s), 20)])
+	}
+
+	startxref := strings.LastIndex(s, "startxref\n")
+	xref, err := strconv.Atoi(strings.Fields(s[startxref+len("startxref\n"):])[0])
+	if err != nil || !strings.HasPrefix(s[xref:], "xref\n") {
+		t.Fatalf("invalid startxref: %d", xref)
+	}
+
+	lines := strings.Split(s[xref:startxref], "\n")
+	size, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	if !strings.Contains(s, "/Size "+strconv.Itoa(size)+" ") {
+		t.Errorf("expected trailer of %d objects", size)
+	}
+
+	// first entry is free object zero
+	for id := 1; id < size; id++ {
+		offset, err := strconv.Atoi(strings.Fields(lines[2+id])[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := pdfObjectPattern.FindStringSubmatch(s[offset:])
+		if m == nil || !strings.HasPrefix(s[offset:], m[0]) || m[1] != strconv.Itoa(id) {
+			t.Errorf("cross-reference of object %d points to %q", id, s[offset:min(len(s), offset+10)])
+		}
+	}
+
+	streams, dicts = make(map[int][]byte), make(map[int]string)
+	for _, m := range pdfStreamPattern.FindAllStringSubmatchIndex(s, -1) {
+		id, _ := strconv.Atoi(s[m[2]:m[3]])
+		length, _ := strconv.Atoi(s[m[4]:m[5]])
+
+		if !strings.HasPrefix(s[m[1]+length:], "\nendstream\n") {
+			t.Errorf("invalid length of stream %d", id)
+			continue
+		}
+
+		r, err := zlib.NewReader(bytes.NewReader(data[m[1] : m[1]+length]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streams[id], dicts[id] = content, s[m[6]:m[7]]
+	}
+
+	return streams, dicts
+}
+
+func TestPdfDocument_Write(t *testing.T) {
+	font, err := parsePdfFont(writeSfnt(testFontTables()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := newPdfDocument(font)
+	doc.text(10, 20, 12, "AЙ", false)
+	doc.addPage()
+	doc.verticalText(10, 20, 12, "И", true)
+
+	var buf bytes.Buffer
+	if err := doc.write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	streams, dicts := readTestPdf(t, buf.Bytes())
+
+	out := buf.String()
+	if !strings.Contains(out, "/Count 2") {
+		t.Error("expected two pages")
+	}
+
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+ScheduleFont `).MatchString(out) {
+		t.Error("expected font to be named as subset")
+	}
+
+	var program, toUnicode []byte
+	for id, dict := range dicts {
+		switch {
+		case strings.Contains(dict, "/Length1"):
+			program = streams[id]
+			if !strings.Contains(dict, "/Length1 "+strconv.Itoa(len(program))+" ") {
+				t.Errorf("expected Length1 of font program, got: %s", dict)
+			}
+		case strings.Contains(string(streams[id]), "beginbfchar"):
+			toUnicode = streams[id]
+		}
+	}
+
+	// unused glyph is not embedded, loca of subset has long format
+	loca := readTestSfnt(program)["loca"]
+	if start, end := binary.BigEndian.Uint32(loca[testGlyphUnused*4:]), binary.BigEndian.Uint32(loca[testGlyphUnused*4+4:]); start != end {
+		t.Errorf("expected empty outline of unused glyph, got %d bytes", end-start)
+	}
+
+	for _, want := range []string{"3 beginbfchar", "<0001> <0041>", "<0002> <0418>", "<0003> <0419>"} {
+		if !strings.Contains(string(toUnicode), want) {
+			t.Errorf("expected ToUnicode CMap to contain %q", want)
+		}
+	}
+
+	// text is drawn with glyph ids
+	pages := 0
+	for _, content := range streams {
+		switch {
+		case strings.Contains(string(content), "<00010003> Tj"):
+			pages++
+		case strings.Contains(string(content), "2 Tr") && strings.Contains(string(content), "<0002> Tj"):
+			pages++
+		}
+	}
+
+	if pages != 2 {
+		t.Errorf("expected text on two pages, got: %d", pages)
+	}
+}
+
+func TestPdfDocument_WrapText(t *testing.T) {
+	font, err := parsePdfFont(writeSfnt(testFontTables()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := newPdfDocument(font)
+
+	// each glyph is 6 points wide with size of 10 points
+	cases := map[string]struct {
+		text  string
+		width float64
+		want  []string
+	}{
+		"fitting line":   {text: "AA AA", width: 30, want: []string{"AA AA"}},
+		"wrapped words":  {text: "AA AA AA", width: 30, want: []string{"AA AA", "AA"}},
+		"explicit break": {text: "AA\nAA", width: 100, want: []string{"AA", "AA"}},
+		"long word":      {text: "AAAAAAA", width: 25, want: []string{"AAAA", "AAA"}},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			got := doc.wrapText(c.text, 10, c.width)
+			if strings.Join(got, "|") != strings.Join(c.want, "|") {
+				t.Errorf("expected lines %q, got: %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestPdfNum(t *testing.T) {
+	cases := map[float64]string{0: "0", 1.5: "1.5", 2.25: "2.25", 3.333: "3.33", 10: "10", -0.4: "-0.4"}
+
+	for f, want := range cases {
+		if got := pdfNum(f); got != want {
+			t.Errorf("format %v: expected %s, got %s", f, want, got)
+		}
+	}
+}
+
+func TestPdfExporter_Export(t *testing.T) {
+	e := newTestExport(t)
+
+	path := filepath.Join(t.TempDir(), "font.ttf")
+	if err := os.WriteFile(path, writeSfnt(testFontTables()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := e.factory(t, PdfFontPath(path)).ByFormat("pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n, schedule := range map[string]*schedules.Schedule{"cycled": e.schedule, "calendar": e.calendar(t)} {
+		t.Run(n, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exp.Export(context.Background(), schedule, &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			streams, _ := readTestPdf(t, buf.Bytes())
+			if len(streams) < 3 {
+				t.Errorf("expected font and page streams, got: %d", len(streams))
+			}
+		})
+	}
+}
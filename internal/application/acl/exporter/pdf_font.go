@@ -0,0 +1,470 @@
+package exporter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"slices"
+)
+
+// pdfFont TrueType font embedded into pdf documents. Only tables needed for text layout and subsetting
+// are parsed
+type pdfFont struct {
+	tables     map[string][]byte
+	longLoca   bool
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []uint16
+	glyphs     map[rune]uint16
+}
+
+// loadPdfFont reads TrueType font file. Font must have unicode cmap, fonts with CFF outlines are not supported
+func loadPdfFont(path string) (*pdfFont, error) {
+	if path == "" {
+		return nil, errors.New("pdf font is not configured")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pdf font error: %w", err)
+	}
+
+	font, err := parsePdfFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse pdf font %s error: %w", path, err)
+	}
+
+	return font, nil
+}
+
+var errInvalidFont = errors.New("invalid truetype font")
+
+func parsePdfFont(data []byte) (*pdfFont, error) {
+	if len(data) < 12 {
+		return nil, errInvalidFont
+	}
+
+	if v := binary.BigEndian.Uint32(data); v != 0x00010000 && v != 0x74727565 {
+		return nil, errors.New("only truetype outlines are supported")
+	}
+
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := range numTables {
+		rec := 12 + i*16
+		if rec+16 > len(data) {
+			return nil, errInvalidFont
+		}
+
+		offset := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, errInvalidFont
+		}
+
+		tables[string(data[rec:rec+4])] = data[offset : offset+length]
+	}
+
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errInvalidFont
+	}
+
+	font := pdfFont{
+		tables:     tables,
+		longLoca:   binary.BigEndian.Uint16(head[50:]) != 0,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		ascent:     int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent:    int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+	}
+
+	if font.unitsPerEm == 0 {
+		return nil, errInvalidFont
+	}
+
+	for i := range font.bbox {
+		font.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+i*2:])))
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || numMetrics > numGlyphs || len(hmtx) < numMetrics*4 {
+		return nil, errInvalidFont
+	}
+
+	// glyphs after last metric share its advance
+	font.advances = make([]uint16, numGlyphs)
+	for i := range font.advances {
+		font.advances[i] = binary.BigEndian.Uint16(hmtx[min(i, numMetrics-1)*4:])
+	}
+
+	locaEntry := 2
+	if font.longLoca {
+		locaEntry = 4
+	}
+
+	if len(tables["loca"]) < (numGlyphs+1)*locaEntry {
+		return nil, errInvalidFont
+	}
+
+	glyphs, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+
+	font.glyphs = glyphs
+
+	return &font, nil
+}
+
+// parseCmap reads unicode subtable of cmap table, full repertoire subtable is preferred over BMP one
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errInvalidFont
+	}
+
+	var bmp, full int
+	numSubtables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := range numSubtables {
+		rec := 4 + i*8
+		if rec+8 > len(cmap) {
+			return nil, errInvalidFont
+		}
+
+		platform := binary.BigEndian.Uint16(cmap[rec:])
+		encoding := binary.BigEndian.Uint16(cmap[rec+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+
+		switch {
+		case platform == 3 && encoding == 10, platform == 0 && encoding >= 4:
+			full = offset
+		case platform == 3 && encoding == 1, platform == 0:
+			bmp = offset
+		}
+	}
+
+	for _, offset := range []int{full, bmp} {
+		if offset == 0 || offset+4 > len(cmap) {
+			continue
+		}
+
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			return parseCmapFormat4(cmap[offset:])
+		case 12:
+			return parseCmapFormat12(cmap[offset:])
+		}
+	}
+
+	return nil, errors.New("unicode cmap not found")
+}
+
+func parseCmapFormat4(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 14 {
+		return nil, errInvalidFont
+	}
+
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + segCount*2 + 2
+	deltas := startCodes + segCount*2
+	rangeOffsets := deltas + segCount*2
+	if rangeOffsets+segCount*2 > len(sub) {
+		return nil, errInvalidFont
+	}
+
+	glyphs := make(map[rune]uint16)
+	for seg := range segCount {
+		end := int(binary.BigEndian.Uint16(sub[endCodes+seg*2:]))
+		start := int(binary.BigEndian.Uint16(sub[startCodes+seg*2:]))
+		delta := binary.BigEndian.Uint16(sub[deltas+seg*2:])
+		rangeOffsetPos := rangeOffsets + seg*2
+		rangeOffset := int(binary.BigEndian.Uint16(sub[rangeOffsetPos:]))
+
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var glyph uint16
+
+			if rangeOffset == 0 {
+				glyph = uint16(c) + delta
+			} else {
+				pos := rangeOffsetPos + rangeOffset + (c-start)*2
+				if pos+2 > len(sub) {
+					return nil, errInvalidFont
+				}
+
+				if glyph = binary.BigEndian.Uint16(sub[pos:]); glyph != 0 {
+					glyph += delta
+				}
+			}
+
+			if glyph != 0 {
+				glyphs[rune(c)] = glyph
+			}
+		}
+	}
+
+	return glyphs, nil
+}
+
+func parseCmapFormat12(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 16 {
+		return nil, errInvalidFont
+	}
+
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	if 16+numGroups*12 > len(sub) {
+		return nil, errInvalidFont
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := range numGroups {
+		group := sub[16+i*12:]
+		start := binary.BigEndian.Uint32(group)
+		end := binary.BigEndian.Uint32(group[4:])
+		glyph := binary.BigEndian.Uint32(group[8:])
+
+		// planes above BMP and supplementary are not used in schedules
+		for c := start; c <= end && c <= 0x2FFFF; c++ {
+			glyphs[rune(c)] = uint16(glyph + c - start)
+		}
+	}
+
+	return glyphs, nil
+}
+
+// glyph returns glyph of rune, missing runes are drawn as glyph zero
+func (f *pdfFont) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// advance returns advance width of glyph in thousandths of em
+func (f *pdfFont) advance(glyph uint16) float64 {
+	if int(glyph) >= len(f.advances) {
+		return 0
+	}
+
+	return float64(f.advances[glyph]) * 1000 / float64(f.unitsPerEm)
+}
+
+// scale converts font units into thousandths of em
+func (f *pdfFont) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// glyphData returns outline of glyph from glyf table, empty glyphs have no outline
+func (f *pdfFont) glyphData(glyph uint16) ([]byte, error) {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+
+	var start, end int
+	if f.longLoca {
+		start = int(binary.BigEndian.Uint32(loca[int(glyph)*4:]))
+		end = int(binary.BigEndian.Uint32(loca[int(glyph)*4+4:]))
+	} else {
+		start = int(binary.BigEndian.Uint16(loca[int(glyph)*2:])) * 2
+		end = int(binary.BigEndian.Uint16(loca[int(glyph)*2+2:])) * 2
+	}
+
+	if start > end || end > len(glyf) {
+		return nil, errInvalidFont
+	}
+
+	return glyf[start:end], nil
+}
+
+// flags of composite glyph components
+const (
+	glyfArgsAreWords   = 0x0001
+	glyfHaveScale      = 0x0008
+	glyfMoreComponents = 0x0020
+	glyfHaveXYScale    = 0x0040
+	glyfHaveTwoByTwo   = 0x0080
+)
+
+// glyphComponents returns glyphs referenced by composite glyph, simple glyphs have no components
+func glyphComponents(data []byte) ([]uint16, error) {
+	// components follow glyph header of contours number and bounding box
+	const header = 10
+
+	if len(data) < header || int16(binary.BigEndian.Uint16(data)) >= 0 {
+		return nil, nil
+	}
+
+	var components []uint16
+	for pos := header; ; {
+		if pos+4 > len(data) {
+			return nil, errInvalidFont
+		}
+
+		flags := binary.BigEndian.Uint16(data[pos:])
+		components = append(components, binary.BigEndian.Uint16(data[pos+2:]))
+
+		pos += 4
+		if flags&glyfArgsAreWords != 0 {
+			pos += 4
+		} else {
+			pos += 2
+		}
+
+		switch {
+		case flags&glyfHaveScale != 0:
+			pos += 2
+		case flags&glyfHaveXYScale != 0:
+			pos += 4
+		case flags&glyfHaveTwoByTwo != 0:
+			pos += 8
+		}
+
+		if flags&glyfMoreComponents == 0 {
+			return components, nil
+		}
+	}
+}
+
+// subset returns font program with outlines of used glyphs and glyphs they are composed of. Glyph ids are
+// kept, so text drawn with ids of full font is rendered by subset as is. Tables not needed by pdf readers,
+// e.g. cmap and names, are dropped
+func (f *pdfFont) subset(used []uint16) ([]byte, error) {
+	keep := make(map[uint16]bool, len(used)+1)
+
+	// missing glyph is always kept
+	queue := append([]uint16{0}, used...)
+	for len(queue) > 0 {
+		glyph := queue[0]
+		queue = queue[1:]
+
+		if keep[glyph] || int(glyph) >= len(f.advances) {
+			continue
+		}
+
+		keep[glyph] = true
+
+		data, err := f.glyphData(glyph)
+		if err != nil {
+			return nil, err
+		}
+
+		components, err := glyphComponents(data)
+		if err != nil {
+			return nil, err
+		}
+
+		queue = append(queue, components...)
+	}
+
+	numGlyphs := len(f.advances)
+	loca := make([]byte, (numGlyphs+1)*4)
+	var glyf []byte
+	for glyph := range numGlyphs {
+		binary.BigEndian.PutUint32(loca[glyph*4:], uint32(len(glyf)))
+
+		if !keep[uint16(glyph)] {
+			continue
+		}
+
+		data, err := f.glyphData(uint16(glyph))
+		if err != nil {
+			return nil, err
+		}
+
+		glyf = append(glyf, data...)
+		// outlines are aligned to four bytes
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(loca[numGlyphs*4:], uint32(len(glyf)))
+
+	head := append([]byte{}, f.tables["head"]...)
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": f.tables["hhea"],
+		"hmtx": f.tables["hmtx"],
+		"maxp": f.tables["maxp"],
+		"loca": loca,
+		"glyf": glyf,
+	}
+
+	// hinting programs are referenced by outlines
+	for _, tag := range []string{"cvt ", "fpgm", "prep"} {
+		if t, ok := f.tables[tag]; ok {
+			tables[tag] = t
+		}
+	}
+
+	return writeSfnt(tables), nil
+}
+
+// writeSfnt assembles TrueType font file of tables, checksum adjustment of head table is updated
+func writeSfnt(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+
+	// search parameters of table directory
+	entrySelector := bits.Len(uint(len(tags))) - 1
+	searchRange := (1 << entrySelector) * 16
+
+	header := make([]byte, 12+len(tags)*16)
+	binary.BigEndian.PutUint32(header, 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(len(tags)))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(len(tags)*16-searchRange))
+
+	var body []byte
+	headOffset := -1
+	for i, tag := range tags {
+		t := tables[tag]
+		if tag == "head" {
+			t = append([]byte{}, t...)
+			binary.BigEndian.PutUint32(t[8:], 0)
+			headOffset = len(header) + len(body)
+		}
+
+		rec := header[12+i*16:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], sfntChecksum(t))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(header)+len(body)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t)))
+
+		body = append(body, t...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+
+	data := append(header, body...)
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(data[headOffset+8:], 0xB1B0AFBA-sfntChecksum(data))
+	}
+
+	return data
+}
+
+// sfntChecksum sums data as big endian words, tail is padded with zeros
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+
+	return sum
+}
@@ -0,0 +1,291 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// test font glyphs: missing glyph, "A", "И", composite "Й" of "И" and breve, breve and unused glyph
+const (
+	testGlyphA      = 1
+	testGlyphI      = 2
+	testGlyphShortI = 3
+	testGlyphBreve  = 4
+	testGlyphUnused = 5
+	testNumGlyphs   = 6
+)
+
+// testFontTables returns tables of TrueType font with glyphs described above, loca has short format
+func testFontTables() map[string][]byte {
+	be := binary.BigEndian
+
+	head := make([]byte, 54)
+	be.PutUint32(head, 0x00010000)
+	be.PutUint32(head[12:], 0x5F0F3CF5)
+	be.PutUint16(head[18:], 2048)
+	for i, v := range []int16{-100, -400, 1800, 1900} {
+		be.PutUint16(head[36+i*2:], uint16(v))
+	}
+
+	hhea := make([]byte, 36)
+	be.PutUint32(hhea, 0x00010000)
+	be.PutUint16(hhea[4:], 1638)
+	be.PutUint16(hhea[6:], uint16(0xFFFF-410+1))
+	// glyphs after "И" share its advance
+	be.PutUint16(hhea[34:], 3)
+
+	maxp := make([]byte, 6)
+	be.PutUint32(maxp, 0x00005000)
+	be.PutUint16(maxp[4:], testNumGlyphs)
+
+	hmtx := make([]byte, 3*4+(testNumGlyphs-3)*2)
+	for i, advance := range []uint16{1024, 1229, 1434} {
+		be.PutUint16(hmtx[i*4:], advance)
+	}
+
+	simple := func(marker byte) []byte {
+		data := make([]byte, 14)
+		be.PutUint16(data, 1)
+		data[10], data[11], data[12], data[13] = marker, marker, marker, marker
+		return data
+	}
+
+	// "Й" is composed of "И" with word arguments and breve with byte arguments and scale
+	composite := make([]byte, 10, 24)
+	be.PutUint16(composite, 0xFFFF)
+	composite = be.AppendUint16(composite, glyfArgsAreWords|glyfMoreComponents)
+	composite = be.AppendUint16(composite, testGlyphI)
+	composite = append(composite, 0, 0, 0, 0)
+	composite = be.AppendUint16(composite, glyfHaveScale)
+	composite = be.AppendUint16(composite, testGlyphBreve)
+	composite = append(composite, 0, 0, 0x40, 0)
+
+	outlines := [][]byte{simple(0xA0), simple(0xA1), simple(0xA2), composite, simple(0xA4), simple(0xA5)}
+
+	var glyf []byte
+	loca := make([]byte, 0, (testNumGlyphs+1)*2)
+	for _, o := range outlines {
+		loca = be.AppendUint16(loca, uint16(len(glyf)/2))
+		glyf = append(glyf, o...)
+	}
+	loca = be.AppendUint16(loca, uint16(len(glyf)/2))
+
+	return map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"maxp": maxp,
+		"hmtx": hmtx,
+		"cmap": testCmap(map[rune]uint16{'A': testGlyphA, 'И': testGlyphI, 'Й': testGlyphShortI}),
+		"loca": loca,
+		"glyf": glyf,
+		"cvt ": {0, 1, 0, 2},
+		"name": {0, 0, 0, 0},
+	}
+}
+
+// testCmap returns cmap table with format 4 windows unicode subtable of single character segments
+func testCmap(glyphs map[rune]uint16) []byte {
+	be := binary.BigEndian
+
+	// segments are sorted, last one is required 0xFFFF segment
+	codes := slices.Sorted(maps.Keys(glyphs))
+
+	segCount := len(codes) + 1
+
+	sub := be.AppendUint16(nil, 4)
+	sub = be.AppendUint16(sub, uint16(16+segCount*8))
+	sub = be.AppendUint16(sub, 0)
+	sub = be.AppendUint16(sub, uint16(segCount*2))
+	sub = append(sub, 0, 0, 0, 0, 0, 0)
+	for _, c := range codes {
+		sub = be.AppendUint16(sub, uint16(c))
+	}
+	sub = be.AppendUint16(sub, 0xFFFF)
+	sub = be.AppendUint16(sub, 0)
+	for _, c := range codes {
+		sub = be.AppendUint16(sub, uint16(c))
+	}
+	sub = be.AppendUint16(sub, 0xFFFF)
+	for _, c := range codes {
+		sub = be.AppendUint16(sub, glyphs[c]-uint16(c))
+	}
+	sub = be.AppendUint16(sub, 1)
+	for range segCount {
+		sub = be.AppendUint16(sub, 0)
+	}
+
+	cmap := be.AppendUint16(nil, 0)
+	cmap = be.AppendUint16(cmap, 1)
+	cmap = be.AppendUint16(cmap, 3)
+	cmap = be.AppendUint16(cmap, 1)
+	cmap = be.AppendUint32(cmap, 12)
+
+	return append(cmap, sub...)
+}
+
+func TestParsePdfFont(t *testing.T) {
+	font, err := parsePdfFont(writeSfnt(testFontTables()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if font.unitsPerEm != 2048 || font.ascent != 1638 || font.descent != -410 || font.bbox != [4]int{-100, -400, 1800, 1900} {
+		t.Errorf("unexpected metrics: %+v", font)
+	}
+
+	glyphs := map[rune]uint16{'A': testGlyphA, 'И': testGlyphI, 'Й': testGlyphShortI, 'Б': 0}
+	for r, want := range glyphs {
+		if got := font.glyph(r); got != want {
+			t.Errorf("glyph of %q: expected %d, got %d", r, want, got)
+		}
+	}
+
+	advances := map[uint16]float64{0: 500, testGlyphA: 600.0976, testGlyphUnused: 700.1953, testNumGlyphs: 0}
+	for glyph, want := range advances {
+		if got := font.advance(glyph); got < want-0.001 || got > want+0.001 {
+			t.Errorf("advance of glyph %d: expected %v, got %v", glyph, want, got)
+		}
+	}
+}
+
+func TestParsePdfFont_Invalid(t *testing.T) {
+	without := func(tag string) []byte {
+		tables := testFontTables()
+		delete(tables, tag)
+		return writeSfnt(tables)
+	}
+
+	cff := writeSfnt(testFontTables())
+	copy(cff, "OTTO")
+
+	cases := map[string][]byte{
+		"empty":            nil,
+		"truncated":        writeSfnt(testFontTables())[:40],
+		"cff outlines":     cff,
+		"missing cmap":     without("cmap"),
+		"missing outlines": without("glyf"),
+	}
+
+	for n, data := range cases {
+		t.Run(n, func(t *testing.T) {
+			if _, err := parsePdfFont(data); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseCmapFormat12(t *testing.T) {
+	be := binary.BigEndian
+
+	sub := be.AppendUint16(nil, 12)
+	sub = append(sub, 0, 0)
+	sub = be.AppendUint32(sub, 40)
+	sub = be.AppendUint32(sub, 0)
+	sub = be.AppendUint32(sub, 2)
+	for _, group := range [][3]uint32{{0x410, 0x42F, 10}, {0x1F600, 0x1F600, 100}} {
+		for _, v := range group {
+			sub = be.AppendUint32(sub, v)
+		}
+	}
+
+	glyphs, err := parseCmapFormat12(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[rune]uint16{'А': 10, 'Б': 11, 'Я': 41, 0x1F600: 100}
+	for r, glyph := range want {
+		if glyphs[r] != glyph {
+			t.Errorf("glyph of %q: expected %d, got %d", r, glyph, glyphs[r])
+		}
+	}
+
+	if len(glyphs) != 33 {
+		t.Errorf("expected 33 glyphs, got: %d", len(glyphs))
+	}
+
+	if _, err := parseCmapFormat12(sub[:30]); err == nil {
+		t.Error("expected error on truncated subtable, got nil")
+	}
+}
+
+func TestPdfFont_Subset(t *testing.T) {
+	font, err := parsePdfFont(writeSfnt(testFontTables()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := font.subset([]uint16{testGlyphShortI})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sum := sfntChecksum(data); sum != 0xB1B0AFBA {
+		t.Errorf("invalid checksum adjustment, font checksum: %X", sum)
+	}
+
+	tables := readTestSfnt(data)
+	for _, tag := range []string{"cmap", "name"} {
+		if _, ok := tables[tag]; ok {
+			t.Errorf("expected %s table to be dropped", tag)
+		}
+	}
+
+	// pdf readers map glyphs by ids, cmap is needed only to parse subset here
+	tables["cmap"] = testFontTables()["cmap"]
+
+	subset, err := parsePdfFont(writeSfnt(tables))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(subset.tables["cvt "], font.tables["cvt "]) {
+		t.Error("expected hinting tables to be kept")
+	}
+
+	if !subset.longLoca {
+		t.Error("expected long loca format")
+	}
+
+	// composite glyph is kept with its components
+	kept := []uint16{0, testGlyphI, testGlyphShortI, testGlyphBreve}
+	for glyph := range uint16(testNumGlyphs) {
+		want, err := font.glyphData(glyph)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := subset.glyphData(glyph)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// outlines are padded to four bytes
+		got = got[:min(len(got), len(want))]
+
+		if slices.Contains(kept, glyph) {
+			if !bytes.Equal(got, want) {
+				t.Errorf("expected outline of glyph %d to be kept, got: %v", glyph, got)
+			}
+		} else if len(got) != 0 {
+			t.Errorf("expected outline of glyph %d to be dropped, got: %v", glyph, got)
+		}
+	}
+}
+
+// readTestSfnt returns tables of font file
+func readTestSfnt(data []byte) map[string][]byte {
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := range numTables {
+		rec := data[12+i*16:]
+		offset := binary.BigEndian.Uint32(rec[8:])
+		tables[string(rec[:4])] = data[offset : offset+binary.BigEndian.Uint32(rec[12:])]
+	}
+
+	return tables
+}
@@ -0,0 +1,123 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"schedule-generator/internal/domain/departments"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// exportRefs teachers and departments referenced by exported items. References are loaded in batches before items
+// are written and kept for the whole export, so bulk export of several schedules loads each teacher once
+type exportRefs struct {
+	repo        ExporterRepository
+	teachers    map[uuid.UUID]teachers.Teacher
+	departments map[uuid.UUID]departments.Department
+}
+
+func newExportRefs(repo ExporterRepository) *exportRefs {
+	return &exportRefs{
+		repo:        repo,
+		teachers:    make(map[uuid.UUID]teachers.Teacher),
+		departments: make(map[uuid.UUID]departments.Department),
+	}
+}
+
+// load loads teachers of items and departments of teachers which are not loaded yet
+func (r *exportRefs) load(ctx context.Context, items []schedules.ScheduleItem) error {
+	var teacherIDs uuid.UUIDs
+	seen := make(map[uuid.UUID]struct{})
+
+	for _, item := range items {
+		for _, id := range item.TeacherIDs() {
+			if _, ok := r.teachers[id]; ok {
+				continue
+			}
+
+			if _, ok := seen[id]; ok {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			teacherIDs = append(teacherIDs, id)
+		}
+	}
+
+	if len(teacherIDs) == 0 {
+		return nil
+	}
+
+	loaded, err := r.repo.MapTeacherByIDs(ctx, teacherIDs)
+	if err != nil {
+		return fmt.Errorf("map teachers error: %w", err)
+	}
+
+	var departmentIDs uuid.UUIDs
+	for id, teacher := range loaded {
+		r.teachers[id] = teacher
+
+		if _, ok := r.departments[teacher.DepartmentID]; ok || teacher.DepartmentID == uuid.Nil {
+			continue
+		}
+
+		if slices.Contains(departmentIDs, teacher.DepartmentID) {
+			continue
+		}
+
+		departmentIDs = append(departmentIDs, teacher.DepartmentID)
+	}
+
+	if len(departmentIDs) == 0 {
+		return nil
+	}
+
+	deps, err := r.repo.MapDepartmentByIDs(ctx, departmentIDs)
+	if err != nil {
+		return fmt.Errorf("map departments error: %w", err)
+	}
+
+	for id, department := range deps {
+		r.departments[id] = department
+	}
+
+	return nil
+}
+
+// teacher returns loaded teacher
+func (r *exportRefs) teacher(id uuid.UUID) (teachers.Teacher, error) {
+	teacher, ok := r.teachers[id]
+	if !ok {
+		return teachers.Teacher{}, fmt.Errorf("teacher %s not found", id)
+	}
+
+	return teacher, nil
+}
+
+// department returns loaded department of teacher
+func (r *exportRefs) department(teacher teachers.Teacher) (departments.Department, error) {
+	department, ok := r.departments[teacher.DepartmentID]
+	if !ok {
+		return departments.Department{}, fmt.Errorf("department %s of teacher %s not found", teacher.DepartmentID, teacher.ID)
+	}
+
+	return department, nil
+}
+
+// teacherNames returns names of item teachers, main teachers go first
+func (r *exportRefs) teacherNames(item schedules.ScheduleItem) ([]string, error) {
+	names := make([]string, 0, len(item.Teachers))
+	for _, t := range item.Teachers {
+		teacher, err := r.teacher(t.TeacherID)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, teacher.Name)
+	}
+
+	return names, nil
+}
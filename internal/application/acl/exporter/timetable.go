@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"schedule-generator/internal/domain/schedules"
+	"time"
+)
+
+// TimetableExporter exports lessons of teacher or cabinet collected from schedules of several groups
+type TimetableExporter interface {
+	ExportTimetable(ctx context.Context, timetable *Timetable, dst io.Writer) error
+}
+
+type Timetable struct {
+	// Title subject of timetable, e.g. teacher name or cabinet address
+	Title string
+	// Details lines printed under title, e.g. period of timetable
+	Details []string
+	Days    []TimetableDay
+}
+
+// TimetableDay lessons of weekday of cycled schedules or of date when Date is set
+type TimetableDay struct {
+	Weekday time.Weekday
+	Date    *time.Time
+	Lessons []TimetableLesson
+}
+
+type TimetableLesson struct {
+	schedules.ScheduleItem
+	EduGroupNumber string
+	TeacherNames   []string
+	// CycleLength of cycled schedule of item, zero for dated items
+	CycleLength int
+}
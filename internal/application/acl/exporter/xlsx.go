@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"schedule-generator/internal/domain/schedules"
+	"strconv"
+)
+
+const (
+	// xlsxFirstGridRow row of grid header, title of sheet goes above
+	xlsxFirstGridRow = 2
+	// xlsxFirstLessonCol first column with lessons, day, lesson number, time and week labels go before
+	xlsxFirstLessonCol = 4
+)
+
+// xlsxExporter draws schedule grid with lessons of whole group and lessons of every week as merged cells
+type xlsxExporter struct {
+	repo   ExporterRepository
+	refs   *exportRefs
+	bells  schedules.BellSchedule
+	logger *slog.Logger
+}
+
+func (exp *xlsxExporter) Export(ctx context.Context, schedule *schedules.Schedule, dst io.Writer) error {
+	return exp.ExportWorkbook(ctx, []*schedules.Schedule{schedule}, dst)
+}
+
+// ExportWorkbook writes workbook with sheet per schedule
+func (exp *xlsxExporter) ExportWorkbook(ctx context.Context, list []*schedules.Schedule, dst io.Writer) error {
+	wb := newXlsxWorkbook()
+
+	var items []schedules.ScheduleItem
+	for _, schedule := range list {
+		items = append(items, schedule.ListItem()...)
+	}
+
+	if err := exp.refs.load(ctx, items); err != nil {
+		exp.logger.Error("Load references error", "error", err)
+		return err
+	}
+
+	for _, schedule := range list {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		logger := exp.logger.With("schedule_id", schedule.ID)
+
+		group, err := exp.repo.GetEduGroup(ctx, schedule.EduGroupID)
+		if err != nil {
+			logger.Error("Get edu group error", "error", err)
+			return err
+		}
+
+		grid, err := newScheduleGrid(schedule, len(exp.bells), func(item schedules.ScheduleItem) ([]string, error) {
+			return formGridItemLines(exp.refs, item)
+		})
+		if err != nil {
+			logger.Error("Build schedule grid error", "error", err)
+			return err
+		}
+
+		sheet := wb.addSheet(group.Number)
+		sheet.set(0, 0, fmt.Sprintf("Расписание занятий группы %s, %d семестр", group.Number, schedule.Semester), xlsxStyleTitle)
+		exp.drawGrid(sheet, grid)
+	}
+
+	return wb.write(dst)
+}
+
+func (exp *xlsxExporter) drawGrid(sheet *xlsxSheet, grid *scheduleGrid) {
+	headers := []string{"День", "Пара", "Время", "Неделя"}
+	for i, h := range headers {
+		sheet.set(xlsxFirstGridRow, i, h, xlsxStyleHeader)
+	}
+
+	for sg := range grid.Subgroups {
+		header := "Занятия"
+		if grid.Subgroups > 1 {
+			header = "Подгруппа " + strconv.Itoa(sg+1)
+		}
+
+		sheet.set(xlsxFirstGridRow, xlsxFirstLessonCol+sg, header, xlsxStyleHeader)
+	}
+
+	sheet.colWidths[0] = 5
+	sheet.colWidths[1] = 6
+	sheet.colWidths[2] = 13
+	sheet.colWidths[3] = 8
+	for sg := range grid.Subgroups {
+		sheet.colWidths[xlsxFirstLessonCol+sg] = max(60/float64(grid.Subgroups), 30)
+	}
+
+	rowHeight := 60.0
+	if grid.WeekRows > 1 {
+		rowHeight = 40
+	}
+
+	dayRows := grid.DayRows()
+	row := xlsxFirstGridRow + 1
+
+	for _, day := range grid.Days {
+		for r := row; r < row+dayRows; r++ {
+			sheet.rowHeights[r] = rowHeight
+			sheet.set(r, 0, "", xlsxStyleDay)
+			sheet.set(r, 3, grid.WeekLabel(r-row), xlsxStyleLabel)
+
+			for sg := range grid.Subgroups {
+				sheet.set(r, xlsxFirstLessonCol+sg, "", xlsxStyleLesson)
+			}
+		}
+
+		sheet.set(row, 0, day.Label, xlsxStyleDay)
+		sheet.merge(xlsxRange{fromRow: row, fromCol: 0, toRow: row + dayRows - 1, toCol: 0})
+
+		for lesson := range grid.Lessons {
+			first := row + lesson*grid.WeekRows
+			last := first + grid.WeekRows - 1
+
+			for r := first; r <= last; r++ {
+				sheet.set(r, 1, "", xlsxStyleLabel)
+				sheet.set(r, 2, "", xlsxStyleLabel)
+			}
+
+			sheet.set(first, 1, strconv.Itoa(lesson+1), xlsxStyleLabel)
+			sheet.set(first, 2, formLessonTime(exp.bells, lesson), xlsxStyleLabel)
+			sheet.merge(xlsxRange{fromRow: first, fromCol: 1, toRow: last, toCol: 1})
+			sheet.merge(xlsxRange{fromRow: first, fromCol: 2, toRow: last, toCol: 2})
+		}
+
+		for _, cell := range day.Cells {
+			r, col := row+cell.Row, xlsxFirstLessonCol+cell.Col
+
+			sheet.set(r, col, cell.Text(), xlsxStyleLesson)
+			sheet.merge(xlsxRange{fromRow: r, fromCol: col, toRow: r + cell.Rows - 1, toCol: col + cell.Cols - 1})
+		}
+
+		row += dayRows
+	}
+}
@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"schedule-generator/internal/domain/schedules"
+)
+
+// readXlsx opens workbook and returns its parts by names, each xml part must be well-formed
+func readXlsx(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("part %s is not valid xml: %v", f.Name, err)
+			}
+		}
+
+		parts[f.Name] = string(content)
+	}
+
+	return parts
+}
+
+func TestXlsxExporter_ExportWorkbook(t *testing.T) {
+	e := newTestExport(t)
+
+	exp, err := e.factory(t).ByFormat("xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workbookExp, ok := exp.(WorkbookExporter)
+	if !ok {
+		t.Fatal("expected xlsx exporter to export workbooks")
+	}
+
+	var buf bytes.Buffer
+	// the same group twice gets two sheets with unique names
+	if err := workbookExp.ExportWorkbook(context.Background(), []*schedules.Schedule{e.schedule, e.schedule}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	parts := readXlsx(t, buf.Bytes())
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("expected workbook part %s", name)
+		}
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal([]byte(parts["xl/workbook.xml"]), &workbook); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, s := range workbook.Sheets {
+		names = append(names, s.Name)
+	}
+
+	if !slices.Equal(names, []string{"101", "101 (2)"}) {
+		t.Errorf("expected sheets [101 101 (2)], got: %v", names)
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref   string `xml:"r,attr"`
+				Value string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+		Merges []struct {
+			Ref string `xml:"ref,attr"`
+		} `xml:"mergeCells>mergeCell"`
+	}
+	if err := xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet); err != nil {
+		t.Fatal(err)
+	}
+
+	var values []string
+	for _, row := range sheet.Rows {
+		for _, c := range row.Cells {
+			values = append(values, c.Value)
+		}
+	}
+
+	text := strings.Join(values, "\n")
+	for _, want := range []string{"Расписание занятий группы 101, 1 семестр", "Математика; анализ, часть 1", "Программирование", e.teacher.Name} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected sheet to contain %q", want)
+		}
+	}
+
+	if len(sheet.Merges) == 0 {
+		t.Error("expected merged cells of days and lessons")
+	}
+}
+
+func TestXlsxWorkbook_AddSheet(t *testing.T) {
+	wb := newXlsxWorkbook()
+
+	cases := []struct {
+		name, want string
+	}{
+		{"101", "101"},
+		{"101", "101 (2)"},
+		{"a/b:c", "a_b_c"},
+		{"''", "Sheet"},
+		{strings.Repeat("я", 40), strings.Repeat("я", xlsxMaxSheetName)},
+		{strings.Repeat("я", 40), strings.Repeat("я", xlsxMaxSheetName-4) + " (2)"},
+	}
+
+	for _, c := range cases {
+		if got := wb.addSheet(c.name).name; got != c.want {
+			t.Errorf("sheet name for %q: expected %q, got %q", c.name, c.want, got)
+		}
+	}
+}
+
+func TestXlsxColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+
+	for col, want := range cases {
+		if got := xlsxColumnName(col); got != want {
+			t.Errorf("column %d: expected %s, got %s", col, want, got)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// xlsxMaxSheetName max length of worksheet name allowed by spreadsheet applications
+const xlsxMaxSheetName = 31
+
+// xlsxStyle index of cell format in styles part written by writeXlsxStyles
+type xlsxStyle int
+
+const (
+	xlsxStyleDefault xlsxStyle = iota
+	// xlsxStyleTitle bold text without borders
+	xlsxStyleTitle
+	// xlsxStyleHeader bold centered text with borders
+	xlsxStyleHeader
+	// xlsxStyleLabel centered text with borders
+	xlsxStyleLabel
+	// xlsxStyleLesson wrapped centered text with borders
+	xlsxStyleLesson
+	// xlsxStyleDay bold text rotated to be read bottom up, with borders
+	xlsxStyleDay
+)
+
+type xlsxCell struct {
+	value string
+	style xlsxStyle
+}
+
+// xlsxRange rectangle of cells, zero based and inclusive
+type xlsxRange struct {
+	fromRow, fromCol int
+	toRow, toCol     int
+}
+
+// xlsxSheet worksheet kept in memory until workbook is written
+type xlsxSheet struct {
+	name       string
+	cells      map[int]map[int]xlsxCell
+	merges     []xlsxRange
+	colWidths  map[int]float64
+	rowHeights map[int]float64
+}
+
+func newXlsxSheet(name string) *xlsxSheet {
+	return &xlsxSheet{
+		name:       name,
+		cells:      make(map[int]map[int]xlsxCell),
+		colWidths:  make(map[int]float64),
+		rowHeights: make(map[int]float64),
+	}
+}
+
+func (s *xlsxSheet) set(row, col int, value string, style xlsxStyle) {
+	if s.cells[row] == nil {
+		s.cells[row] = make(map[int]xlsxCell)
+	}
+
+	s.cells[row][col] = xlsxCell{value: value, style: style}
+}
+
+// merge merges cells of range, value of top left cell is shown. Cells of range keep their styles, so borders are
+// drawn around the whole merged area
+func (s *xlsxSheet) merge(r xlsxRange) {
+	if r.fromRow == r.toRow && r.fromCol == r.toCol {
+		return
+	}
+
+	s.merges = append(s.merges, r)
+}
+
+// xlsxWorkbook minimal SpreadsheetML writer: inline strings, fixed set of styles and merged cells
+type xlsxWorkbook struct {
+	sheets []*xlsxSheet
+	names  map[string]struct{}
+}
+
+func newXlsxWorkbook() *xlsxWorkbook {
+	return &xlsxWorkbook{names: make(map[string]struct{})}
+}
+
+// addSheet adds worksheet with name made valid and unique within workbook
+func (wb *xlsxWorkbook) addSheet(name string) *xlsxSheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+
+		return r
+	}, strings.Trim(name, "'"))
+
+	if name == "" {
+		name = "Sheet"
+	}
+
+	base := truncateRunes(name, xlsxMaxSheetName)
+	name = base
+	for n := 2; ; n++ {
+		if _, ok := wb.names[strings.ToLower(name)]; !ok {
+			break
+		}
+
+		suffix := " (" + strconv.Itoa(n) + ")"
+		name = truncateRunes(base, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+
+	wb.names[strings.ToLower(name)] = struct{}{}
+
+	sheet := newXlsxSheet(name)
+	wb.sheets = append(wb.sheets, sheet)
+
+	return sheet
+}
+
+func (wb *xlsxWorkbook) write(dst io.Writer) error {
+	if len(wb.sheets) == 0 {
+		// workbook must contain at least one sheet to be opened
+		wb.addSheet("Sheet")
+	}
+
+	zw := zip.NewWriter(dst)
+
+	parts := []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{"[Content_Types].xml", wb.writeContentTypes},
+		{"_rels/.rels", writeXlsxRootRels},
+		{"xl/workbook.xml", wb.writeWorkbook},
+		{"xl/_rels/workbook.xml.rels", wb.writeWorkbookRels},
+		{"xl/styles.xml", writeXlsxStyles},
+	}
+
+	for i, sheet := range wb.sheets {
+		parts = append(parts, struct {
+			name  string
+			write func(w io.Writer) error
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.write})
+	}
+
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+
+		if err := part.write(w); err != nil {
+			return fmt.Errorf("write %s error: %w", part.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func (wb *xlsxWorkbook) writeContentTypes(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range wb.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeXlsxRootRels(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header+
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`+
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>`+
+		`</Relationships>`)
+	return err
+}
+
+func (wb *xlsxWorkbook) writeWorkbook(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range wb.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (wb *xlsxWorkbook) writeWorkbookRels(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	// styles relationship goes after sheets, so sheet ids match their positions
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.sheets)+1)
+	b.WriteString(`</Relationships>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeXlsxStyles writes cell formats in order of xlsxStyle constants
+func writeXlsxStyles(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header+
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+
+		`<fonts count="2">`+
+		`<font><sz val="10"/><name val="Arial"/></font>`+
+		`<font><b/><sz val="10"/><name val="Arial"/></font>`+
+		`</fonts>`+
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>`+
+		`<borders count="2">`+
+		`<border><left/><right/><top/><bottom/><diagonal/></border>`+
+		`<border><left style="thin"/><right style="thin"/><top style="thin"/><bottom style="thin"/><diagonal/></border>`+
+		`</borders>`+
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`+
+		`<cellXfs count="6">`+
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`+
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>`+
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="1" xfId="0" applyFont="1" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="center" wrapText="1"/></xf>`+
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="center"/></xf>`+
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="center" wrapText="1"/></xf>`+
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="1" xfId="0" applyFont="1" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="center" textRotation="90"/></xf>`+
+		`</cellXfs>`+
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`+
+		`</styleSheet>`)
+	return err
+}
+
+func (s *xlsxSheet) write(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	if len(s.colWidths) > 0 {
+		cols := sortedKeys(s.colWidths)
+
+		b.WriteString(`<cols>`)
+		for _, col := range cols {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, col+1, col+1, formatXlsxFloat(s.colWidths[col]))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for _, row := range sortedKeys(s.cells) {
+		fmt.Fprintf(&b, `<row r="%d"`, row+1)
+		if h, ok := s.rowHeights[row]; ok {
+			fmt.Fprintf(&b, ` ht="%s" customHeight="1"`, formatXlsxFloat(h))
+		}
+		b.WriteString(`>`)
+
+		cells := s.cells[row]
+		for _, col := range sortedKeys(cells) {
+			cell := cells[col]
+			ref := xlsxCellRef(row, col)
+
+			if cell.value == "" {
+				fmt.Fprintf(&b, `<c r="%s" s="%d"/>`, ref, cell.style)
+				continue
+			}
+
+			fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.style, xmlEscape(cell.value))
+		}
+
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	if len(s.merges) > 0 {
+		fmt.Fprintf(&b, `<mergeCells count="%d">`, len(s.merges))
+		for _, m := range s.merges {
+			fmt.Fprintf(&b, `<mergeCell ref="%s:%s"/>`, xlsxCellRef(m.fromRow, m.fromCol), xlsxCellRef(m.toRow, m.toCol))
+		}
+		b.WriteString(`</mergeCells>`)
+	}
+
+	b.WriteString(`</worksheet>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// xlsxCellRef returns A1 style reference of zero based cell position
+func xlsxCellRef(row, col int) string {
+	return xlsxColumnName(col) + strconv.Itoa(row+1)
+}
+
+// xlsxColumnName returns letters of zero based column: A..Z, AA..
+func xlsxColumnName(col int) string {
+	var name []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append(name, byte('A'+(col-1)%26))
+	}
+
+	slices.Reverse(name)
+
+	return string(name)
+}
+
+func formatXlsxFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	// writing to strings.Builder never fails
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n])
+}
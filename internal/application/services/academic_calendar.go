@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	academicyears "schedule-generator/internal/domain/academic_years"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/infrastructure/db"
+
+	"github.com/google/uuid"
+)
+
+type AcademicCalendarServiceRepository interface {
+	GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error)
+	GetAcademicYearByFacultyAndYear(ctx context.Context, facultyID uuid.UUID, year int) (*academicyears.AcademicYear, error)
+}
+
+// AcademicCalendarService resolves semester start dates from faculty academic calendar
+type AcademicCalendarService struct {
+	repo AcademicCalendarServiceRepository
+}
+
+func NewAcademicCalendarService(repo AcademicCalendarServiceRepository) *AcademicCalendarService {
+	return &AcademicCalendarService{
+		repo: repo,
+	}
+}
+
+// GetAcademicYear returns faculty academic year or default one if faculty does not have it configured
+func (s *AcademicCalendarService) GetAcademicYear(ctx context.Context, facultyID uuid.UUID, year int) (academicyears.AcademicYear, error) {
+	academicYear, err := s.repo.GetAcademicYearByFacultyAndYear(ctx, facultyID, year)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return academicyears.DefaultAcademicYear(facultyID, year), nil
+		}
+
+		return academicyears.AcademicYear{}, fmt.Errorf("get academic year error: %w", err)
+	}
+
+	return *academicYear, nil
+}
+
+// GetEducationStartDate start of the first education week of group semester, used as origin for week numbering
+func (s *AcademicCalendarService) GetEducationStartDate(ctx context.Context, group *edugroups.EduGroup, semester int) (time.Time, error) {
+	facultyID, err := s.repo.GetEduGroupFacultyID(ctx, group.ID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get edu group faculty id error: %w", err)
+	}
+
+	academicYear, err := s.GetAcademicYear(ctx, facultyID, group.GetEducationYearBySemester(semester))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return academicYear.SemesterWeeksStart(semester), nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"schedule-generator/internal/common"
+	academicyears "schedule-generator/internal/domain/academic_years"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/infrastructure/db"
+
+	"github.com/google/uuid"
+)
+
+type academicCalendarRepoStub struct {
+	facultyID uuid.UUID
+	years     map[int]*academicyears.AcademicYear
+	err       error
+}
+
+func (r *academicCalendarRepoStub) GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error) {
+	return r.facultyID, nil
+}
+
+func (r *academicCalendarRepoStub) GetAcademicYearByFacultyAndYear(ctx context.Context, facultyID uuid.UUID, year int) (*academicyears.AcademicYear, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if y, ok := r.years[year]; ok {
+		return y, nil
+	}
+
+	return nil, db.ErrorNotFound
+}
+
+func TestAcademicCalendarService_GetEducationStartDate(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, common.DefaultTimezone)
+	}
+
+	facultyID := uuid.New()
+	group := &edugroups.EduGroup{ID: uuid.New(), AdmissionYear: 2025}
+
+	repo := &academicCalendarRepoStub{
+		facultyID: facultyID,
+		years: map[int]*academicyears.AcademicYear{
+			// wednesday starts
+			2026: {FacultyID: facultyID, Year: 2026, FallSemesterStart: date(2026, time.September, 2), SpringSemesterStart: date(2027, time.February, 10)},
+			2027: {FacultyID: facultyID, Year: 2027, FallSemesterStart: date(2027, time.September, 1), SpringSemesterStart: date(2028, time.February, 9), WeeksFromMonday: true},
+		},
+	}
+
+	svc := NewAcademicCalendarService(repo)
+
+	cases := []struct {
+		name     string
+		semester int
+		want     time.Time
+	}{
+		{"default fall semester", 1, date(2025, time.September, 1)},
+		{"default spring semester", 2, date(2026, time.February, 9)},
+		{"configured fall semester", 3, date(2026, time.September, 2)},
+		{"configured spring semester", 4, date(2027, time.February, 10)},
+		{"weeks from monday", 5, date(2027, time.August, 30)},
+		{"weeks from monday spring semester", 6, date(2028, time.February, 7)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := svc.GetEducationStartDate(context.Background(), group, c.semester)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equal(c.want) {
+				t.Errorf("expected %s, got %s", c.want.Format(time.DateOnly), got.Format(time.DateOnly))
+			}
+		})
+	}
+
+	t.Run("repository error", func(t *testing.T) {
+		svc := NewAcademicCalendarService(&academicCalendarRepoStub{facultyID: facultyID, err: errors.New("connection lost")})
+
+		if _, err := svc.GetEducationStartDate(context.Background(), group, 1); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"schedule-generator/internal/domain/cabinets"
 	"schedule-generator/internal/domain/departments"
 	edudirections "schedule-generator/internal/domain/edu_directions"
 	edugroups "schedule-generator/internal/domain/edu_groups"
@@ -91,3 +92,7 @@ func (a *AuthorizationService) HaveAccessToTeacher(ctx context.Context, teacher
 
 	return a.svc.HaveAccessToFaculty(user, facultyID), nil
 }
+
+func (a *AuthorizationService) HaveAccessToCabinet(ctx context.Context, cabinet *cabinets.Cabinet, user *users.User) (bool, error) {
+	return a.svc.HaveAccessToFaculty(user, cabinet.FacultyID), nil
+}
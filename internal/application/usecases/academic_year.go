@@ -0,0 +1,195 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"schedule-generator/internal/application/services"
+	academicyears "schedule-generator/internal/domain/academic_years"
+	"schedule-generator/internal/domain/faculties"
+	"schedule-generator/internal/domain/users"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/pkg/execerror"
+
+	"github.com/google/uuid"
+)
+
+type AcademicYearUsecaseRepo interface {
+	academicyears.Repository
+	faculties.Repository
+}
+
+type AcademicYearUsecase struct {
+	repo    AcademicYearUsecaseRepo
+	authSvc *services.AuthorizationService
+	logger  *slog.Logger
+}
+
+func NewAcademicYearUsecase(authSvc *services.AuthorizationService, repo AcademicYearUsecaseRepo, logger *slog.Logger) *AcademicYearUsecase {
+	return &AcademicYearUsecase{
+		authSvc: authSvc,
+		repo:    repo,
+		logger:  logger,
+	}
+}
+
+type CreateAcademicYearInput struct {
+	FacultyID           uuid.UUID
+	Year                int
+	FallSemesterStart   time.Time
+	SpringSemesterStart time.Time
+	WeeksFromMonday     bool
+}
+
+type CreateAcademicYearOutput = academicyears.AcademicYear
+
+// CreateAcademicYear
+func (uc *AcademicYearUsecase) CreateAcademicYear(ctx context.Context, input CreateAcademicYearInput, user *users.User) (*CreateAcademicYearOutput, error) {
+	logger := uc.logger.With("faculty_id", input.FacultyID, "year", input.Year)
+
+	faculty, err := uc.repo.GetFaculty(ctx, input.FacultyID)
+	if err != nil {
+		logger.Error("Get faculty error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("faculty not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToFaculty(ctx, faculty, user); err != nil {
+		logger.Error("Check access to faculty error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to faculty"))
+	}
+
+	academicYear, err := academicyears.NewAcademicYear(faculty.ID, input.Year, input.FallSemesterStart, input.SpringSemesterStart, input.WeeksFromMonday)
+	if err != nil {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+	}
+
+	err = uc.repo.SaveAcademicYear(ctx, academicYear)
+	if err != nil {
+		logger.Error("Save academic year error", "error", err)
+
+		if errors.Is(err, db.ErrorUniqueViolation) {
+			return nil, execerror.NewExecError(execerror.TypeProcessingConflict, fmt.Errorf("academic year %d for faculty already exists", input.Year))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return academicYear, nil
+}
+
+type ListAcademicYearOutput = []academicyears.AcademicYear
+
+// ListAcademicYear
+func (uc *AcademicYearUsecase) ListAcademicYear(ctx context.Context, user *users.User) (ListAcademicYearOutput, error) {
+	logger := uc.logger
+
+	var list []academicyears.AcademicYear
+	var listErr error
+
+	if uc.authSvc.IsAdmin(user) {
+		list, listErr = uc.repo.ListAcademicYear(ctx)
+	} else {
+		if user.FacultyID == nil {
+			return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user not accociated with any faculty"))
+		}
+
+		list, listErr = uc.repo.ListAcademicYearByFaculty(ctx, *user.FacultyID)
+	}
+
+	if listErr != nil {
+		logger.Error("List academic year error", "error", listErr)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return list, nil
+}
+
+type UpdateAcademicYearInput struct {
+	AcademicYearID      uuid.UUID
+	FallSemesterStart   *time.Time
+	SpringSemesterStart *time.Time
+	WeeksFromMonday     *bool
+}
+
+type UpdateAcademicYearOutput = academicyears.AcademicYear
+
+// UpdateAcademicYear
+func (uc *AcademicYearUsecase) UpdateAcademicYear(ctx context.Context, input UpdateAcademicYearInput, user *users.User) (*UpdateAcademicYearOutput, error) {
+	logger := uc.logger.With("academic_year_id", input.AcademicYearID)
+
+	academicYear, err := uc.getAcademicYear(ctx, logger, input.AcademicYearID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.FallSemesterStart != nil {
+		academicYear.FallSemesterStart = *input.FallSemesterStart
+	}
+
+	if input.SpringSemesterStart != nil {
+		academicYear.SpringSemesterStart = *input.SpringSemesterStart
+	}
+
+	if input.WeeksFromMonday != nil {
+		academicYear.WeeksFromMonday = *input.WeeksFromMonday
+	}
+
+	if err := academicYear.Validate(); err != nil {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+	}
+
+	err = uc.repo.SaveAcademicYear(ctx, academicYear)
+	if err != nil {
+		logger.Error("Save academic year error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return academicYear, nil
+}
+
+// DeleteAcademicYear
+func (uc *AcademicYearUsecase) DeleteAcademicYear(ctx context.Context, academicYearID uuid.UUID, user *users.User) error {
+	logger := uc.logger.With("academic_year_id", academicYearID)
+
+	if _, err := uc.getAcademicYear(ctx, logger, academicYearID, user); err != nil {
+		return err
+	}
+
+	err := uc.repo.DeleteAcademicYear(ctx, academicYearID)
+	if err != nil {
+		logger.Error("Delete academic year error", "error", err)
+		return execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return nil
+}
+
+func (uc *AcademicYearUsecase) getAcademicYear(ctx context.Context, logger *slog.Logger, academicYearID uuid.UUID, user *users.User) (*academicyears.AcademicYear, error) {
+	academicYear, err := uc.repo.GetAcademicYear(ctx, academicYearID)
+	if err != nil {
+		logger.Error("Get academic year error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("academic year not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToFaculty(ctx, &faculties.Faculty{ID: academicYear.FacultyID}, user); err != nil {
+		logger.Error("Check access to faculty error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to academic year"))
+	}
+
+	return academicYear, nil
+}
@@ -49,6 +49,7 @@ type CreateCabinetInput struct {
 	Building                           string
 	Auditorium                         string
 	SuitableForPeoplesWithSpecialNeeds bool
+	Capacity                           int
 	Appointment                        *string
 	Equipment                          *Equipment
 }
@@ -87,11 +88,11 @@ func (uc *CabinetUsecase) CreateCabinet(ctx context.Context, input CreateCabinet
 		equipment = &cabinets.CabinetEquipment{
 			Furniture:         input.Equipment.Furniture,
 			TechnicalMeans:    input.Equipment.TechnicalMeans,
-			ComputerEquipment: input.Equipment.Furniture,
+			ComputerEquipment: input.Equipment.СomputerEquipment,
 		}
 	}
 
-	cabinet, err := cabinets.NewCabinet(faculty.ID, cabinetType, input.Auditorium, input.SuitableForPeoplesWithSpecialNeeds, input.Building, input.Appointment, equipment)
+	cabinet, err := cabinets.NewCabinet(faculty.ID, cabinetType, input.Auditorium, input.SuitableForPeoplesWithSpecialNeeds, input.Building, input.Capacity, input.Appointment, equipment)
 	if err != nil {
 		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
 	}
@@ -196,6 +197,7 @@ type UpdateCabinetInput struct {
 	Building                           *string
 	Auditorium                         *string
 	SuitableForPeoplesWithSpecialNeeds *bool
+	Capacity                           *int
 	Appointment                        *string
 	Equipment                          *Equipment
 }
@@ -257,6 +259,11 @@ func (uc *CabinetUsecase) UpdateCabinet(ctx context.Context, input UpdateCabinet
 	if input.SuitableForPeoplesWithSpecialNeeds != nil {
 		cabinet.SuitableForPeoplesWithSpecialNeeds = *input.SuitableForPeoplesWithSpecialNeeds
 	}
+
+	if input.Capacity != nil {
+		cabinet.Capacity = *input.Capacity
+	}
+
 	if input.Appointment != nil {
 		if len(*input.Appointment) == 0 {
 			cabinet.Appointment = nil
@@ -269,7 +276,7 @@ func (uc *CabinetUsecase) UpdateCabinet(ctx context.Context, input UpdateCabinet
 		cabinet.Equipment = &cabinets.CabinetEquipment{
 			Furniture:         input.Equipment.Furniture,
 			TechnicalMeans:    input.Equipment.TechnicalMeans,
-			ComputerEquipment: input.Equipment.Furniture,
+			ComputerEquipment: input.Equipment.СomputerEquipment,
 		}
 	}
 
@@ -305,6 +312,10 @@ func (uc *CabinetUsecase) DeleteCabinet(ctx context.Context, cabinetID uuid.UUID
 	err := uc.repo.DeleteCabinet(ctx, cabinetID)
 	if err != nil {
 		logger.Error("Delete edu cabinet error", "error", err)
+		if errors.Is(err, db.ErrorAssociationViolation) {
+			return execerror.NewExecError(execerror.TypeProcessingConflict, errors.New("cabinet is used in schedules"))
+		}
+
 		return execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
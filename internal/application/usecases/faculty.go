@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
+
 	"schedule-generator/internal/application/services"
 	"schedule-generator/internal/domain/faculties"
+	"schedule-generator/internal/domain/schedules"
 	"schedule-generator/internal/domain/users"
+	"schedule-generator/internal/infrastructure/db"
 	"schedule-generator/pkg/execerror"
+
+	"github.com/google/uuid"
 )
 
 type FacultyUseCaseRepo interface {
@@ -60,3 +66,49 @@ func (uc *FacultyUsecase) ListFaculty(ctx context.Context, user *users.User) (Li
 
 	return faculties, nil
 }
+
+type UpdateFacultyInput struct {
+	FacultyID   uuid.UUID
+	WorkingDays []time.Weekday
+}
+
+type UpdateFacultyOutput = faculties.Faculty
+
+// UpdateFaculty
+func (uc *FacultyUsecase) UpdateFaculty(ctx context.Context, input UpdateFacultyInput, user *users.User) (*UpdateFacultyOutput, error) {
+	logger := uc.logger.With("faculty_id", input.FacultyID)
+
+	faculty, err := uc.repo.GetFaculty(ctx, input.FacultyID)
+	if err != nil {
+		logger.Error("Get faculty error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("faculty not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToFaculty(ctx, faculty, user); err != nil {
+		logger.Error("Check access to faculty error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to faculty"))
+	}
+
+	if input.WorkingDays != nil {
+		workingWeek, err := schedules.NewWorkingWeek(input.WorkingDays...)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		faculty.WorkingDays = workingWeek.Days()
+	}
+
+	err = uc.repo.SaveFaculty(ctx, faculty)
+	if err != nil {
+		logger.Error("Save faculty error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return faculty, nil
+}
@@ -1,19 +1,23 @@
 package usecases
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 	"time"
 
 	"schedule-generator/internal/application/acl/exporter"
 	"schedule-generator/internal/application/services"
+	"schedule-generator/internal/common"
 	"schedule-generator/internal/domain/cabinets"
 	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/faculties"
 	"schedule-generator/internal/domain/schedules"
 	"schedule-generator/internal/domain/teachers"
 	"schedule-generator/internal/domain/users"
@@ -29,6 +33,10 @@ type ScheduleUsecaseRepo interface {
 	cabinets.Repository
 
 	GetScheduleByEduGroupIDAndSemester(ctx context.Context, eduGroupID uuid.UUID, semester int) (*schedules.Schedule, error)
+	GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error)
+	GetTeacherByExternalID(ctx context.Context, externalID string) (*teachers.Teacher, error)
+	GetCabinetByAddress(ctx context.Context, building, auditorium string) (*cabinets.Cabinet, error)
+	GetFaculty(ctx context.Context, id uuid.UUID) (*faculties.Faculty, error)
 	MapEduGroupsBySchedules(ctx context.Context, scheduleIDs uuid.UUIDs) (map[uuid.UUID]edugroups.EduGroup, error)
 	MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error)
 
@@ -36,41 +44,98 @@ type ScheduleUsecaseRepo interface {
 }
 
 type ScheduleUsecase struct {
-	repo     ScheduleUsecaseRepo
-	authSvc  *services.AuthorizationService
-	exporter exporter.Factory
-	logger   *slog.Logger
+	repo        ScheduleUsecaseRepo
+	authSvc     *services.AuthorizationService
+	calendarSvc *services.AcademicCalendarService
+	exporter    exporter.Factory
+	logger      *slog.Logger
 }
 
-func NewScheduleUsecase(authSvc *services.AuthorizationService, repo ScheduleUsecaseRepo, exporter exporter.Factory, logger *slog.Logger) *ScheduleUsecase {
+func NewScheduleUsecase(authSvc *services.AuthorizationService, calendarSvc *services.AcademicCalendarService, repo ScheduleUsecaseRepo, exporter exporter.Factory, logger *slog.Logger) *ScheduleUsecase {
 	return &ScheduleUsecase{
-		repo:     repo,
-		authSvc:  authSvc,
-		exporter: exporter,
-		logger:   logger,
+		repo:        repo,
+		authSvc:     authSvc,
+		calendarSvc: calendarSvc,
+		exporter:    exporter,
+		logger:      logger,
 	}
 }
 
+type ItemTeacherDTO struct {
+	schedules.ItemTeacher
+	Name string
+}
+
 type ScheduleItemDTO struct {
 	schedules.ScheduleItem
-	TeacherName string
+	// TeacherName name of main teacher
+	TeacherName  string
+	TeacherNames []ItemTeacherDTO
+}
+
+// newScheduleItemDTO resolves names of item teachers
+func newScheduleItemDTO(item schedules.ScheduleItem, teachersMap map[uuid.UUID]teachers.Teacher) (ScheduleItemDTO, error) {
+	dto := ScheduleItemDTO{
+		ScheduleItem: item,
+		TeacherNames: make([]ItemTeacherDTO, len(item.Teachers)),
+	}
+
+	for i, it := range item.Teachers {
+		t, ok := teachersMap[it.TeacherID]
+		if !ok {
+			return ScheduleItemDTO{}, fmt.Errorf("teacher with id %s for item %s not found", it.TeacherID, item.Discipline)
+		}
+
+		dto.TeacherNames[i] = ItemTeacherDTO{ItemTeacher: it, Name: t.Name}
+	}
+
+	if len(dto.TeacherNames) > 0 {
+		dto.TeacherName = dto.TeacherNames[0].Name
+	}
+
+	return dto, nil
+}
+
+// collectTeacherIDs returns unique ids of all teachers of items
+func collectTeacherIDs(items []schedules.ScheduleItem) uuid.UUIDs {
+	var teacherIDs uuid.UUIDs
+	m := make(map[uuid.UUID]struct{})
+
+	for _, item := range items {
+		for _, id := range item.TeacherIDs() {
+			if _, ok := m[id]; ok {
+				continue
+			}
+
+			m[id] = struct{}{}
+			teacherIDs = append(teacherIDs, id)
+		}
+	}
+
+	return teacherIDs
 }
 
 type ScheduleDTO struct {
-	ID         uuid.UUID
-	EduGroupID uuid.UUID
-	Semester   int
-	Type       schedules.ScheduleType
-	StartDate  *time.Time
-	EndDate    *time.Time
-	Items      []ScheduleItemDTO
+	ID          uuid.UUID
+	EduGroupID  uuid.UUID
+	Semester    int
+	Type        schedules.ScheduleType
+	StartDate   *time.Time
+	EndDate     *time.Time
+	CycleLength int
+	WorkingDays []time.Weekday
+	Version     int
+	Items       []ScheduleItemDTO
 }
 
 type CreateScheduleInput struct {
-	EduGroupID uuid.UUID
-	Semester   int
-	StartDate  *time.Time
-	EndDate    *time.Time
+	EduGroupID  uuid.UUID
+	Semester    int
+	StartDate   *time.Time
+	EndDate     *time.Time
+	CycleLength *int
+	// WorkingDays overrides faculty working days when provided
+	WorkingDays []time.Weekday
 }
 
 type CreateScheduleOutput struct {
@@ -117,6 +182,40 @@ func (uc *ScheduleUsecase) CreateSchedule(ctx context.Context, input CreateSched
 		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
 	}
 
+	if input.CycleLength != nil {
+		if err := schedule.Cycled.SetCycleLength(*input.CycleLength); err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+	}
+
+	workingDays := input.WorkingDays
+	if workingDays == nil {
+		facultyID, err := uc.repo.GetEduGroupFacultyID(ctx, group.ID)
+		if err != nil {
+			logger.Error("Get edu group faculty id error", "error", err)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		faculty, err := uc.repo.GetFaculty(ctx, facultyID)
+		if err != nil {
+			logger.Error("Get faculty error", "error", err)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		workingDays = faculty.WorkingDays
+	}
+
+	if len(workingDays) > 0 {
+		workingWeek, err := schedules.NewWorkingWeek(workingDays...)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		if err := schedule.SetWorkingWeek(workingWeek); err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+	}
+
 	err = uc.repo.SaveSchedule(ctx, schedule)
 	if err != nil {
 		logger.Error("Save schedule error", "error", err)
@@ -163,18 +262,7 @@ func (uc *ScheduleUsecase) GetSchedule(ctx context.Context, scheduleID uuid.UUID
 		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
-	var teacherIDs uuid.UUIDs
-	m := make(map[uuid.UUID]struct{})
-
-	for _, item := range schedule.ListItem() {
-		if _, ok := m[item.TeacherID]; ok {
-			continue
-		}
-
-		teacherIDs = append(teacherIDs, item.TeacherID)
-	}
-
-	teachersMap, err := uc.repo.MapTeacherByIDs(ctx, teacherIDs)
+	teachersMap, err := uc.repo.MapTeacherByIDs(ctx, collectTeacherIDs(schedule.ListItem()))
 	if err != nil {
 		logger.Error("Get teachers map error", "error", err)
 		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
@@ -240,28 +328,92 @@ func (uc *ScheduleUsecase) ListSchedule(ctx context.Context, user *users.User) (
 	return result, nil
 }
 
+type ItemTeacherInput struct {
+	TeacherID uuid.UUID
+	Role      int8
+}
+
 type AddItemToScheduleInput struct {
-	Discipline    string
+	// ItemID addresses changed item on update. Item is searched by lesson when empty
+	ItemID     *uuid.UUID
+	Discipline string
+	// TeacherID main teacher, used as the only teacher when Teachers is empty
 	TeacherID     uuid.UUID
+	Teachers      []ItemTeacherInput
 	CabinetID     uuid.UUID
 	StudentsCount int16
 	Date          *time.Time
 	Weeknum       *int
 	Weekday       *time.Weekday
 	Weektype      *int8
+	CycleWeeks    []int
+	Weeks         []int
 	LessonNumber  int8
-	Subgroup      int8
-	LessonType    int8
+	// Duration count of consecutive lesson periods, single lesson when zero
+	Duration   int8
+	Subgroup   int8
+	LessonType int8
+	// LocationKind cabinet by default, CabinetID is ignored for online and external lessons
+	LocationKind int8
+	MeetingURL   string
+	Address      string
+}
+
+func (input AddItemToScheduleInput) itemLocation() schedules.Location {
+	return schedules.Location{
+		Kind:       schedules.LocationKind(input.LocationKind),
+		MeetingURL: input.MeetingURL,
+		Address:    input.Address,
+	}
+}
+
+// resolveItemCabinet returns cabinet of lesson. Lessons outside of cabinet have empty one
+func resolveItemCabinet(ctx context.Context, repo ScheduleUsecaseRepo, logger *slog.Logger, input AddItemToScheduleInput) (schedules.Cabinet, error) {
+	if input.LocationKind != int8(schedules.LocationKindCabinet) {
+		return schedules.Cabinet{}, nil
+	}
+
+	cabinet, err := repo.GetCabinet(ctx, input.CabinetID)
+	if err != nil {
+		logger.Error("Get cabinet error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return schedules.Cabinet{}, execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("cabinet %s not found", input.CabinetID))
+		}
+
+		return schedules.Cabinet{}, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return schedules.Cabinet{
+		ID:         cabinet.ID,
+		Building:   cabinet.Building,
+		Auditorium: cabinet.Auditorium,
+	}, nil
+}
+
+func (input AddItemToScheduleInput) itemTeachers() []schedules.ItemTeacher {
+	if len(input.Teachers) == 0 {
+		return []schedules.ItemTeacher{{TeacherID: input.TeacherID, Role: schedules.TeacherRoleMain}}
+	}
+
+	teachers := make([]schedules.ItemTeacher, len(input.Teachers))
+	for i, t := range input.Teachers {
+		teachers[i] = schedules.ItemTeacher{
+			TeacherID: t.TeacherID,
+			Role:      schedules.TeacherRole(t.Role),
+		}
+	}
+
+	return teachers
 }
 
-// AddItemToSchedule
-func (uc *ScheduleUsecase) AddItemsToSchedule(ctx context.Context, scheduleID uuid.UUID, input []AddItemToScheduleInput, user *users.User) error {
+// AddItemToSchedule returns new version of schedule
+func (uc *ScheduleUsecase) AddItemsToSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, input []AddItemToScheduleInput, user *users.User) (int, error) {
 	logger := uc.logger.With("schedule_id", scheduleID)
 
 	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
 	if err != nil {
 		logger.Error("Start transaction error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 	defer rollback(ctx)
 
@@ -270,123 +422,238 @@ func (uc *ScheduleUsecase) AddItemsToSchedule(ctx context.Context, scheduleID uu
 	schedule, err := repo.GetSchedule(ctx, scheduleID)
 	if err != nil {
 		logger.Error("Get schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
 	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
 		logger.Error("Check access to schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	} else if !ok {
-		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
+		return 0, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
+	}
+
+	if err := schedule.CheckVersion(version); err != nil {
+		return 0, scheduleVersionConflict(schedule.Version)
 	}
 
 	//TODO: handle calendar schedule
 	if schedule.Type != schedules.ScheduleTypeCycled {
 		logger.Error("Schedule is not cycled")
-		return execerror.NewExecError(execerror.TypeUnimpemented, errors.New("currently supproted schedule is cycled"))
+		return 0, execerror.NewExecError(execerror.TypeUnimpemented, errors.New("currently supproted schedule is cycled"))
 	}
 
 	for i, item := range input {
 		if item.Weekday == nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weekday"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weekday"))
 		}
 
 		if item.Weektype == nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weektype"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weektype"))
 		}
 
-		cabinet, err := repo.GetCabinet(ctx, item.CabinetID)
+		cabinetValue, err := resolveItemCabinet(ctx, repo, logger, item)
 		if err != nil {
-			logger.Error("Get cabinet error", "error", err)
-			if errors.Is(err, db.ErrorNotFound) {
-				return execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("cabinet %s not found", item.CabinetID))
-			}
-
-			return execerror.NewExecError(execerror.TypeInternal, nil)
-		}
-
-		cabinetValue := schedules.Cabinet{
-			Building:   cabinet.Building,
-			Auditorium: cabinet.Auditorium,
+			return 0, err
 		}
 
 		err = schedule.Cycled.AddItem(
 			item.Discipline,
-			item.TeacherID,
+			item.itemTeachers(),
 			*item.Weekday,
 			item.StudentsCount,
 			item.LessonNumber,
+			item.Duration,
 			item.Subgroup,
 			*item.Weektype,
+			item.CycleWeeks,
+			item.Weeks,
 			item.LessonType,
+			item.itemLocation(),
 			cabinetValue,
 		)
 		if err != nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, err).AddDetails("input_idx", strconv.FormatInt(int64(i), 10))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, err).AddDetails("input_idx", strconv.FormatInt(int64(i), 10))
 		}
 	}
 
-	err = uc.repo.SaveSchedule(ctx, schedule)
+	err = repo.SaveSchedule(ctx, schedule)
 	if err != nil {
 		logger.Error("Save schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		if errors.Is(err, db.ErrorVersionConflict) {
+			return 0, scheduleVersionConflict(schedule.Version)
+		}
+
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
 	err = commit(ctx)
 	if err != nil {
 		logger.Error("Save updated schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
-	return nil
+	return schedule.Version, nil
+}
+
+type ScheduleDayDTO struct {
+	Date    time.Time
+	Weekday time.Weekday
+	// Weeknum and Weektype of education week containing date, nil before education start
+	Weeknum  *int
+	Weektype *schedules.Weektype
+	Items    []ScheduleItemDTO
+}
+
+type GetListScheduleItemForSpecifiedDateOutput struct {
+	ScheduleDayDTO
+	ScheduleID     uuid.UUID
+	EduGroupNumber string
 }
 
 // GetListScheduleItemForSpecifiedDate
-func (uc *ScheduleUsecase) GetListScheduleItemForSpecifiedDate(ctx context.Context, scheduleID uuid.UUID, date time.Time, user *users.User) ([]schedules.ScheduleItem, error) {
+func (uc *ScheduleUsecase) GetListScheduleItemForSpecifiedDate(ctx context.Context, scheduleID uuid.UUID, date time.Time, user *users.User) (*GetListScheduleItemForSpecifiedDateOutput, error) {
 	logger := uc.logger.With("schedule_id", scheduleID, "date", date)
 
+	group, days, err := uc.listScheduleDays(ctx, logger, scheduleID, date, date, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetListScheduleItemForSpecifiedDateOutput{
+		ScheduleDayDTO: days[0],
+		ScheduleID:     scheduleID,
+		EduGroupNumber: group.Number,
+	}, nil
+}
+
+type GetScheduleCalendarOutput struct {
+	ScheduleID     uuid.UUID
+	EduGroupNumber string
+	From           time.Time
+	To             time.Time
+	Days           []ScheduleDayDTO
+}
+
+// GetScheduleCalendar
+func (uc *ScheduleUsecase) GetScheduleCalendar(ctx context.Context, scheduleID uuid.UUID, from, to time.Time, user *users.User) (*GetScheduleCalendarOutput, error) {
+	logger := uc.logger.With("schedule_id", scheduleID, "from", from, "to", to)
+
+	if from.After(to) {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("from is after to"))
+	}
+
+	if to.Sub(from) > MaxTimetableRangeDays*24*time.Hour {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("range can not be longer than %d days", MaxTimetableRangeDays))
+	}
+
+	group, days, err := uc.listScheduleDays(ctx, logger, scheduleID, from, to, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetScheduleCalendarOutput{
+		ScheduleID:     scheduleID,
+		EduGroupNumber: group.Number,
+		From:           from,
+		To:             to,
+		Days:           days,
+	}, nil
+}
+
+// listScheduleDays returns dated schedule items grouped by day for each date in range. Works for both cycled and calendar schedules
+func (uc *ScheduleUsecase) listScheduleDays(ctx context.Context, logger *slog.Logger, scheduleID uuid.UUID, from, to time.Time, user *users.User) (*edugroups.EduGroup, []ScheduleDayDTO, error) {
 	schedule, err := uc.repo.GetSchedule(ctx, scheduleID)
 	if err != nil {
 		logger.Error("Get schedule error", "error", err)
 		if errors.Is(err, db.ErrorNotFound) {
-			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
+			return nil, nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
 		}
 
-		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
 	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
 		logger.Error("Check access to schedule error", "error", err)
-		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	} else if !ok {
-		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
-	}
-
-	if schedule.Type != schedules.ScheduleTypeCycled {
-		logger.Error("Schedule is not cycled")
-		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("allowed only for cycled schedule"))
+		return nil, nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
 	}
 
 	group, err := uc.repo.GetEduGroup(ctx, schedule.EduGroupID)
 	if err != nil {
 		logger.Error("Get schedule edu group error", "error", err)
-		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
-	educationStartDate := group.GetEducationStartDateBySemester(schedule.Semester)
+	educationStartDate, err := uc.calendarSvc.GetEducationStartDate(ctx, group, schedule.Semester)
+	if err != nil {
+		logger.Error("Get education start date error", "error", err)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
 
 	scheduleSvc := schedules.NewScheduleService()
 
-	items, err := scheduleSvc.ListScheduleItemByDate(schedule.Cycled, educationStartDate, date)
+	items, err := scheduleSvc.ListScheduleItemByDateRange(schedule, educationStartDate, from, to)
 	if err != nil {
-		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		return nil, nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+	}
+
+	teachersMap, err := uc.repo.MapTeacherByIDs(ctx, collectTeacherIDs(items))
+	if err != nil {
+		logger.Error("Get teachers map error", "error", err)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	byDate := make(map[string][]ScheduleItemDTO)
+	for _, item := range items {
+		dto, err := newScheduleItemDTO(item, teachersMap)
+		if err != nil {
+			logger.Error("Create schedule item dto error", "error", err)
+			return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		key := item.Date.In(from.Location()).Format(time.DateOnly)
+		byDate[key] = append(byDate[key], dto)
+	}
+
+	y, mo, d := from.Date()
+	start := time.Date(y, mo, d, 0, 0, 0, 0, from.Location())
+
+	y, mo, d = educationStartDate.In(from.Location()).Date()
+	educationStartDay := time.Date(y, mo, d, 0, 0, 0, 0, from.Location())
+
+	var days []ScheduleDayDTO
+	for date := start; !date.After(to); date = date.AddDate(0, 0, 1) {
+		dayItems := byDate[date.Format(time.DateOnly)]
+		slices.SortStableFunc(dayItems, func(a, b ScheduleItemDTO) int {
+			if c := cmp.Compare(a.LessonNumber, b.LessonNumber); c != 0 {
+				return c
+			}
+
+			return cmp.Compare(a.Subgroup, b.Subgroup)
+		})
+
+		day := ScheduleDayDTO{
+			Date:    date,
+			Weekday: date.Weekday(),
+			Items:   dayItems,
+		}
+
+		if !date.Before(educationStartDay) {
+			weekNumber := schedules.WeekNumber(educationStartDate, date)
+			weektype := schedules.WeektypeOfWeek(weekNumber)
+			day.Weeknum = &weekNumber
+			day.Weektype = &weektype
+		}
+
+		days = append(days, day)
 	}
 
-	return items, nil
+	return group, days, nil
 }
 
 // ExportSchedule
-func (uc *ScheduleUsecase) ExportSchedule(ctx context.Context, scheduleID uuid.UUID, format string, dst io.Writer, user *users.User) error {
+func (uc *ScheduleUsecase) ExportSchedule(ctx context.Context, scheduleID uuid.UUID, format, csvProfile string, dst io.Writer, user *users.User) error {
 	logger := uc.logger.With("schedule_id", scheduleID)
 
 	schedule, err := uc.repo.GetSchedule(ctx, scheduleID)
@@ -406,27 +673,28 @@ func (uc *ScheduleUsecase) ExportSchedule(ctx context.Context, scheduleID uuid.U
 		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
 	}
 
-	exp, err := uc.exporter.ByFormat(format)
+	exp, err := uc.exporterByFormat(ctx, logger, format, csvProfile)
 	if err != nil {
-		logger.Error("Get exporter by formate error", "error", err)
-		if errors.Is(err, exporter.ErrUnknownFormat) {
-			return execerror.NewExecError(execerror.TypeInvalidInput, err)
-		}
+		return err
+	}
 
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+	if _, ok := exp.(exporter.CalendarExporter); ok && schedule.Type == schedules.ScheduleTypeCycled {
+		schedule, err = uc.cycledToCalendar(ctx, logger, schedule)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = exp.Export(ctx, schedule, dst)
 	if err != nil {
-		logger.Error("Export schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return exportError(ctx, logger, "Export schedule error", err)
 	}
 
 	return nil
 }
 
 // ExportCycledScheduleAsCalendar
-func (uc *ScheduleUsecase) ExportCycledScheduleAsCalendar(ctx context.Context, scheduleID uuid.UUID, format string, dst io.Writer, user *users.User) error {
+func (uc *ScheduleUsecase) ExportCycledScheduleAsCalendar(ctx context.Context, scheduleID uuid.UUID, format, csvProfile string, dst io.Writer, user *users.User) error {
 	logger := uc.logger.With("schedule_id", scheduleID)
 
 	schedule, err := uc.repo.GetSchedule(ctx, scheduleID)
@@ -450,160 +718,1030 @@ func (uc *ScheduleUsecase) ExportCycledScheduleAsCalendar(ctx context.Context, s
 		return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule is not cycled"))
 	}
 
-	group, err := uc.repo.GetEduGroup(ctx, schedule.EduGroupID)
-	if err != nil {
-		logger.Error("Get schedule edu group error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
-	}
-
-	calendarSchedule, err := schedules.CalendarScheduleFromCycled(schedule.EduGroupID, schedule.Semester, schedule.Cycled, group.GetEducationStartDateBySemester(schedule.Semester))
+	calendarSchedule, err := uc.cycledToCalendar(ctx, logger, schedule)
 	if err != nil {
-		logger.Error("Make calendar from cycled schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInvalidInput, err)
+		return err
 	}
 
-	log.Println(calendarSchedule.ListItem())
-
-	exp, err := uc.exporter.ByFormat(format)
+	exp, err := uc.exporterByFormat(ctx, logger, format, csvProfile)
 	if err != nil {
-		logger.Error("Get exporter by formate error", "error", err)
-		if errors.Is(err, exporter.ErrUnknownFormat) {
-			return execerror.NewExecError(execerror.TypeInvalidInput, err)
-		}
-
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return err
 	}
 
 	err = exp.Export(ctx, calendarSchedule, dst)
 	if err != nil {
-		logger.Error("Export schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return exportError(ctx, logger, "Export schedule error", err)
 	}
 
 	return nil
 }
 
-type RemoveItemFromScheduleInput struct {
-	Date         *time.Time
-	Weekday      *time.Weekday
-	LessonNumber int8
-	Subgroup     int8
-	Weektype     *int8
-}
-
-// RemoveItemsFromSchedule
-func (uc *ScheduleUsecase) RemoveItemsFromSchedule(ctx context.Context, scheduleID uuid.UUID, input []RemoveItemFromScheduleInput, user *users.User) error {
-	logger := uc.logger.With("schedule_id", scheduleID)
-
-	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
-	if err != nil {
-		logger.Error("Start transaction error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
-	}
-	defer rollback(ctx)
-
-	repo := tx.(ScheduleUsecaseRepo)
+// ExportFacultySchedules exports schedules of faculty groups into single document. Schedules of provided semester
+// are exported, latest schedule of each group when semester is not set
+func (uc *ScheduleUsecase) ExportFacultySchedules(ctx context.Context, facultyID uuid.UUID, semester int, format string, dst io.Writer, user *users.User) error {
+	logger := uc.logger.With("faculty_id", facultyID, "semester", semester)
 
-	schedule, err := repo.GetSchedule(ctx, scheduleID)
+	faculty, err := uc.repo.GetFaculty(ctx, facultyID)
 	if err != nil {
-		logger.Error("Get schedule error", "error", err)
+		logger.Error("Get faculty error", "error", err)
 		if errors.Is(err, db.ErrorNotFound) {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
+			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("faculty not found"))
 		}
 
 		return execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
-	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
-		logger.Error("Check access to schedule error", "error", err)
+	if ok, err := uc.authSvc.HaveAccessToFaculty(ctx, faculty, user); err != nil {
+		logger.Error("Check access to faculty error", "error", err)
 		return execerror.NewExecError(execerror.TypeInternal, nil)
 	} else if !ok {
-		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
+		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to faculty"))
 	}
 
-	for _, item := range input {
-		switch schedule.Type {
-		case schedules.ScheduleTypeCycled:
-			if item.Weekday == nil {
-				return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weekday"))
-			}
-
-			if item.Weektype == nil {
-				return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weektype"))
-			}
-
-			err := schedule.Cycled.RemoveItem(*item.Weekday, item.LessonNumber, item.Subgroup, *item.Weektype)
-			if err != nil {
-				logger.Error("Remove item error", "error", err)
-				return execerror.NewExecError(execerror.TypeInvalidInput, err)
-			}
-		case schedules.ScheduleTypeCalendar:
-			if item.Date == nil {
-				return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing date"))
-			}
+	exp, err := uc.exporterByFormat(ctx, logger, format, "")
+	if err != nil {
+		return err
+	}
 
-			err := schedule.Calendar.RemoveItem(*item.Date, item.LessonNumber, item.Subgroup)
-			if err != nil {
-				logger.Error("Remove item error", "error", err)
-				return execerror.NewExecError(execerror.TypeInvalidInput, err)
-			}
-		}
+	workbookExp, ok := exp.(exporter.WorkbookExporter)
+	if !ok {
+		return execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("format %s does not support export of several schedules", format))
 	}
 
-	err = repo.SaveSchedule(ctx, schedule)
+	selected, _, err := uc.selectFacultySchedules(ctx, logger, facultyID, FacultySchedulesFilter{Semester: semester})
 	if err != nil {
-		logger.Error("Save schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return err
 	}
 
-	err = commit(ctx)
+	err = workbookExp.ExportWorkbook(ctx, selected, dst)
 	if err != nil {
-		logger.Error("Save updated schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return exportError(ctx, logger, "Export faculty schedules error", err)
 	}
 
 	return nil
 }
 
-type UpdateScheduleInput struct {
-	ID        uuid.UUID
-	Semester  *int
-	StartDate *time.Time
-	EndDate   *time.Time
+type ImportScheduleInput struct {
+	EduGroupID uuid.UUID
+	// Document schedule document written by json exporter
+	Document io.Reader
 }
 
-type UpdateScheduleOutput GetScheduleOutput
-
-// UpdateSchedule
-func (uc *ScheduleUsecase) UpdateSchedule(ctx context.Context, input UpdateScheduleInput, user *users.User) (*UpdateScheduleOutput, error) {
-	logger := uc.logger.With("schedule_id", input.ID)
+// ImportSchedule creates schedule of group from schedule document. Every item is added through schedule validation,
+// errors of all invalid items are returned in details by item index and nothing is saved then
+func (uc *ScheduleUsecase) ImportSchedule(ctx context.Context, input ImportScheduleInput, user *users.User) (*CreateScheduleOutput, error) {
+	logger := uc.logger.With("edu_group_id", input.EduGroupID)
 
-	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	doc, err := exporter.DecodeScheduleDocument(input.Document)
 	if err != nil {
-		logger.Error("Start transaction error", "error", err)
-		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
 	}
-	defer rollback(ctx)
 
-	repo := tx.(ScheduleUsecaseRepo)
+	scheduleType, err := doc.ScheduleType()
+	if err != nil {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+	}
 
-	schedule, err := repo.GetSchedule(ctx, input.ID)
+	group, err := uc.repo.GetEduGroup(ctx, input.EduGroupID)
 	if err != nil {
-		logger.Error("Get schedule error", "error", err)
+		logger.Error("Get edu group error", "error", err)
 		if errors.Is(err, db.ErrorNotFound) {
-			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("edu group not found"))
 		}
 
 		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
-	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
-		logger.Error("Check access to schedule error", "error", err)
+	if ok, err := uc.authSvc.HaveAccessToEduGroup(ctx, group, user); err != nil {
+		logger.Error("Check access to group error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to edu group"))
+	}
+
+	scheduleExists := func() error {
+		return execerror.NewExecError(execerror.TypeProcessingConflict, errors.New("schedule for group and semester already exists")).
+			AddDetails("edu_group_id", input.EduGroupID.String()).
+			AddDetails("semester", strconv.FormatInt(int64(doc.Semester), 10))
+	}
+
+	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	if err != nil {
+		logger.Error("Start transaction error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+	defer rollback(ctx)
+
+	repo := tx.(ScheduleUsecaseRepo)
+
+	if _, err := repo.GetScheduleByEduGroupIDAndSemester(ctx, input.EduGroupID, doc.Semester); err == nil {
+		return nil, scheduleExists()
+	} else if !errors.Is(err, db.ErrorNotFound) {
+		logger.Error("Check if schedule alreay exists for semeter error", "error", err, "semester", doc.Semester)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	var schedule *schedules.Schedule
+
+	switch scheduleType {
+	case schedules.ScheduleTypeCycled:
+		startDate, err := time.ParseInLocation(time.DateOnly, doc.StartDate, common.DefaultTimezone)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("invalid start date: %w", err))
+		}
+
+		endDate, err := time.ParseInLocation(time.DateOnly, doc.EndDate, common.DefaultTimezone)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("invalid end date: %w", err))
+		}
+
+		schedule, err = schedules.NewCycledSchedule(group.ID, doc.Semester, startDate, endDate, int(group.AdmissionYear), time.Now().Year())
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		if doc.CycleLength > 0 {
+			if err := schedule.Cycled.SetCycleLength(doc.CycleLength); err != nil {
+				return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
+		}
+	case schedules.ScheduleTypeCalendar:
+		schedule = &schedules.Schedule{
+			ID:         uuid.New(),
+			EduGroupID: group.ID,
+			Semester:   doc.Semester,
+			Type:       schedules.ScheduleTypeCalendar,
+			Calendar:   &schedules.CalendarSchedule{WorkingWeek: schedules.DefaultWorkingWeek},
+		}
+
+		if err := schedule.Validate(int(group.AdmissionYear), time.Now().Year()); err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+	}
+
+	if len(doc.WorkingDays) > 0 {
+		workingWeek, err := schedules.NewWorkingWeek(doc.WorkingDays...)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		if err := schedule.SetWorkingWeek(workingWeek); err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+	}
+
+	refs := &documentRefs{
+		teachers: make(map[string]uuid.UUID),
+		cabinets: make(map[exporter.ScheduleDocumentCabinet]schedules.Cabinet),
+	}
+
+	var invalid *execerror.ExecError
+	for i, item := range doc.Items {
+		err := uc.importScheduleItem(ctx, logger, schedule, item, refs)
+		if err == nil {
+			continue
+		}
+
+		var execErr *execerror.ExecError
+		if errors.As(err, &execErr) {
+			return nil, execErr
+		}
+
+		if invalid == nil {
+			invalid = execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule document has invalid items"))
+		}
+
+		invalid.AddDetails("items["+strconv.Itoa(i)+"]", err.Error())
+	}
+
+	if invalid != nil {
+		return nil, invalid
+	}
+
+	err = repo.SaveSchedule(ctx, schedule)
+	if err != nil {
+		logger.Error("Save schedule error", "error", err)
+		if errors.Is(err, db.ErrorUniqueViolation) {
+			return nil, scheduleExists()
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if err := commit(ctx); err != nil {
+		logger.Error("Commit imported schedule error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	dto, _ := scheduleToCycledScheduleDTO(schedule, nil, false)
+
+	return &CreateScheduleOutput{
+		ScheduleDTO:    dto,
+		EduGroupNumber: group.Number,
+	}, nil
+}
+
+// documentRefs teachers and cabinets of schedule document resolved during import
+type documentRefs struct {
+	teachers map[string]uuid.UUID
+	cabinets map[exporter.ScheduleDocumentCabinet]schedules.Cabinet
+}
+
+// importScheduleItem adds item of schedule document to schedule. Invalid item and unknown references are returned
+// as plain errors, failures of repository as execution errors
+func (uc *ScheduleUsecase) importScheduleItem(ctx context.Context, logger *slog.Logger, schedule *schedules.Schedule, item exporter.ScheduleDocumentItem, refs *documentRefs) error {
+	itemTeachers := make([]schedules.ItemTeacher, len(item.Teachers))
+	for i, t := range item.Teachers {
+		id, ok := refs.teachers[t.ExternalID]
+		if !ok {
+			teacher, err := uc.repo.GetTeacherByExternalID(ctx, t.ExternalID)
+			if err != nil {
+				if errors.Is(err, db.ErrorNotFound) {
+					return fmt.Errorf("teacher with external id %q not found", t.ExternalID)
+				}
+
+				logger.Error("Get teacher by external id error", "error", err, "external_id", t.ExternalID)
+				return execerror.NewExecError(execerror.TypeInternal, nil)
+			}
+
+			id = teacher.ID
+			refs.teachers[t.ExternalID] = id
+		}
+
+		itemTeachers[i] = schedules.ItemTeacher{TeacherID: id, Role: schedules.TeacherRole(t.Role)}
+	}
+
+	location := schedules.Location{
+		Kind:       schedules.LocationKind(item.Location.Kind),
+		MeetingURL: item.Location.MeetingURL,
+		Address:    item.Location.Address,
+	}
+
+	var cabinet schedules.Cabinet
+	if location.Kind == schedules.LocationKindCabinet {
+		if item.Cabinet == nil {
+			return errors.New("missing cabinet")
+		}
+
+		var ok bool
+		if cabinet, ok = refs.cabinets[*item.Cabinet]; !ok {
+			c, err := uc.repo.GetCabinetByAddress(ctx, item.Cabinet.Building, item.Cabinet.Auditorium)
+			if err != nil {
+				if errors.Is(err, db.ErrorNotFound) {
+					return fmt.Errorf("cabinet %s %s not found", item.Cabinet.Building, item.Cabinet.Auditorium)
+				}
+
+				logger.Error("Get cabinet by address error", "error", err)
+				return execerror.NewExecError(execerror.TypeInternal, nil)
+			}
+
+			cabinet = schedules.Cabinet{ID: c.ID, Building: c.Building, Auditorium: c.Auditorium}
+			refs.cabinets[*item.Cabinet] = cabinet
+		}
+	}
+
+	switch schedule.Type {
+	case schedules.ScheduleTypeCycled:
+		if item.Weektype == nil {
+			return errors.New("missing weektype")
+		}
+
+		return schedule.Cycled.AddItem(
+			item.Discipline,
+			itemTeachers,
+			item.Weekday,
+			item.StudentsCount,
+			item.LessonNumber,
+			item.Duration,
+			item.Subgroup,
+			*item.Weektype,
+			item.CycleWeeks,
+			item.Weeks,
+			item.LessonType,
+			location,
+			cabinet,
+		)
+	case schedules.ScheduleTypeCalendar:
+		date, err := time.ParseInLocation(time.DateOnly, item.Date, common.DefaultTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid date: %w", err)
+		}
+
+		if item.Weeknum == nil {
+			return errors.New("missing weeknum")
+		}
+
+		return schedule.Calendar.AddItem(
+			item.Discipline,
+			itemTeachers,
+			date,
+			item.StudentsCount,
+			item.LessonNumber,
+			item.Duration,
+			item.Subgroup,
+			*item.Weeknum,
+			item.LessonType,
+			location,
+			cabinet,
+		)
+	}
+
+	return errors.New("unknown schedule type")
+}
+
+type ImportCycledCsvInput struct {
+	// Document file of cycled csv format
+	Document  io.Reader
+	Semester  int
+	StartDate time.Time
+	EndDate   time.Time
+	// DryRun only checks file and reports problems, nothing is saved
+	DryRun bool
+}
+
+type ImportedScheduleDTO struct {
+	EduGroupID     uuid.UUID
+	EduGroupNumber string
+	// ScheduleID id of created schedule, empty on dry run
+	ScheduleID uuid.UUID
+	ItemsCount int
+}
+
+type ImportLineErrorDTO struct {
+	Lines []int
+	Error string
+}
+
+type ImportCycledCsvOutput struct {
+	DryRun             bool
+	Schedules          []ImportedScheduleDTO
+	UnresolvedGroups   []string
+	UnresolvedTeachers []string
+	UnresolvedCabinets []string
+	Errors             []ImportLineErrorDTO
+	// PlaceholderMeetingURLLines lines of online lessons imported with ImportedMeetingURL, csv file does not
+	// carry meeting urls, so they must be set after import
+	PlaceholderMeetingURLLines []int
+}
+
+func (out *ImportCycledCsvOutput) hasProblems() bool {
+	return len(out.UnresolvedGroups) > 0 || len(out.UnresolvedTeachers) > 0 || len(out.UnresolvedCabinets) > 0 || len(out.Errors) > 0
+}
+
+// ImportCycledCsv creates cycled schedules of groups found in file of cycled csv format. Groups are resolved by number,
+// teachers by external id and cabinets by address. Schedules are saved only when every line of file is imported,
+// dry run reports unresolved references and invalid lines without saving
+func (uc *ScheduleUsecase) ImportCycledCsv(ctx context.Context, input ImportCycledCsvInput, user *users.User) (*ImportCycledCsvOutput, error) {
+	logger := uc.logger.With("semester", input.Semester)
+
+	items, lineErrs, err := exporter.ReadCycledCsv(input.Document)
+	if err != nil {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+	}
+
+	out := &ImportCycledCsvOutput{DryRun: input.DryRun}
+	for _, lineErr := range lineErrs {
+		out.Errors = append(out.Errors, ImportLineErrorDTO{Lines: []int{lineErr.Line}, Error: lineErr.Err.Error()})
+	}
+
+	var groupNumbers []string
+	groupItems := make(map[string][]exporter.CycledCsvItem)
+	for _, item := range items {
+		if _, ok := groupItems[item.GroupNumber]; !ok {
+			groupNumbers = append(groupNumbers, item.GroupNumber)
+		}
+
+		groupItems[item.GroupNumber] = append(groupItems[item.GroupNumber], item)
+	}
+
+	refs := &csvRefs{
+		teachers:           make(map[string]uuid.UUID),
+		cabinets:           make(map[schedules.Cabinet]schedules.Cabinet),
+		unresolvedTeachers: make(map[string]struct{}),
+		unresolvedCabinets: make(map[string]struct{}),
+	}
+
+	var created []*schedules.Schedule
+
+	for _, number := range groupNumbers {
+		groupLogger := logger.With("edu_group_number", number)
+		list := groupItems[number]
+
+		var lines []int
+		for _, item := range list {
+			lines = append(lines, item.Lines...)
+		}
+
+		group, err := uc.repo.GetEduGroupByNumber(ctx, number)
+		if err != nil {
+			if errors.Is(err, db.ErrorNotFound) {
+				out.UnresolvedGroups = append(out.UnresolvedGroups, number)
+				continue
+			}
+
+			groupLogger.Error("Get edu group by number error", "error", err)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		if ok, err := uc.authSvc.HaveAccessToEduGroup(ctx, group, user); err != nil {
+			groupLogger.Error("Check access to group error", "error", err)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		} else if !ok {
+			return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to edu group")).
+				AddDetails("edu_group_number", number)
+		}
+
+		schedule, err := uc.newImportedSchedule(ctx, groupLogger, group, input, list)
+		if err != nil {
+			var execErr *execerror.ExecError
+			if errors.As(err, &execErr) {
+				return nil, execErr
+			}
+
+			out.Errors = append(out.Errors, ImportLineErrorDTO{Lines: lines, Error: err.Error()})
+			continue
+		}
+
+		for _, item := range list {
+			err := uc.importCsvItem(ctx, groupLogger, schedule, item, refs)
+			if err == nil {
+				if item.Location.Kind == schedules.LocationKindOnline && item.Location.MeetingURL == "" {
+					out.PlaceholderMeetingURLLines = append(out.PlaceholderMeetingURLLines, item.Lines...)
+				}
+
+				continue
+			}
+
+			var execErr *execerror.ExecError
+			if errors.As(err, &execErr) {
+				return nil, execErr
+			}
+
+			if !errors.Is(err, errUnresolvedReference) {
+				out.Errors = append(out.Errors, ImportLineErrorDTO{Lines: item.Lines, Error: err.Error()})
+			}
+		}
+
+		created = append(created, schedule)
+		out.Schedules = append(out.Schedules, ImportedScheduleDTO{
+			EduGroupID:     group.ID,
+			EduGroupNumber: group.Number,
+			ItemsCount:     len(schedule.ListItem()),
+		})
+	}
+
+	out.UnresolvedTeachers = slices.Sorted(maps.Keys(refs.unresolvedTeachers))
+	out.UnresolvedCabinets = slices.Sorted(maps.Keys(refs.unresolvedCabinets))
+
+	if input.DryRun {
+		return out, nil
+	}
+
+	if out.hasProblems() {
+		execErr := execerror.NewExecError(execerror.TypeInvalidInput, errors.New("csv file can not be imported"))
+		if len(out.UnresolvedGroups) > 0 {
+			execErr.AddDetails("unresolved_groups", out.UnresolvedGroups...)
+		}
+		if len(out.UnresolvedTeachers) > 0 {
+			execErr.AddDetails("unresolved_teachers", out.UnresolvedTeachers...)
+		}
+		if len(out.UnresolvedCabinets) > 0 {
+			execErr.AddDetails("unresolved_cabinets", out.UnresolvedCabinets...)
+		}
+		for _, e := range out.Errors {
+			execErr.AddDetails("errors", fmt.Sprintf("lines %v: %s", e.Lines, e.Error))
+		}
+
+		return nil, execErr
+	}
+
+	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	if err != nil {
+		logger.Error("Start transaction error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+	defer rollback(ctx)
+
+	repo := tx.(ScheduleUsecaseRepo)
+
+	for i, schedule := range created {
+		if err := repo.SaveSchedule(ctx, schedule); err != nil {
+			logger.Error("Save schedule error", "error", err, "edu_group_id", schedule.EduGroupID)
+			if errors.Is(err, db.ErrorUniqueViolation) {
+				return nil, execerror.NewExecError(execerror.TypeProcessingConflict, errors.New("schedule for group and semester already exists")).
+					AddDetails("edu_group_number", out.Schedules[i].EduGroupNumber)
+			}
+
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		out.Schedules[i].ScheduleID = schedule.ID
+	}
+
+	if err := commit(ctx); err != nil {
+		logger.Error("Commit imported schedules error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return out, nil
+}
+
+// newImportedSchedule creates empty cycled schedule of group with working days of faculty and the longest cycle of
+// imported items. Invalid schedule is returned as plain error, failures of repository as execution errors
+func (uc *ScheduleUsecase) newImportedSchedule(ctx context.Context, logger *slog.Logger, group *edugroups.EduGroup, input ImportCycledCsvInput, items []exporter.CycledCsvItem) (*schedules.Schedule, error) {
+	if _, err := uc.repo.GetScheduleByEduGroupIDAndSemester(ctx, group.ID, input.Semester); err == nil {
+		return nil, fmt.Errorf("schedule of group %s for semester %d already exists", group.Number, input.Semester)
+	} else if !errors.Is(err, db.ErrorNotFound) {
+		logger.Error("Check if schedule alreay exists for semeter error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, input.Semester, input.StartDate, input.EndDate, int(group.AdmissionYear), time.Now().Year())
+	if err != nil {
+		return nil, err
+	}
+
+	cycleLength := 0
+	for _, item := range items {
+		cycleLength = max(cycleLength, item.CycleLength)
+	}
+
+	if cycleLength > 0 {
+		if err := schedule.Cycled.SetCycleLength(cycleLength); err != nil {
+			return nil, err
+		}
+	}
+
+	facultyID, err := uc.repo.GetEduGroupFacultyID(ctx, group.ID)
+	if err != nil {
+		logger.Error("Get edu group faculty id error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	faculty, err := uc.repo.GetFaculty(ctx, facultyID)
+	if err != nil {
+		logger.Error("Get faculty error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if len(faculty.WorkingDays) > 0 {
+		workingWeek, err := schedules.NewWorkingWeek(faculty.WorkingDays...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := schedule.SetWorkingWeek(workingWeek); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedule, nil
+}
+
+var errUnresolvedReference = errors.New("unresolved reference")
+
+// ImportedMeetingURL placeholder meeting url of online lessons imported from csv file, reserved .invalid domain
+// never resolves, so placeholder can not be mistaken for real meeting
+const ImportedMeetingURL = "https://meeting-url.invalid/"
+
+// csvRefs teachers and cabinets of csv file resolved during import, unresolved ones are collected for report
+type csvRefs struct {
+	teachers           map[string]uuid.UUID
+	cabinets           map[schedules.Cabinet]schedules.Cabinet
+	unresolvedTeachers map[string]struct{}
+	unresolvedCabinets map[string]struct{}
+}
+
+// importCsvItem adds item read from csv file to schedule. Item with unresolved teacher or cabinet is skipped
+// with errUnresolvedReference, online lesson without meeting url gets ImportedMeetingURL
+func (uc *ScheduleUsecase) importCsvItem(ctx context.Context, logger *slog.Logger, schedule *schedules.Schedule, item exporter.CycledCsvItem, refs *csvRefs) error {
+	unresolved := false
+
+	itemTeachers := make([]schedules.ItemTeacher, len(item.TeacherExternalIDs))
+	for i, externalID := range item.TeacherExternalIDs {
+		role := schedules.TeacherRoleAssistant
+		if i == 0 {
+			role = schedules.TeacherRoleMain
+		}
+
+		id, ok := refs.teachers[externalID]
+		if !ok {
+			if _, ok := refs.unresolvedTeachers[externalID]; ok {
+				unresolved = true
+				continue
+			}
+
+			teacher, err := uc.repo.GetTeacherByExternalID(ctx, externalID)
+			if err != nil {
+				if errors.Is(err, db.ErrorNotFound) {
+					refs.unresolvedTeachers[externalID] = struct{}{}
+					unresolved = true
+					continue
+				}
+
+				logger.Error("Get teacher by external id error", "error", err, "external_id", externalID)
+				return execerror.NewExecError(execerror.TypeInternal, nil)
+			}
+
+			id = teacher.ID
+			refs.teachers[externalID] = id
+		}
+
+		itemTeachers[i] = schedules.ItemTeacher{TeacherID: id, Role: role}
+	}
+
+	var cabinet schedules.Cabinet
+	if item.Location.Kind == schedules.LocationKindCabinet {
+		var ok bool
+		if cabinet, ok = refs.cabinets[item.Cabinet]; !ok {
+			c, err := uc.repo.GetCabinetByAddress(ctx, item.Cabinet.Building, item.Cabinet.Auditorium)
+			if err != nil {
+				if !errors.Is(err, db.ErrorNotFound) {
+					logger.Error("Get cabinet by address error", "error", err, "address", item.Aud)
+					return execerror.NewExecError(execerror.TypeInternal, nil)
+				}
+
+				refs.unresolvedCabinets[item.Aud] = struct{}{}
+				unresolved = true
+			} else {
+				cabinet = schedules.Cabinet{ID: c.ID, Building: c.Building, Auditorium: c.Auditorium}
+				refs.cabinets[item.Cabinet] = cabinet
+			}
+		}
+	}
+
+	if unresolved {
+		return errUnresolvedReference
+	}
+
+	location := item.Location
+	if location.Kind == schedules.LocationKindOnline && location.MeetingURL == "" {
+		location.MeetingURL = ImportedMeetingURL
+	}
+
+	return schedule.Cycled.AddItem(
+		item.Discipline,
+		itemTeachers,
+		item.Weekday,
+		0,
+		item.LessonNumber,
+		item.Duration,
+		item.Subgroup,
+		int8(item.Weektype),
+		item.CycleWeeks,
+		item.Weeks,
+		int8(item.LessonType),
+		location,
+		cabinet,
+	)
+}
+
+// FacultySchedulesFilter selects schedules of faculty. Latest schedule of every group is selected when semester is
+// not set
+type FacultySchedulesFilter struct {
+	Semester int
+	// EduGroupIDs limits schedules to groups, all groups of faculty when empty
+	EduGroupIDs uuid.UUIDs
+}
+
+// selectFacultySchedules returns schedules of faculty matching filter ordered by group number with groups of schedules
+func (uc *ScheduleUsecase) selectFacultySchedules(ctx context.Context, logger *slog.Logger, facultyID uuid.UUID, filter FacultySchedulesFilter) ([]*schedules.Schedule, map[uuid.UUID]edugroups.EduGroup, error) {
+	list, err := uc.repo.ListScheduleByFaculty(ctx, facultyID)
+	if err != nil {
+		logger.Error("List faculty schedules error", "error", err)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	// schedules are ordered by group and semester descending, so first schedule of group is the latest one
+	var selected []*schedules.Schedule
+	var scheduleIDs uuid.UUIDs
+	seenGroups := make(map[uuid.UUID]struct{})
+	for i := range list {
+		schedule := &list[i]
+		if filter.Semester > 0 && schedule.Semester != filter.Semester {
+			continue
+		}
+
+		if len(filter.EduGroupIDs) > 0 && !slices.Contains(filter.EduGroupIDs, schedule.EduGroupID) {
+			continue
+		}
+
+		if _, ok := seenGroups[schedule.EduGroupID]; ok && filter.Semester == 0 {
+			continue
+		}
+
+		seenGroups[schedule.EduGroupID] = struct{}{}
+		selected = append(selected, schedule)
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+	}
+
+	groups, err := uc.repo.MapEduGroupsBySchedules(ctx, scheduleIDs)
+	if err != nil {
+		logger.Error("Map edu groups by schedules error", "error", err)
+		return nil, nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	slices.SortStableFunc(selected, func(a, b *schedules.Schedule) int {
+		return cmp.Compare(groups[a.EduGroupID].Number, groups[b.EduGroupID].Number)
+	})
+
+	return selected, groups, nil
+}
+
+// ExportFacultyArchive exports schedules of faculty matching filter into zip archive with file per schedule
+// and manifest
+func (uc *ScheduleUsecase) ExportFacultyArchive(ctx context.Context, facultyID uuid.UUID, filter FacultySchedulesFilter, format, csvProfile string, dst io.Writer, user *users.User) error {
+	logger := uc.logger.With("faculty_id", facultyID, "semester", filter.Semester, "format", format)
+
+	faculty, err := uc.repo.GetFaculty(ctx, facultyID)
+	if err != nil {
+		logger.Error("Get faculty error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("faculty not found"))
+		}
+
+		return execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToFaculty(ctx, faculty, user); err != nil {
+		logger.Error("Check access to faculty error", "error", err)
+		return execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to faculty"))
+	}
+
+	exp, err := uc.exporterByFormat(ctx, logger, format, csvProfile)
+	if err != nil {
+		return err
+	}
+
+	selected, groups, err := uc.selectFacultySchedules(ctx, logger, facultyID, filter)
+	if err != nil {
+		return err
+	}
+
+	archive := exporter.NewArchiveWriter(dst, exporter.ArchiveManifest{
+		FacultyID:   faculty.ID,
+		FacultyName: faculty.Name,
+		Format:      format,
+		CreatedAt:   time.Now(),
+	})
+
+	_, calendarOnly := exp.(exporter.CalendarExporter)
+
+	for _, schedule := range selected {
+		scheduleLogger := logger.With("schedule_id", schedule.ID)
+
+		if err := ctx.Err(); err != nil {
+			return exportError(ctx, scheduleLogger, "Export schedule into archive error", err)
+		}
+
+		entry := exporter.ArchiveEntry{
+			ScheduleID:      schedule.ID,
+			ScheduleVersion: schedule.Version,
+			EduGroupID:      schedule.EduGroupID,
+			EduGroupNumber:  groups[schedule.EduGroupID].Number,
+			Semester:        schedule.Semester,
+		}
+
+		if calendarOnly && schedule.Type == schedules.ScheduleTypeCycled {
+			schedule, err = uc.cycledToCalendar(ctx, scheduleLogger, schedule)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = archive.Add(entry, func(w io.Writer) error {
+			return exp.Export(ctx, schedule, w)
+		})
+		if err != nil {
+			return exportError(ctx, scheduleLogger, "Export schedule into archive error", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		logger.Error("Close archive error", "error", err)
+		return execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return nil
+}
+
+// exporterByFormat returns exporter of format. Csv profile chooses layout of csv files, default layout is used when
+// profile is empty
+func (uc *ScheduleUsecase) exporterByFormat(ctx context.Context, logger *slog.Logger, format, csvProfile string) (exporter.Exporter, error) {
+	var exp exporter.Exporter
+	var err error
+
+	switch {
+	case csvProfile == "":
+		exp, err = uc.exporter.ByFormat(format)
+	case format == "csv":
+		exp, err = uc.exporter.ByCsvProfile(ctx, csvProfile)
+	default:
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("csv profile is not applicable to format %s", format))
+	}
+
+	if err != nil {
+		logger.Error("Get exporter by formate error", "error", err)
+		if errors.Is(err, exporter.ErrUnknownFormat) || errors.Is(err, exporter.ErrUnknownCsvProfile) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return exp, nil
+}
+
+// exportError logs failed export. Export stopped because client has gone is not an error of service
+func exportError(ctx context.Context, logger *slog.Logger, msg string, err error) error {
+	if ctx.Err() != nil {
+		logger.Info("Export cancelled", "error", err)
+		return execerror.NewExecError(execerror.TypeInternal, ctx.Err())
+	}
+
+	logger.Error(msg, "error", err)
+	return execerror.NewExecError(execerror.TypeInternal, nil)
+}
+
+// cycledToCalendar expands cycled schedule into dated items of semester. Calendar keeps id and version of cycled schedule
+func (uc *ScheduleUsecase) cycledToCalendar(ctx context.Context, logger *slog.Logger, schedule *schedules.Schedule) (*schedules.Schedule, error) {
+	group, err := uc.repo.GetEduGroup(ctx, schedule.EduGroupID)
+	if err != nil {
+		logger.Error("Get schedule edu group error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	educationStartDate, err := uc.calendarSvc.GetEducationStartDate(ctx, group, schedule.Semester)
+	if err != nil {
+		logger.Error("Get education start date error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	calendarSchedule, err := schedules.CalendarScheduleFromCycled(schedule.EduGroupID, schedule.Semester, schedule.Cycled, educationStartDate)
+	if err != nil {
+		logger.Error("Make calendar from cycled schedule error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+	}
+
+	calendarSchedule.ID = schedule.ID
+	calendarSchedule.Version = schedule.Version
+
+	return calendarSchedule, nil
+}
+
+type RemoveItemFromScheduleInput struct {
+	// ItemID addresses removed item. Item is searched by lesson when empty
+	ItemID       *uuid.UUID
+	Date         *time.Time
+	Weekday      *time.Weekday
+	LessonNumber int8
+	Subgroup     int8
+	Weektype     *int8
+	CycleWeeks   []int
+	Weeks        []int
+}
+
+// RemoveItemsFromSchedule returns new version of schedule
+func (uc *ScheduleUsecase) RemoveItemsFromSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, input []RemoveItemFromScheduleInput, user *users.User) (int, error) {
+	logger := uc.logger.With("schedule_id", scheduleID)
+
+	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	if err != nil {
+		logger.Error("Start transaction error", "error", err)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+	defer rollback(ctx)
+
+	repo := tx.(ScheduleUsecaseRepo)
+
+	schedule, err := repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		logger.Error("Get schedule error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
+		}
+
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
+		logger.Error("Check access to schedule error", "error", err)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return 0, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
+	}
+
+	if err := schedule.CheckVersion(version); err != nil {
+		return 0, scheduleVersionConflict(schedule.Version)
+	}
+
+	for _, item := range input {
+		if item.ItemID != nil {
+			err := schedule.RemoveItemByID(*item.ItemID)
+			if err != nil {
+				logger.Error("Remove item error", "error", err)
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
+
+			continue
+		}
+
+		switch schedule.Type {
+		case schedules.ScheduleTypeCycled:
+			if item.Weekday == nil {
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weekday"))
+			}
+
+			if item.Weektype == nil {
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weektype"))
+			}
+
+			err := schedule.Cycled.RemoveItem(*item.Weekday, item.LessonNumber, item.Subgroup, *item.Weektype, item.CycleWeeks, item.Weeks)
+			if err != nil {
+				logger.Error("Remove item error", "error", err)
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
+		case schedules.ScheduleTypeCalendar:
+			if item.Date == nil {
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing date"))
+			}
+
+			err := schedule.Calendar.RemoveItem(*item.Date, item.LessonNumber, item.Subgroup)
+			if err != nil {
+				logger.Error("Remove item error", "error", err)
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
+		}
+	}
+
+	err = repo.SaveSchedule(ctx, schedule)
+	if err != nil {
+		logger.Error("Save schedule error", "error", err)
+		if errors.Is(err, db.ErrorVersionConflict) {
+			return 0, scheduleVersionConflict(schedule.Version)
+		}
+
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	err = commit(ctx)
+	if err != nil {
+		logger.Error("Save updated schedule error", "error", err)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return schedule.Version, nil
+}
+
+type UpdateScheduleInput struct {
+	ID          uuid.UUID
+	Semester    *int
+	StartDate   *time.Time
+	EndDate     *time.Time
+	CycleLength *int
+	WorkingDays []time.Weekday
+	// Version expected version of schedule, check is skipped when empty
+	Version *int
+}
+
+type UpdateScheduleOutput GetScheduleOutput
+
+// UpdateSchedule
+func (uc *ScheduleUsecase) UpdateSchedule(ctx context.Context, input UpdateScheduleInput, user *users.User) (*UpdateScheduleOutput, error) {
+	logger := uc.logger.With("schedule_id", input.ID)
+
+	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	if err != nil {
+		logger.Error("Start transaction error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+	defer rollback(ctx)
+
+	repo := tx.(ScheduleUsecaseRepo)
+
+	schedule, err := repo.GetSchedule(ctx, input.ID)
+	if err != nil {
+		logger.Error("Get schedule error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
+		logger.Error("Check access to schedule error", "error", err)
 		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	} else if !ok {
 		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
 	}
 
+	if err := schedule.CheckVersion(input.Version); err != nil {
+		return nil, scheduleVersionConflict(schedule.Version)
+	}
+
 	group, err := uc.repo.GetEduGroup(ctx, schedule.EduGroupID)
 	if err != nil {
 		logger.Error("Get schedules group error", "error", err)
@@ -622,6 +1760,21 @@ func (uc *ScheduleUsecase) UpdateSchedule(ctx context.Context, input UpdateSched
 		schedule.Cycled.EndDate = *input.EndDate
 	}
 
+	if input.CycleLength != nil && schedule.Type == schedules.ScheduleTypeCycled {
+		schedule.Cycled.CycleLength = *input.CycleLength
+	}
+
+	if input.WorkingDays != nil {
+		workingWeek, err := schedules.NewWorkingWeek(input.WorkingDays...)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		if err := schedule.SetWorkingWeek(workingWeek); err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+	}
+
 	err = schedule.Validate(int(group.AdmissionYear), time.Now().Year())
 	if err != nil {
 		return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
@@ -630,6 +1783,10 @@ func (uc *ScheduleUsecase) UpdateSchedule(ctx context.Context, input UpdateSched
 	err = repo.SaveSchedule(ctx, schedule)
 	if err != nil {
 		logger.Error("Save schedule error", "error", err)
+		if errors.Is(err, db.ErrorVersionConflict) {
+			return nil, scheduleVersionConflict(schedule.Version)
+		}
+
 		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
@@ -646,14 +1803,14 @@ func (uc *ScheduleUsecase) UpdateSchedule(ctx context.Context, input UpdateSched
 	}, nil
 }
 
-// UpdateItemInSchedule
-func (uc *ScheduleUsecase) UpdateItemInSchedule(ctx context.Context, scheduleID uuid.UUID, input AddItemToScheduleInput, user *users.User) error {
+// UpdateItemInSchedule returns new version of schedule
+func (uc *ScheduleUsecase) UpdateItemInSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, input AddItemToScheduleInput, user *users.User) (int, error) {
 	logger := uc.logger.With("schedule_id", scheduleID)
 
 	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
 	if err != nil {
 		logger.Error("Start transaction error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 	defer rollback(ctx)
 
@@ -663,106 +1820,133 @@ func (uc *ScheduleUsecase) UpdateItemInSchedule(ctx context.Context, scheduleID
 	if err != nil {
 		logger.Error("Get schedule error", "error", err)
 		if errors.Is(err, db.ErrorNotFound) {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("schedule not found"))
 		}
 
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
 	if ok, err := uc.authSvc.HaveAccessToSchedule(ctx, schedule, user); err != nil {
 		logger.Error("Check access to schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	} else if !ok {
-		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
+		return 0, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
 	}
 
-	cabinet, err := repo.GetCabinet(ctx, input.CabinetID)
-	if err != nil {
-		logger.Error("Get cabinet error", "error", err)
-		if errors.Is(err, db.ErrorNotFound) {
-			return execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("cabinet %s not found", input.CabinetID))
-		}
-
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+	if err := schedule.CheckVersion(version); err != nil {
+		return 0, scheduleVersionConflict(schedule.Version)
 	}
 
-	cabinetValue := schedules.Cabinet{
-		Building:   cabinet.Building,
-		Auditorium: cabinet.Auditorium,
+	cabinetValue, err := resolveItemCabinet(ctx, repo, logger, input)
+	if err != nil {
+		return 0, err
 	}
 
+	var add func() error
+
 	switch schedule.Type {
 	case schedules.ScheduleTypeCycled:
 		if input.Weekday == nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weekday"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weekday"))
 		}
 
 		if input.Weektype == nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weektype"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weektype"))
 		}
 
-		err := schedule.Cycled.RemoveItem(*input.Weekday, input.LessonNumber, input.Subgroup, *input.Weektype)
-		if err != nil {
-			logger.Error("Remove item error", "error", err)
-			return execerror.NewExecError(execerror.TypeInvalidInput, err)
+		if input.ItemID == nil {
+			err = schedule.Cycled.RemoveItem(*input.Weekday, input.LessonNumber, input.Subgroup, *input.Weektype, input.CycleWeeks, input.Weeks)
+			if err != nil {
+				logger.Error("Remove item error", "error", err)
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
 		}
 
-		err = schedule.Cycled.AddItem(
-			input.Discipline,
-			input.TeacherID,
-			*input.Weekday,
-			input.StudentsCount,
-			input.LessonNumber,
-			input.Subgroup,
-			*input.Weektype,
-			input.LessonType,
-			cabinetValue,
-		)
+		add = func() error {
+			return schedule.Cycled.AddItem(
+				input.Discipline,
+				input.itemTeachers(),
+				*input.Weekday,
+				input.StudentsCount,
+				input.LessonNumber,
+				input.Duration,
+				input.Subgroup,
+				*input.Weektype,
+				input.CycleWeeks,
+				input.Weeks,
+				input.LessonType,
+				input.itemLocation(),
+				cabinetValue,
+			)
+		}
 	case schedules.ScheduleTypeCalendar:
 		if input.Date == nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing date"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing date"))
 		}
 
 		if input.Weeknum == nil {
-			return execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weeknum"))
+			return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("missing weeknum"))
 		}
 
-		err := schedule.Calendar.RemoveItem(*input.Date, input.LessonNumber, input.Subgroup)
-		if err != nil {
-			logger.Error("Remove item error", "error", err)
-			return execerror.NewExecError(execerror.TypeInvalidInput, err)
-		}
-
-		err = schedule.Calendar.AddItem(
-			input.Discipline,
-			input.TeacherID,
-			*input.Date,
-			input.StudentsCount,
-			input.LessonNumber,
-			input.Subgroup,
-			*input.Weeknum,
-			input.LessonType,
-			cabinetValue,
-		)
+		if input.ItemID == nil {
+			err = schedule.Calendar.RemoveItem(*input.Date, input.LessonNumber, input.Subgroup)
+			if err != nil {
+				logger.Error("Remove item error", "error", err)
+				return 0, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
+		}
+
+		add = func() error {
+			return schedule.Calendar.AddItem(
+				input.Discipline,
+				input.itemTeachers(),
+				*input.Date,
+				input.StudentsCount,
+				input.LessonNumber,
+				input.Duration,
+				input.Subgroup,
+				*input.Weeknum,
+				input.LessonType,
+				input.itemLocation(),
+				cabinetValue,
+			)
+		}
+	default:
+		return 0, execerror.NewExecError(execerror.TypeUnimpemented, errors.New("unknown schedule type"))
+	}
+
+	if input.ItemID != nil {
+		// changed item keeps its identity
+		err = schedule.UpdateItem(*input.ItemID, add)
+	} else {
+		err = add()
+	}
+
+	if err != nil {
+		return 0, execerror.NewExecError(execerror.TypeInvalidInput, err)
 	}
 
 	err = repo.SaveSchedule(ctx, schedule)
 	if err != nil {
 		logger.Error("Save schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		if errors.Is(err, db.ErrorVersionConflict) {
+			return 0, scheduleVersionConflict(schedule.Version)
+		}
+
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
 	err = commit(ctx)
 	if err != nil {
 		logger.Error("Save updated schedule error", "error", err)
-		return execerror.NewExecError(execerror.TypeInternal, nil)
+		return 0, execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
-	return nil
+	return schedule.Version, nil
 }
 
 // DeleteSchedule
-func (uc *ScheduleUsecase) DeleteSchedule(ctx context.Context, scheduleID uuid.UUID, user *users.User) error {
+func (uc *ScheduleUsecase) DeleteSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, user *users.User) error {
 	logger := uc.logger
 
 	schedule, err := uc.repo.GetSchedule(ctx, scheduleID)
@@ -782,42 +1966,56 @@ func (uc *ScheduleUsecase) DeleteSchedule(ctx context.Context, scheduleID uuid.U
 		return execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to schedule"))
 	}
 
-	err = uc.repo.DeleteSchedule(ctx, scheduleID)
+	if err := schedule.CheckVersion(version); err != nil {
+		return scheduleVersionConflict(schedule.Version)
+	}
+
+	err = uc.repo.DeleteSchedule(ctx, scheduleID, schedule.Version)
 	if err != nil {
 		logger.Error("Delete schedule error", "error", err)
+		if errors.Is(err, db.ErrorVersionConflict) {
+			return scheduleVersionConflict(schedule.Version)
+		}
+
 		return execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
 	return nil
 }
 
+// scheduleVersionConflict reports that schedule was changed by another user since it was read
+func scheduleVersionConflict(version int) error {
+	return execerror.NewExecError(execerror.TypeProcessingConflict, errors.New("schedule was modified by another user")).
+		AddDetails("version", strconv.Itoa(version))
+}
+
 func scheduleToCycledScheduleDTO(schedule *schedules.Schedule, teachersMap map[uuid.UUID]teachers.Teacher, withItems bool) (ScheduleDTO, error) {
 	var items []ScheduleItemDTO
 
 	if withItems {
 		for _, item := range schedule.Cycled.ListItem() {
-			t, ok := teachersMap[item.TeacherID]
-			if !ok {
-				return ScheduleDTO{}, fmt.Errorf("teacher with id %s for item %s not found", item.TeacherID, item.Discipline)
+			dto, err := newScheduleItemDTO(item, teachersMap)
+			if err != nil {
+				return ScheduleDTO{}, err
 			}
 
-			items = append(items, ScheduleItemDTO{
-				ScheduleItem: item,
-				TeacherName:  t.Name,
-			})
+			items = append(items, dto)
 		}
 	}
 
 	dto := ScheduleDTO{
-		ID:         schedule.ID,
-		Semester:   schedule.Semester,
-		EduGroupID: schedule.EduGroupID,
-		Items:      items,
+		ID:          schedule.ID,
+		Semester:    schedule.Semester,
+		EduGroupID:  schedule.EduGroupID,
+		WorkingDays: schedule.GetWorkingWeek().Days(),
+		Version:     schedule.Version,
+		Items:       items,
 	}
 
 	if schedule.Type == schedules.ScheduleTypeCycled {
 		dto.StartDate = &schedule.Cycled.StartDate
 		dto.EndDate = &schedule.Cycled.EndDate
+		dto.CycleLength = schedule.Cycled.CycleLength
 	}
 
 	return dto, nil
@@ -0,0 +1,483 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"schedule-generator/internal/application/acl/exporter"
+	"schedule-generator/internal/application/services"
+	"schedule-generator/internal/domain/cabinets"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/faculties"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"schedule-generator/internal/domain/users"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/pkg/execerror"
+
+	"github.com/google/uuid"
+)
+
+// scheduleRepoStub environment of groups, teachers and cabinets kept in memory, saved schedules are collected.
+// Methods not used by tests are left to embedded interface
+type scheduleRepoStub struct {
+	ScheduleUsecaseRepo
+	facultyID uuid.UUID
+	groups    map[uuid.UUID]edugroups.EduGroup
+	teachers  map[uuid.UUID]teachers.Teacher
+	cabinets  map[uuid.UUID]cabinets.Cabinet
+	saved     []*schedules.Schedule
+}
+
+func (r *scheduleRepoStub) GetEduGroup(ctx context.Context, id uuid.UUID) (*edugroups.EduGroup, error) {
+	group, ok := r.groups[id]
+	if !ok {
+		return nil, db.ErrorNotFound
+	}
+
+	return &group, nil
+}
+
+func (r *scheduleRepoStub) GetEduGroupByNumber(ctx context.Context, number string) (*edugroups.EduGroup, error) {
+	for _, g := range r.groups {
+		if g.Number == number {
+			return &g, nil
+		}
+	}
+
+	return nil, db.ErrorNotFound
+}
+
+func (r *scheduleRepoStub) GetFaculty(ctx context.Context, id uuid.UUID) (*faculties.Faculty, error) {
+	if id != r.facultyID {
+		return nil, db.ErrorNotFound
+	}
+
+	return &faculties.Faculty{ID: id, Name: "Факультет"}, nil
+}
+
+func (r *scheduleRepoStub) GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error) {
+	if _, ok := r.groups[groupID]; !ok {
+		return uuid.Nil, db.ErrorNotFound
+	}
+
+	return r.facultyID, nil
+}
+
+func (r *scheduleRepoStub) GetScheduleByEduGroupIDAndSemester(ctx context.Context, eduGroupID uuid.UUID, semester int) (*schedules.Schedule, error) {
+	for _, s := range r.saved {
+		if s.EduGroupID == eduGroupID && s.Semester == semester {
+			return s, nil
+		}
+	}
+
+	return nil, db.ErrorNotFound
+}
+
+func (r *scheduleRepoStub) GetTeacherByExternalID(ctx context.Context, externalID string) (*teachers.Teacher, error) {
+	for _, t := range r.teachers {
+		if t.ExternalID == externalID {
+			return &t, nil
+		}
+	}
+
+	return nil, db.ErrorNotFound
+}
+
+func (r *scheduleRepoStub) GetCabinetByAddress(ctx context.Context, building, auditorium string) (*cabinets.Cabinet, error) {
+	for _, c := range r.cabinets {
+		if c.Building == building && c.Auditorium == auditorium {
+			return &c, nil
+		}
+	}
+
+	return nil, db.ErrorNotFound
+}
+
+func (r *scheduleRepoStub) MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error) {
+	result := make(map[uuid.UUID]teachers.Teacher, len(teacherIDs))
+	for _, id := range teacherIDs {
+		if t, ok := r.teachers[id]; ok {
+			result[id] = t
+		}
+	}
+
+	return result, nil
+}
+
+func (r *scheduleRepoStub) SaveSchedule(ctx context.Context, schedule *schedules.Schedule) error {
+	r.saved = append(r.saved, schedule)
+	return nil
+}
+
+// AsTransaction returns the same repository, saved schedules are not rolled back
+func (r *scheduleRepoStub) AsTransaction(ctx context.Context, isoLevel db.IsoLevel) (db.TransactionalRepository, db.RollbackTxnFunc, db.CommitTxnFunc, error) {
+	noop := func(context.Context) error {
+		return nil
+	}
+
+	return r, noop, noop, nil
+}
+
+// authRepoStub places all groups into faculty of repository
+type authRepoStub struct {
+	services.AuthorizationServiceRepository
+	repo *scheduleRepoStub
+}
+
+func (r *authRepoStub) GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error) {
+	return r.repo.GetEduGroupFacultyID(ctx, groupID)
+}
+
+// exporterRepoStub serves exporters from repository
+type exporterRepoStub struct {
+	exporter.ExporterRepository
+	repo *scheduleRepoStub
+}
+
+func (r *exporterRepoStub) GetEduGroup(ctx context.Context, id uuid.UUID) (*edugroups.EduGroup, error) {
+	return r.repo.GetEduGroup(ctx, id)
+}
+
+func (r *exporterRepoStub) MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error) {
+	return r.repo.MapTeacherByIDs(ctx, teacherIDs)
+}
+
+// newTestEnvironment returns repository with group 101, teachers 1001 and 1002 and cabinet 101 of building 1.
+// Environments differ only by ids, so documents of one environment can be imported into another
+func newTestEnvironment() *scheduleRepoStub {
+	r := &scheduleRepoStub{
+		facultyID: uuid.New(),
+		groups:    make(map[uuid.UUID]edugroups.EduGroup),
+		teachers:  make(map[uuid.UUID]teachers.Teacher),
+		cabinets:  make(map[uuid.UUID]cabinets.Cabinet),
+	}
+
+	group := edugroups.EduGroup{ID: uuid.New(), Number: "101", AdmissionYear: 2025}
+	r.groups[group.ID] = group
+
+	for _, externalID := range []string{"1001", "1002"} {
+		t := teachers.Teacher{ID: uuid.New(), ExternalID: externalID, Name: "Преподаватель " + externalID}
+		r.teachers[t.ID] = t
+	}
+
+	cabinet := cabinets.Cabinet{ID: uuid.New(), FacultyID: r.facultyID, Building: "1", Auditorium: "101"}
+	r.cabinets[cabinet.ID] = cabinet
+
+	return r
+}
+
+func (r *scheduleRepoStub) group() edugroups.EduGroup {
+	for _, g := range r.groups {
+		return g
+	}
+
+	return edugroups.EduGroup{}
+}
+
+func (r *scheduleRepoStub) teacher(externalID string) uuid.UUID {
+	t, _ := r.GetTeacherByExternalID(context.Background(), externalID)
+	return t.ID
+}
+
+func (r *scheduleRepoStub) cabinet() schedules.Cabinet {
+	c, _ := r.GetCabinetByAddress(context.Background(), "1", "101")
+	return schedules.Cabinet{ID: c.ID, Building: c.Building, Auditorium: c.Auditorium}
+}
+
+func (r *scheduleRepoStub) usecase(t *testing.T) *ScheduleUsecase {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	exp, err := exporter.NewExporterFactory(&exporterRepoStub{repo: r}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewScheduleUsecase(services.NewAuthorizationService(&authRepoStub{repo: r}), nil, r, exp, logger)
+}
+
+// newTestSourceSchedule returns cycled schedule of source environment with items of all kinds of location,
+// assistant teacher, rotation and semester weeks
+func newTestSourceSchedule(t *testing.T, source *scheduleRepoStub) *schedules.Schedule {
+	t.Helper()
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(source.group().ID, 1, start, start.AddDate(0, 0, 27), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schedule.Cycled.SetCycleLength(2); err != nil {
+		t.Fatal(err)
+	}
+
+	main := []schedules.ItemTeacher{{TeacherID: source.teacher("1001")}}
+	withAssistant := []schedules.ItemTeacher{{TeacherID: source.teacher("1001")}, {TeacherID: source.teacher("1002"), Role: schedules.TeacherRoleAssistant}}
+
+	add := []func() error{
+		func() error {
+			return schedule.Cycled.AddItem("Математика", main, time.Monday, 25, 0, 0, 0, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLecture), schedules.Location{}, source.cabinet())
+		},
+		func() error {
+			return schedule.Cycled.AddItem("Программирование", withAssistant, time.Tuesday, 12, 1, 2, 1, int8(schedules.WeekTypeUneven), nil, []int{1, 3}, int8(schedules.ItemTypeLaboratory), schedules.Location{Kind: schedules.LocationKindOnline, MeetingURL: "https://meet.example.com/lab"}, schedules.Cabinet{})
+		},
+		func() error {
+			return schedule.Cycled.AddItem("Практика", main, time.Wednesday, 10, 2, 0, 0, int8(schedules.WeekTypeBoth), []int{2}, nil, int8(schedules.ItemTypePractice), schedules.Location{Kind: schedules.LocationKindExternal, Address: "ул. Ленина, 1"}, schedules.Cabinet{})
+		},
+	}
+
+	for _, f := range add {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return schedule
+}
+
+// normalizeItems replaces ids of items, teachers and cabinets of environment by references of document, so
+// items of different environments can be compared
+func normalizeItems(r *scheduleRepoStub, items []schedules.ScheduleItem) []schedules.ScheduleItem {
+	res := make([]schedules.ScheduleItem, len(items))
+	for i, item := range items {
+		item.ID = uuid.Nil
+
+		item.Teachers = append([]schedules.ItemTeacher{}, item.Teachers...)
+		for j, it := range item.Teachers {
+			item.Teachers[j].TeacherID = uuid.NewSHA1(uuid.Nil, []byte(r.teachers[it.TeacherID].ExternalID))
+		}
+
+		item.Cabinet.ID = uuid.Nil
+
+		// dated items are compared by dates only, weeks of cycled schedule they were expanded from are not kept
+		if item.Date != nil {
+			date := time.Date(item.Date.Year(), item.Date.Month(), item.Date.Day(), 0, 0, 0, 0, time.UTC)
+			item.Date = &date
+			item.Weektype, item.CycleWeeks, item.Weeks = nil, nil, nil
+		}
+
+		res[i] = item
+	}
+
+	return res
+}
+
+func TestScheduleUsecase_ImportSchedule_RoundTrip(t *testing.T) {
+	admin := &users.User{ID: uuid.New(), Role: users.RoleAdmin}
+
+	cases := map[string]func(t *testing.T, s *schedules.Schedule) *schedules.Schedule{
+		"cycled": func(t *testing.T, s *schedules.Schedule) *schedules.Schedule {
+			return s
+		},
+		"calendar": func(t *testing.T, s *schedules.Schedule) *schedules.Schedule {
+			calendar, err := schedules.CalendarScheduleFromCycled(s.EduGroupID, s.Semester, s.Cycled, s.Cycled.StartDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return calendar
+		},
+	}
+
+	for n, convert := range cases {
+		t.Run(n, func(t *testing.T) {
+			ctx := context.Background()
+			source, target := newTestEnvironment(), newTestEnvironment()
+
+			schedule := convert(t, newTestSourceSchedule(t, source))
+
+			exp, err := source.usecase(t).exporter.ByFormat("json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var doc bytes.Buffer
+			if err := exp.Export(ctx, schedule, &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Contains(doc.Bytes(), []byte(`"version": 1,`)) {
+				t.Fatalf("expected document of version 1, got: %s", doc.String())
+			}
+
+			out, err := target.usecase(t).ImportSchedule(ctx, ImportScheduleInput{EduGroupID: target.group().ID, Document: &doc}, admin)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(target.saved) != 1 || out.ID != target.saved[0].ID {
+				t.Fatalf("expected imported schedule to be saved, got: %d schedules", len(target.saved))
+			}
+
+			imported := target.saved[0]
+			if imported.EduGroupID != target.group().ID || imported.Semester != schedule.Semester || imported.Type != schedule.Type {
+				t.Errorf("unexpected imported schedule: %+v", imported)
+			}
+
+			if imported.GetWorkingWeek() != schedule.GetWorkingWeek() {
+				t.Errorf("expected working week %v, got: %v", schedule.GetWorkingWeek(), imported.GetWorkingWeek())
+			}
+
+			if schedule.Type == schedules.ScheduleTypeCycled {
+				// dates are written without time and read in default timezone
+				sameDate := func(a, b time.Time) bool {
+					return a.Format(time.DateOnly) == b.Format(time.DateOnly)
+				}
+
+				if !sameDate(imported.Cycled.StartDate, schedule.Cycled.StartDate) || !sameDate(imported.Cycled.EndDate, schedule.Cycled.EndDate) || imported.Cycled.CycleLength != schedule.Cycled.CycleLength {
+					t.Errorf("expected period %v-%v of cycle %d, got: %v-%v of cycle %d",
+						schedule.Cycled.StartDate, schedule.Cycled.EndDate, schedule.Cycled.CycleLength,
+						imported.Cycled.StartDate, imported.Cycled.EndDate, imported.Cycled.CycleLength)
+				}
+			}
+
+			// imported items reference teachers and cabinet of target environment
+			for _, item := range imported.ListItem() {
+				for _, it := range item.Teachers {
+					if _, ok := target.teachers[it.TeacherID]; !ok {
+						t.Errorf("expected teacher of target environment, got: %s", it.TeacherID)
+					}
+				}
+
+				if item.InCabinet() && item.Cabinet != target.cabinet() {
+					t.Errorf("expected cabinet of target environment, got: %+v", item.Cabinet)
+				}
+			}
+
+			want, got := normalizeItems(source, schedule.ListItem()), normalizeItems(target, imported.ListItem())
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("expected items %+v, got: %+v", want, got)
+			}
+
+			// repeated import of the same document conflicts with imported schedule
+			var repeated bytes.Buffer
+			if err := exp.Export(ctx, schedule, &repeated); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = target.usecase(t).ImportSchedule(ctx, ImportScheduleInput{EduGroupID: target.group().ID, Document: &repeated}, admin)
+
+			var execErr *execerror.ExecError
+			if !errors.As(err, &execErr) || execErr.Type != execerror.TypeProcessingConflict {
+				t.Errorf("expected processing conflict on repeated import, got: %v", err)
+			}
+
+			if len(target.saved) != 1 {
+				t.Errorf("expected no schedule to be saved on repeated import, got: %d schedules", len(target.saved))
+			}
+		})
+	}
+}
+
+func TestScheduleUsecase_ImportCycledCsv(t *testing.T) {
+	admin := &users.User{ID: uuid.New(), Role: users.RoleAdmin}
+
+	row := func(fields ...string) string {
+		return strings.Join(fields, ";")
+	}
+
+	header := row("Group", "Day", "Les", "Aud", "Week", "Subg", "Name", "Caf", "Subject", "Subj_Type", "Start", "End", "Subj_CafID", "PrepID")
+	lecture := row("101", "1", "1", "УК1-101", "", "", "Преподаватель 1001", "42", "Математика", "лек.", "-100", "-100", "0", "1001")
+	online := row("101", "2", "2", "ДОТ", "", "", "Преподаватель 1002", "42", "Программирование", "лаб.", "-100", "-100", "0", "1002")
+	unknownCabinet := row("101", "3", "1", "УК1-999", "", "", "Преподаватель 1001", "42", "Физика", "пр.", "-100", "-100", "0", "1001")
+	unknownTeacher := row("101", "4", "1", "УК1-101", "", "", "Преподаватель 9999", "42", "Химия", "лек.", "-100", "-100", "0", "9999")
+
+	input := func(dryRun bool, rows ...string) ImportCycledCsvInput {
+		return ImportCycledCsvInput{
+			Document:  strings.NewReader(strings.Join(append([]string{header}, rows...), "\n") + "\n"),
+			Semester:  1,
+			StartDate: time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2025, time.December, 28, 0, 0, 0, 0, time.UTC),
+			DryRun:    dryRun,
+		}
+	}
+
+	t.Run("dry run reports problems", func(t *testing.T) {
+		env := newTestEnvironment()
+
+		out, err := env.usecase(t).ImportCycledCsv(context.Background(), input(true, lecture, online, unknownCabinet, unknownTeacher), admin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(env.saved) != 0 {
+			t.Errorf("expected nothing to be saved on dry run, got: %d schedules", len(env.saved))
+		}
+
+		if len(out.Schedules) != 1 || out.Schedules[0].ItemsCount != 2 || out.Schedules[0].ScheduleID != uuid.Nil {
+			t.Errorf("expected unsaved schedule of lecture and online lesson, got: %+v", out.Schedules)
+		}
+
+		if !slices.Equal(out.UnresolvedCabinets, []string{"УК1-999"}) || !slices.Equal(out.UnresolvedTeachers, []string{"9999"}) {
+			t.Errorf("expected unresolved cabinet and teacher, got: %v and %v", out.UnresolvedCabinets, out.UnresolvedTeachers)
+		}
+
+		if len(out.Errors) != 0 {
+			t.Errorf("expected no line errors, got: %+v", out.Errors)
+		}
+
+		if !slices.Equal(out.PlaceholderMeetingURLLines, []int{3}) {
+			t.Errorf("expected online lesson of line 3 with placeholder url, got: %v", out.PlaceholderMeetingURLLines)
+		}
+	})
+
+	t.Run("import with unresolved references fails", func(t *testing.T) {
+		env := newTestEnvironment()
+
+		_, err := env.usecase(t).ImportCycledCsv(context.Background(), input(false, lecture, online, unknownTeacher), admin)
+
+		var execErr *execerror.ExecError
+		if !errors.As(err, &execErr) || execErr.Type != execerror.TypeInvalidInput {
+			t.Fatalf("expected invalid input error, got: %v", err)
+		}
+
+		if !slices.Equal(execErr.Details["unresolved_teachers"], []string{"9999"}) {
+			t.Errorf("expected unresolved teacher in details, got: %v", execErr.Details)
+		}
+
+		if len(env.saved) != 0 {
+			t.Errorf("expected nothing to be saved, got: %d schedules", len(env.saved))
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		env := newTestEnvironment()
+
+		out, err := env.usecase(t).ImportCycledCsv(context.Background(), input(false, lecture, online), admin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(env.saved) != 1 || out.Schedules[0].ScheduleID != env.saved[0].ID {
+			t.Fatalf("expected imported schedule to be saved, got: %d schedules", len(env.saved))
+		}
+
+		items := env.saved[0].ListItem()
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items, got: %+v", items)
+		}
+
+		for _, item := range items {
+			switch item.Discipline {
+			case "Математика":
+				if item.Cabinet != env.cabinet() || item.MainTeacherID() != env.teacher("1001") {
+					t.Errorf("expected lecture in cabinet of environment, got: %+v", item)
+				}
+			case "Программирование":
+				if item.Location.Kind != schedules.LocationKindOnline || item.Location.MeetingURL != ImportedMeetingURL {
+					t.Errorf("expected online lesson with placeholder url, got: %+v", item.Location)
+				}
+			}
+		}
+	})
+}
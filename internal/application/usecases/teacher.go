@@ -203,6 +203,10 @@ func (uc *TeacherUsecase) DeleteTeacher(ctx context.Context, teacherID uuid.UUID
 	err := uc.repo.DeleteTeacher(ctx, teacherID)
 	if err != nil {
 		logger.Error("Delete teacher error", "error", err)
+		if errors.Is(err, db.ErrorAssociationViolation) {
+			return execerror.NewExecError(execerror.TypeProcessingConflict, errors.New("teacher is used in schedules"))
+		}
+
 		return execerror.NewExecError(execerror.TypeInternal, nil)
 	}
 
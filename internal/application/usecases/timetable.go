@@ -0,0 +1,1046 @@
+package usecases
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"schedule-generator/internal/application/acl/exporter"
+	"schedule-generator/internal/application/services"
+	"schedule-generator/internal/domain/cabinets"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"schedule-generator/internal/domain/users"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/pkg/execerror"
+
+	"github.com/google/uuid"
+)
+
+// MaxTimetableRangeDays limits dated timetable range
+const MaxTimetableRangeDays = 366
+
+type TimetableView int8
+
+const (
+	TimetableViewGrid TimetableView = iota
+	TimetableViewDated
+)
+
+var timetableViewNames = []string{
+	"grid",
+	"dated",
+}
+
+func (v TimetableView) String() string {
+	return timetableViewNames[v]
+}
+
+type TimetableUsecaseRepo interface {
+	teachers.Repository
+	edugroups.Repository
+	cabinets.Repository
+
+	ListScheduleByTeacher(ctx context.Context, teacherID uuid.UUID) ([]schedules.Schedule, error)
+	ListScheduleByCabinet(ctx context.Context, cabinetID uuid.UUID) ([]schedules.Schedule, error)
+	ListScheduleBySlot(ctx context.Context, weekday time.Weekday, lessonNumber int8) ([]schedules.Schedule, error)
+	ListScheduleByEduGroup(ctx context.Context, groupID uuid.UUID) ([]schedules.Schedule, error)
+	MapEduGroupsBySchedules(ctx context.Context, scheduleIDs uuid.UUIDs) (map[uuid.UUID]edugroups.EduGroup, error)
+	MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error)
+}
+
+type TimetableUsecase struct {
+	repo        TimetableUsecaseRepo
+	authSvc     *services.AuthorizationService
+	calendarSvc *services.AcademicCalendarService
+	exporter    exporter.Factory
+	logger      *slog.Logger
+}
+
+func NewTimetableUsecase(authSvc *services.AuthorizationService, calendarSvc *services.AcademicCalendarService, repo TimetableUsecaseRepo, exporter exporter.Factory, logger *slog.Logger) *TimetableUsecase {
+	return &TimetableUsecase{
+		repo:        repo,
+		authSvc:     authSvc,
+		calendarSvc: calendarSvc,
+		exporter:    exporter,
+		logger:      logger,
+	}
+}
+
+type TimetableItemDTO struct {
+	ScheduleItemDTO
+	ScheduleID     uuid.UUID
+	EduGroupID     uuid.UUID
+	EduGroupNumber string
+	// CycleLength of cycled schedule of item, zero for dated items
+	CycleLength int
+}
+
+// TimetableSlotDTO describes one lesson slot. Weektype is set for grid view only
+type TimetableSlotDTO struct {
+	LessonNumber int8
+	Weektype     *schedules.Weektype
+	Items        []TimetableItemDTO
+}
+
+func (s TimetableSlotDTO) Occupied() bool {
+	return len(s.Items) > 0
+}
+
+type TimetableDayDTO struct {
+	Weekday time.Weekday
+	Date    *time.Time
+	Items   []TimetableItemDTO
+	Slots   []TimetableSlotDTO
+}
+
+type TimetableDTO struct {
+	View TimetableView
+	From *time.Time
+	To   *time.Time
+	Days []TimetableDayDTO
+}
+
+// TimetableInput selects timetable view. Dated view is used when both From and To are set,
+// otherwise weekly grid of cycled schedules active on Date (today by default) is built
+type TimetableInput struct {
+	Date *time.Time
+	From *time.Time
+	To   *time.Time
+}
+
+type GetTeacherTimetableInput struct {
+	TimetableInput
+	TeacherID uuid.UUID
+}
+
+type GetTeacherTimetableOutput struct {
+	TimetableDTO
+	Teacher teachers.Teacher
+}
+
+// GetTeacherTimetable
+func (uc *TimetableUsecase) GetTeacherTimetable(ctx context.Context, input GetTeacherTimetableInput, user *users.User) (*GetTeacherTimetableOutput, error) {
+	logger := uc.logger.With("teacher_id", input.TeacherID)
+
+	teacher, err := uc.repo.GetTeacher(ctx, input.TeacherID)
+	if err != nil {
+		logger.Error("Get teacher error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("teacher not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToTeacher(ctx, teacher, user); err != nil {
+		logger.Error("Check access to teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to teacher"))
+	}
+
+	list, err := uc.repo.ListScheduleByTeacher(ctx, teacher.ID)
+	if err != nil {
+		logger.Error("List schedule by teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	dto, err := uc.buildTimetable(ctx, logger, list, input.TimetableInput, func(item schedules.ScheduleItem) bool {
+		return item.HasTeacher(teacher.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetTeacherTimetableOutput{
+		TimetableDTO: *dto,
+		Teacher:      *teacher,
+	}, nil
+}
+
+type GetCabinetTimetableInput struct {
+	TimetableInput
+	CabinetID uuid.UUID
+}
+
+type GetCabinetTimetableOutput struct {
+	TimetableDTO
+	Cabinet cabinets.Cabinet
+}
+
+// GetCabinetTimetable returns lessons booked into cabinet with free and occupied slots
+func (uc *TimetableUsecase) GetCabinetTimetable(ctx context.Context, input GetCabinetTimetableInput, user *users.User) (*GetCabinetTimetableOutput, error) {
+	logger := uc.logger.With("cabinet_id", input.CabinetID)
+
+	cabinet, err := uc.repo.GetCabinet(ctx, input.CabinetID)
+	if err != nil {
+		logger.Error("Get cabinet error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("cabinet not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToCabinet(ctx, cabinet, user); err != nil {
+		logger.Error("Check access to cabinet error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to cabinet"))
+	}
+
+	list, err := uc.repo.ListScheduleByCabinet(ctx, cabinet.ID)
+	if err != nil {
+		logger.Error("List schedule by cabinet error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	dto, err := uc.buildTimetable(ctx, logger, list, input.TimetableInput, func(item schedules.ScheduleItem) bool {
+		return item.InCabinet() && item.Cabinet.ID == cabinet.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fillTimetableSlots(dto)
+
+	return &GetCabinetTimetableOutput{
+		TimetableDTO: *dto,
+		Cabinet:      *cabinet,
+	}, nil
+}
+
+// ExportTeacherTimetable
+func (uc *TimetableUsecase) ExportTeacherTimetable(ctx context.Context, input GetTeacherTimetableInput, format string, dst io.Writer, user *users.User) error {
+	logger := uc.logger.With("teacher_id", input.TeacherID)
+
+	out, err := uc.GetTeacherTimetable(ctx, input, user)
+	if err != nil {
+		return err
+	}
+
+	title := "Преподаватель: " + out.Teacher.Name
+	if out.Teacher.Position != "" {
+		title += ", " + out.Teacher.Position
+	}
+
+	return uc.exportTimetable(ctx, logger, title, &out.TimetableDTO, input.TimetableInput, format, dst)
+}
+
+// ExportCabinetTimetable
+func (uc *TimetableUsecase) ExportCabinetTimetable(ctx context.Context, input GetCabinetTimetableInput, format string, dst io.Writer, user *users.User) error {
+	logger := uc.logger.With("cabinet_id", input.CabinetID)
+
+	out, err := uc.GetCabinetTimetable(ctx, input, user)
+	if err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("Аудитория %s, корпус %s", out.Cabinet.Auditorium, out.Cabinet.Building)
+
+	return uc.exportTimetable(ctx, logger, title, &out.TimetableDTO, input.TimetableInput, format, dst)
+}
+
+func (uc *TimetableUsecase) exportTimetable(
+	ctx context.Context,
+	logger *slog.Logger,
+	title string,
+	dto *TimetableDTO,
+	input TimetableInput,
+	format string,
+	dst io.Writer,
+) error {
+	exp, err := uc.exporter.ByFormat(format)
+	if err != nil {
+		logger.Error("Get exporter by formate error", "error", err)
+		if errors.Is(err, exporter.ErrUnknownFormat) {
+			return execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		return execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	timetableExp, ok := exp.(exporter.TimetableExporter)
+	if !ok {
+		return execerror.NewExecError(execerror.TypeInvalidInput, fmt.Errorf("format %s does not support timetable export", format))
+	}
+
+	timetable := exporter.Timetable{Title: title}
+
+	switch dto.View {
+	case TimetableViewGrid:
+		date := time.Now()
+		if input.Date != nil {
+			date = *input.Date
+		}
+
+		timetable.Details = []string{"Расписание на неделю, действующее на " + date.Format("02.01.2006")}
+	case TimetableViewDated:
+		timetable.Details = []string{"Период: " + dto.From.Format("02.01.2006") + " – " + dto.To.Format("02.01.2006")}
+	}
+
+	for _, day := range dto.Days {
+		exportDay := exporter.TimetableDay{Weekday: day.Weekday, Date: day.Date}
+
+		for _, item := range day.Items {
+			names := make([]string, len(item.TeacherNames))
+			for i, t := range item.TeacherNames {
+				names[i] = t.Name
+			}
+
+			exportDay.Lessons = append(exportDay.Lessons, exporter.TimetableLesson{
+				ScheduleItem:   item.ScheduleItem,
+				EduGroupNumber: item.EduGroupNumber,
+				TeacherNames:   names,
+				CycleLength:    item.CycleLength,
+			})
+		}
+
+		timetable.Days = append(timetable.Days, exportDay)
+	}
+
+	if err := timetableExp.ExportTimetable(ctx, &timetable, dst); err != nil {
+		return exportError(ctx, logger, "Export timetable error", err)
+	}
+
+	return nil
+}
+
+// SearchFreeCabinetsInput describes searched slot. Slot is defined either by Weekday and Weektype
+// of cycled schedules or by Date
+type SearchFreeCabinetsInput struct {
+	FacultyID                          *uuid.UUID
+	Weekday                            *time.Weekday
+	Weektype                           *int8
+	Date                               *time.Time
+	LessonNumber                       int8
+	CabinetType                        *int8
+	MinCapacity                        *int
+	SuitableForPeoplesWithSpecialNeeds *bool
+	Equipment                          []string
+}
+
+type SearchFreeCabinetsOutput = []cabinets.Cabinet
+
+// SearchFreeCabinets returns faculty cabinets which are not occupied in specified slot
+func (uc *TimetableUsecase) SearchFreeCabinets(ctx context.Context, input SearchFreeCabinetsInput, user *users.User) (SearchFreeCabinetsOutput, error) {
+	logger := uc.logger
+
+	if input.LessonNumber < 0 {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("invalid lesson number"))
+	}
+
+	if (input.Date == nil) == (input.Weekday == nil) {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("either weekday or date must be provided"))
+	}
+
+	var weekday time.Weekday
+	if input.Date != nil {
+		weekday = input.Date.Weekday()
+	} else {
+		weekday = *input.Weekday
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("invalid weekday"))
+		}
+	}
+
+	filter := cabinets.Filter{
+		MinCapacity:                        input.MinCapacity,
+		SuitableForPeoplesWithSpecialNeeds: input.SuitableForPeoplesWithSpecialNeeds,
+		Equipment:                          input.Equipment,
+	}
+
+	if input.CabinetType != nil {
+		cabinetType, err := cabinets.NewCabinetType(*input.CabinetType)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		filter.Type = &cabinetType
+	}
+
+	weektype := schedules.WeekTypeBoth
+	if input.Weektype != nil {
+		wt, err := schedules.NewWeekType(*input.Weektype)
+		if err != nil {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+		}
+
+		weektype = wt
+	}
+
+	facultyID := input.FacultyID
+	if !uc.authSvc.IsAdmin(user) {
+		if user.FacultyID == nil {
+			return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user not accociated with any faculty"))
+		}
+
+		if facultyID != nil && *facultyID != *user.FacultyID {
+			return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to faculty"))
+		}
+
+		facultyID = user.FacultyID
+	}
+
+	var list []cabinets.Cabinet
+	var listErr error
+
+	if facultyID != nil {
+		list, listErr = uc.repo.ListCabinetByFaculty(ctx, *facultyID)
+	} else {
+		list, listErr = uc.repo.ListCabinet(ctx)
+	}
+
+	if listErr != nil {
+		logger.Error("List cabinet error", "error", listErr)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	scheduleList, err := uc.repo.ListScheduleBySlot(ctx, weekday, input.LessonNumber)
+	if err != nil {
+		logger.Error("List schedule by slot error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	occupied := make(map[uuid.UUID]struct{})
+
+	if input.Date != nil {
+		items, err := uc.listScheduleItemsByDate(ctx, logger, scheduleList, *input.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			if item.InCabinet() && item.CoversLesson(input.LessonNumber) {
+				occupied[item.Cabinet.ID] = struct{}{}
+			}
+		}
+	} else {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		for _, schedule := range scheduleList {
+			var items []schedules.ScheduleItem
+
+			switch {
+			case schedule.Type == schedules.ScheduleTypeCycled && schedule.Cycled != nil && !schedule.Cycled.EndDate.Before(now):
+				// items following cycle weeks instead of odd and even weeks may take place on any week
+				for _, item := range schedule.Cycled.ListItemByWeekday(weekday) {
+					if item.Weektype == nil || item.Weektype.Overlaps(weektype) {
+						items = append(items, item)
+					}
+				}
+			case schedule.Type == schedules.ScheduleTypeCalendar && schedule.Calendar != nil:
+				// dated lessons occupy weekday slot until they are passed
+				for _, item := range schedule.Calendar.ListItem() {
+					if item.Date == nil || item.Date.Before(today) || item.Date.Weekday() != weekday {
+						continue
+					}
+
+					if item.Weeknum == nil || schedules.WeektypeOfWeek(*item.Weeknum).Overlaps(weektype) {
+						items = append(items, item)
+					}
+				}
+			}
+
+			for _, item := range items {
+				if item.InCabinet() && item.CoversLesson(input.LessonNumber) {
+					occupied[item.Cabinet.ID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	result := make(SearchFreeCabinetsOutput, 0, len(list))
+	for _, cabinet := range list {
+		if !filter.Match(&cabinet) {
+			continue
+		}
+
+		if _, ok := occupied[cabinet.ID]; ok {
+			continue
+		}
+
+		result = append(result, cabinet)
+	}
+
+	return result, nil
+}
+
+// listScheduleItemsByDate returns items of all provided schedules for specified date
+func (uc *TimetableUsecase) listScheduleItemsByDate(ctx context.Context, logger *slog.Logger, list []schedules.Schedule, date time.Time) ([]schedules.ScheduleItem, error) {
+	scheduleIDs := make(uuid.UUIDs, len(list))
+	for i, schedule := range list {
+		scheduleIDs[i] = schedule.ID
+	}
+
+	groups, err := uc.repo.MapEduGroupsBySchedules(ctx, scheduleIDs)
+	if err != nil {
+		logger.Error("Map edu groups by schedules error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	scheduleSvc := schedules.NewScheduleService()
+
+	var result []schedules.ScheduleItem
+	for _, schedule := range list {
+		group, ok := groups[schedule.EduGroupID]
+		if !ok {
+			logger.Error("Edu group for schedule not found", "schedule_id", schedule.ID)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		educationStartDate, err := uc.calendarSvc.GetEducationStartDate(ctx, &group, schedule.Semester)
+		if err != nil {
+			logger.Error("Get education start date error", "error", err, "schedule_id", schedule.ID)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		items, err := scheduleSvc.ListScheduleItemByDateRange(&schedule, educationStartDate, date, date)
+		if err != nil {
+			logger.Error("List schedule items by date error", "error", err, "schedule_id", schedule.ID)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		result = append(result, items...)
+	}
+
+	return result, nil
+}
+
+type FindFreeSlotsInput struct {
+	TimetableInput
+	EduGroupID uuid.UUID
+	Subgroup   int8
+	TeacherID  uuid.UUID
+	CabinetID  *uuid.UUID
+}
+
+type FreeSlotDTO struct {
+	schedules.Slot
+	Score int
+}
+
+type FindFreeSlotsOutput struct {
+	View  TimetableView
+	Slots []FreeSlotDTO
+}
+
+// FindFreeSlots returns slots where edu group (subgroup), teacher and optionally cabinet are free at the same time.
+// Slots are ranked by how well they fit into existing group day structure
+func (uc *TimetableUsecase) FindFreeSlots(ctx context.Context, input FindFreeSlotsInput, user *users.User) (*FindFreeSlotsOutput, error) {
+	logger := uc.logger.With("edu_group_id", input.EduGroupID, "teacher_id", input.TeacherID)
+
+	if input.Subgroup < 0 {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("invalid subgroup"))
+	}
+
+	view, err := timetableViewByInput(input.TimetableInput)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := uc.repo.GetEduGroup(ctx, input.EduGroupID)
+	if err != nil {
+		logger.Error("Get edu group error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("edu group not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToEduGroup(ctx, group, user); err != nil {
+		logger.Error("Check access to group error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to edu group"))
+	}
+
+	teacher, err := uc.repo.GetTeacher(ctx, input.TeacherID)
+	if err != nil {
+		logger.Error("Get teacher error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("teacher not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToTeacher(ctx, teacher, user); err != nil {
+		logger.Error("Check access to teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to teacher"))
+	}
+
+	groupSchedules, err := uc.repo.ListScheduleByEduGroup(ctx, group.ID)
+	if err != nil {
+		logger.Error("List schedule by edu group error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	groupItems, err := uc.collectTimetableItems(ctx, logger, groupSchedules, view, input.TimetableInput, func(item schedules.ScheduleItem) bool {
+		return item.Subgroup == 0 || input.Subgroup == 0 || item.Subgroup == input.Subgroup
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	teacherSchedules, err := uc.repo.ListScheduleByTeacher(ctx, teacher.ID)
+	if err != nil {
+		logger.Error("List schedule by teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	busyItems, err := uc.collectTimetableItems(ctx, logger, teacherSchedules, view, input.TimetableInput, func(item schedules.ScheduleItem) bool {
+		return item.HasTeacher(teacher.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	busyItems = append(busyItems, groupItems...)
+
+	if input.CabinetID != nil {
+		cabinet, err := uc.repo.GetCabinet(ctx, *input.CabinetID)
+		if err != nil {
+			logger.Error("Get cabinet error", "error", err)
+			if errors.Is(err, db.ErrorNotFound) {
+				return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("cabinet not found"))
+			}
+
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		if ok, err := uc.authSvc.HaveAccessToCabinet(ctx, cabinet, user); err != nil {
+			logger.Error("Check access to cabinet error", "error", err)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		} else if !ok {
+			return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to cabinet"))
+		}
+
+		cabinetSchedules, err := uc.repo.ListScheduleByCabinet(ctx, cabinet.ID)
+		if err != nil {
+			logger.Error("List schedule by cabinet error", "error", err)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		cabinetItems, err := uc.collectTimetableItems(ctx, logger, cabinetSchedules, view, input.TimetableInput, func(item schedules.ScheduleItem) bool {
+			return item.InCabinet() && item.Cabinet.ID == cabinet.ID
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		busyItems = append(busyItems, cabinetItems...)
+	}
+
+	var slots []FreeSlotDTO
+
+	switch view {
+	case TimetableViewGrid:
+		slots = findFreeGridSlots(groupItems, busyItems, workingWeek(groupSchedules))
+	case TimetableViewDated:
+		slots = findFreeDatedSlots(groupItems, busyItems, workingWeek(groupSchedules), *input.From, *input.To)
+	}
+
+	slices.SortStableFunc(slots, func(a, b FreeSlotDTO) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return &FindFreeSlotsOutput{
+		View:  view,
+		Slots: slots,
+	}, nil
+}
+
+// findFreeGridSlots returns free cycled slots on working days. Slot free on both weeks is returned once with both weektype
+func findFreeGridSlots(groupItems, busyItems []TimetableItemDTO, week schedules.WorkingWeek) []FreeSlotDTO {
+	scheduleSvc := schedules.NewScheduleService()
+
+	dayLessons := func(items []TimetableItemDTO, weekday time.Weekday, wt schedules.Weektype) []int8 {
+		var lessons []int8
+		for _, item := range items {
+			if item.Weekday == weekday && item.Weektype != nil && item.Weektype.Overlaps(wt) {
+				lessons = append(lessons, item.LessonNumbers()...)
+			}
+		}
+
+		return lessons
+	}
+
+	var slots []FreeSlotDTO
+	for _, d := range week.Days() {
+		for n := int8(0); n < schedules.LessonsPerDay; n++ {
+			var free []schedules.Weektype
+
+			for _, wt := range []schedules.Weektype{schedules.WeekTypeUneven, schedules.WeekTypeEven} {
+				if !slices.Contains(dayLessons(busyItems, d, wt), n) {
+					free = append(free, wt)
+				}
+			}
+
+			if len(free) == 2 {
+				free = []schedules.Weektype{schedules.WeekTypeBoth}
+			}
+
+			for _, wt := range free {
+				slots = append(slots, FreeSlotDTO{
+					Slot: schedules.Slot{
+						Weekday:      d,
+						LessonNumber: n,
+						Weektype:     &wt,
+					},
+					Score: scheduleSvc.RankSlot(dayLessons(groupItems, d, wt), n),
+				})
+			}
+		}
+	}
+
+	return slots
+}
+
+// findFreeDatedSlots returns free slots for each working date in range
+func findFreeDatedSlots(groupItems, busyItems []TimetableItemDTO, week schedules.WorkingWeek, from, to time.Time) []FreeSlotDTO {
+	scheduleSvc := schedules.NewScheduleService()
+
+	dayLessons := func(items []TimetableItemDTO, date time.Time) []int8 {
+		var lessons []int8
+		for _, item := range items {
+			if item.Date == nil {
+				continue
+			}
+
+			iy, im, id := item.Date.In(date.Location()).Date()
+			if iy == date.Year() && im == date.Month() && id == date.Day() {
+				lessons = append(lessons, item.LessonNumbers()...)
+			}
+		}
+
+		return lessons
+	}
+
+	y, m, d := from.Date()
+	from = time.Date(y, m, d, 0, 0, 0, 0, from.Location())
+
+	var slots []FreeSlotDTO
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if !week.Contains(date.Weekday()) {
+			continue
+		}
+
+		busy := dayLessons(busyItems, date)
+		lessons := dayLessons(groupItems, date)
+
+		for n := int8(0); n < schedules.LessonsPerDay; n++ {
+			if slices.Contains(busy, n) {
+				continue
+			}
+
+			slots = append(slots, FreeSlotDTO{
+				Slot: schedules.Slot{
+					Weekday:      date.Weekday(),
+					LessonNumber: n,
+					Date:         &date,
+				},
+				Score: scheduleSvc.RankSlot(lessons, n),
+			})
+		}
+	}
+
+	return slots
+}
+
+// buildTimetable collects items matched by filter from all provided schedules
+func (uc *TimetableUsecase) buildTimetable(
+	ctx context.Context,
+	logger *slog.Logger,
+	list []schedules.Schedule,
+	input TimetableInput,
+	filter func(item schedules.ScheduleItem) bool,
+) (*TimetableDTO, error) {
+	view, err := timetableViewByInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := uc.collectTimetableItems(ctx, logger, list, view, input, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.resolveTimetableTeachers(ctx, items); err != nil {
+		logger.Error("Resolve timetable teachers error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	dto := TimetableDTO{
+		View: view,
+		From: input.From,
+		To:   input.To,
+	}
+
+	switch view {
+	case TimetableViewGrid:
+		dto.Days = groupTimetableItemsByWeekday(items, workingWeek(list))
+	case TimetableViewDated:
+		dto.Days = groupTimetableItemsByDate(items, workingWeek(list), *input.From, *input.To)
+	}
+
+	return &dto, nil
+}
+
+func timetableViewByInput(input TimetableInput) (TimetableView, error) {
+	if input.From == nil && input.To == nil {
+		return TimetableViewGrid, nil
+	}
+
+	if input.From == nil || input.To == nil {
+		return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("both from and to dates must be provided"))
+	}
+
+	if input.From.After(*input.To) {
+		return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("from date is after to date"))
+	}
+
+	if input.To.Sub(*input.From) > MaxTimetableRangeDays*24*time.Hour {
+		return 0, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("date range is too long"))
+	}
+
+	return TimetableViewDated, nil
+}
+
+// collectTimetableItems returns items matched by filter. Grid view contains items of cycled schedules active on input date,
+// dated view contains items of all schedules expanded to input date range
+func (uc *TimetableUsecase) collectTimetableItems(
+	ctx context.Context,
+	logger *slog.Logger,
+	list []schedules.Schedule,
+	view TimetableView,
+	input TimetableInput,
+	filter func(item schedules.ScheduleItem) bool,
+) ([]TimetableItemDTO, error) {
+	scheduleIDs := make(uuid.UUIDs, len(list))
+	for i, schedule := range list {
+		scheduleIDs[i] = schedule.ID
+	}
+
+	groups, err := uc.repo.MapEduGroupsBySchedules(ctx, scheduleIDs)
+	if err != nil {
+		logger.Error("Map edu groups by schedules error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	scheduleSvc := schedules.NewScheduleService()
+
+	var items []TimetableItemDTO
+	for _, schedule := range list {
+		group, ok := groups[schedule.EduGroupID]
+		if !ok {
+			logger.Error("Edu group for schedule not found", "schedule_id", schedule.ID)
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		var scheduleItems []schedules.ScheduleItem
+
+		switch view {
+		case TimetableViewGrid:
+			if schedule.Type != schedules.ScheduleTypeCycled || schedule.Cycled == nil {
+				continue
+			}
+
+			date := time.Now()
+			if input.Date != nil {
+				date = *input.Date
+			}
+
+			if date.Before(schedule.Cycled.StartDate) || date.After(schedule.Cycled.EndDate.AddDate(0, 0, 1)) {
+				continue
+			}
+
+			scheduleItems = schedule.Cycled.ListItem()
+		case TimetableViewDated:
+			educationStartDate, err := uc.calendarSvc.GetEducationStartDate(ctx, &group, schedule.Semester)
+			if err != nil {
+				logger.Error("Get education start date error", "error", err, "schedule_id", schedule.ID)
+				return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+			}
+
+			scheduleItems, err = scheduleSvc.ListScheduleItemByDateRange(&schedule, educationStartDate, *input.From, *input.To)
+			if err != nil {
+				logger.Error("List schedule items by date range error", "error", err, "schedule_id", schedule.ID)
+				return nil, execerror.NewExecError(execerror.TypeInvalidInput, err)
+			}
+		}
+
+		var cycleLength int
+		if view == TimetableViewGrid {
+			cycleLength = schedule.Cycled.CycleLength
+		}
+
+		for _, item := range scheduleItems {
+			if !filter(item) {
+				continue
+			}
+
+			items = append(items, TimetableItemDTO{
+				ScheduleItemDTO: ScheduleItemDTO{ScheduleItem: item},
+				ScheduleID:      schedule.ID,
+				EduGroupID:      group.ID,
+				EduGroupNumber:  group.Number,
+				CycleLength:     cycleLength,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+func (uc *TimetableUsecase) resolveTimetableTeachers(ctx context.Context, items []TimetableItemDTO) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	scheduleItems := make([]schedules.ScheduleItem, len(items))
+	for i, item := range items {
+		scheduleItems[i] = item.ScheduleItem
+	}
+
+	teachersMap, err := uc.repo.MapTeacherByIDs(ctx, collectTeacherIDs(scheduleItems))
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		items[i].ScheduleItemDTO, err = newScheduleItemDTO(items[i].ScheduleItem, teachersMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// workingWeek returns union of working weeks of schedules or default week when list is empty
+func workingWeek(list []schedules.Schedule) schedules.WorkingWeek {
+	var week schedules.WorkingWeek
+	for i := range list {
+		week = week.Union(list[i].GetWorkingWeek())
+	}
+
+	return week.OrDefault()
+}
+
+func groupTimetableItemsByWeekday(items []TimetableItemDTO, week schedules.WorkingWeek) []TimetableDayDTO {
+	days := make([]TimetableDayDTO, 0, 7)
+	for _, d := range week.Days() {
+		day := TimetableDayDTO{Weekday: d}
+
+		for _, item := range items {
+			if item.Weekday == d {
+				day.Items = append(day.Items, item)
+			}
+		}
+
+		sortTimetableItems(day.Items)
+		days = append(days, day)
+	}
+
+	return days
+}
+
+func groupTimetableItemsByDate(items []TimetableItemDTO, week schedules.WorkingWeek, from, to time.Time) []TimetableDayDTO {
+	var days []TimetableDayDTO
+
+	y, m, d := from.Date()
+	from = time.Date(y, m, d, 0, 0, 0, 0, from.Location())
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := TimetableDayDTO{Weekday: date.Weekday(), Date: &date}
+
+		for _, item := range items {
+			if item.Date == nil {
+				continue
+			}
+
+			iy, im, id := item.Date.In(date.Location()).Date()
+			if iy == date.Year() && im == date.Month() && id == date.Day() {
+				day.Items = append(day.Items, item)
+			}
+		}
+
+		if !week.Contains(date.Weekday()) && len(day.Items) == 0 {
+			continue
+		}
+
+		sortTimetableItems(day.Items)
+		days = append(days, day)
+	}
+
+	return days
+}
+
+func sortTimetableItems(items []TimetableItemDTO) {
+	slices.SortStableFunc(items, func(a, b TimetableItemDTO) int {
+		return cmp.Or(
+			cmp.Compare(a.LessonNumber, b.LessonNumber),
+			cmp.Compare(a.EduGroupNumber, b.EduGroupNumber),
+			cmp.Compare(a.Subgroup, b.Subgroup),
+		)
+	})
+}
+
+// fillTimetableSlots splits timetable days into lesson slots. Grid days are split by odd and even weeks
+func fillTimetableSlots(dto *TimetableDTO) {
+	lessonsCount := int8(schedules.LessonsPerDay)
+	for _, day := range dto.Days {
+		for _, item := range day.Items {
+			lessonsCount = max(lessonsCount, item.LessonNumber+item.Span())
+		}
+	}
+
+	for i, day := range dto.Days {
+		var slots []TimetableSlotDTO
+
+		for n := int8(0); n < lessonsCount; n++ {
+			switch dto.View {
+			case TimetableViewGrid:
+				for _, wt := range []schedules.Weektype{schedules.WeekTypeUneven, schedules.WeekTypeEven} {
+					slot := TimetableSlotDTO{LessonNumber: n, Weektype: &wt}
+
+					for _, item := range day.Items {
+						if !item.CoversLesson(n) || item.Weektype == nil {
+							continue
+						}
+
+						if *item.Weektype == wt || *item.Weektype == schedules.WeekTypeBoth {
+							slot.Items = append(slot.Items, item)
+						}
+					}
+
+					slots = append(slots, slot)
+				}
+			case TimetableViewDated:
+				slot := TimetableSlotDTO{LessonNumber: n}
+
+				for _, item := range day.Items {
+					if item.CoversLesson(n) {
+						slot.Items = append(slot.Items, item)
+					}
+				}
+
+				slots = append(slots, slot)
+			}
+		}
+
+		dto.Days[i].Slots = slots
+	}
+}
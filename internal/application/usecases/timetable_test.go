@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"schedule-generator/internal/application/services"
+	"schedule-generator/internal/domain/cabinets"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"schedule-generator/internal/domain/users"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/pkg/execerror"
+
+	"github.com/google/uuid"
+)
+
+// timetableRepoStub group, teacher and cabinet of faculties kept in memory, all of them have no schedules
+type timetableRepoStub struct {
+	TimetableUsecaseRepo
+	group          edugroups.EduGroup
+	teacher        teachers.Teacher
+	cabinet        cabinets.Cabinet
+	groupFacultyID uuid.UUID
+	// teacherFacultyID faculty of teacher department
+	teacherFacultyID uuid.UUID
+}
+
+func (r *timetableRepoStub) GetEduGroup(ctx context.Context, id uuid.UUID) (*edugroups.EduGroup, error) {
+	if id != r.group.ID {
+		return nil, db.ErrorNotFound
+	}
+
+	return &r.group, nil
+}
+
+func (r *timetableRepoStub) GetTeacher(ctx context.Context, id uuid.UUID) (*teachers.Teacher, error) {
+	if id != r.teacher.ID {
+		return nil, db.ErrorNotFound
+	}
+
+	return &r.teacher, nil
+}
+
+func (r *timetableRepoStub) GetCabinet(ctx context.Context, id uuid.UUID) (*cabinets.Cabinet, error) {
+	if id != r.cabinet.ID {
+		return nil, db.ErrorNotFound
+	}
+
+	return &r.cabinet, nil
+}
+
+func (r *timetableRepoStub) ListScheduleByEduGroup(ctx context.Context, groupID uuid.UUID) ([]schedules.Schedule, error) {
+	return nil, nil
+}
+
+func (r *timetableRepoStub) ListScheduleByTeacher(ctx context.Context, teacherID uuid.UUID) ([]schedules.Schedule, error) {
+	return nil, nil
+}
+
+func (r *timetableRepoStub) ListScheduleByCabinet(ctx context.Context, cabinetID uuid.UUID) ([]schedules.Schedule, error) {
+	return nil, nil
+}
+
+func (r *timetableRepoStub) MapEduGroupsBySchedules(ctx context.Context, scheduleIDs uuid.UUIDs) (map[uuid.UUID]edugroups.EduGroup, error) {
+	return map[uuid.UUID]edugroups.EduGroup{}, nil
+}
+
+// timetableAuthRepoStub resolves faculties of group and teacher of timetable repository
+type timetableAuthRepoStub struct {
+	services.AuthorizationServiceRepository
+	repo *timetableRepoStub
+}
+
+func (r *timetableAuthRepoStub) GetEduGroupFacultyID(ctx context.Context, groupID uuid.UUID) (uuid.UUID, error) {
+	return r.repo.groupFacultyID, nil
+}
+
+func (r *timetableAuthRepoStub) GetTeacherFacultyID(ctx context.Context, teacherID uuid.UUID) (uuid.UUID, error) {
+	return r.repo.teacherFacultyID, nil
+}
+
+func TestTimetableUsecase_FindFreeSlots_Access(t *testing.T) {
+	own, other := uuid.New(), uuid.New()
+
+	cases := map[string]struct {
+		teacherFaculty uuid.UUID
+		cabinetFaculty uuid.UUID
+		forbidden      bool
+	}{
+		"teacher and cabinet of user faculty": {teacherFaculty: own, cabinetFaculty: own},
+		"teacher of other faculty":            {teacherFaculty: other, cabinetFaculty: own, forbidden: true},
+		"cabinet of other faculty":            {teacherFaculty: own, cabinetFaculty: other, forbidden: true},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			repo := &timetableRepoStub{
+				group:            edugroups.EduGroup{ID: uuid.New(), Number: "101"},
+				teacher:          teachers.Teacher{ID: uuid.New()},
+				cabinet:          cabinets.Cabinet{ID: uuid.New(), FacultyID: c.cabinetFaculty, Building: "1", Auditorium: "101"},
+				groupFacultyID:   own,
+				teacherFacultyID: c.teacherFaculty,
+			}
+
+			uc := NewTimetableUsecase(services.NewAuthorizationService(&timetableAuthRepoStub{repo: repo}), nil, repo, nil, slog.New(slog.DiscardHandler))
+			user := &users.User{ID: uuid.New(), Role: users.RoleDeputyDean, FacultyID: &own}
+
+			_, err := uc.FindFreeSlots(context.Background(), FindFreeSlotsInput{
+				EduGroupID: repo.group.ID,
+				TeacherID:  repo.teacher.ID,
+				CabinetID:  &repo.cabinet.ID,
+			}, user)
+
+			var execErr *execerror.ExecError
+			if forbidden := errors.As(err, &execErr) && execErr.Type == execerror.TypeForbbiden; forbidden != c.forbidden {
+				t.Errorf("expected forbidden: %v, got error: %v", c.forbidden, err)
+			}
+		})
+	}
+}
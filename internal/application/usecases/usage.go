@@ -0,0 +1,409 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"schedule-generator/internal/application/services"
+	"schedule-generator/internal/common"
+	"schedule-generator/internal/domain/cabinets"
+	edugroups "schedule-generator/internal/domain/edu_groups"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/domain/teachers"
+	"schedule-generator/internal/domain/users"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/pkg/execerror"
+
+	"github.com/google/uuid"
+)
+
+type UsageUsecaseRepo interface {
+	schedules.Repository
+	teachers.Repository
+	cabinets.Repository
+	edugroups.Repository
+
+	ListScheduleByTeacher(ctx context.Context, teacherID uuid.UUID) ([]schedules.Schedule, error)
+	ListScheduleByCabinet(ctx context.Context, cabinetID uuid.UUID) ([]schedules.Schedule, error)
+	ListScheduleByEduGroup(ctx context.Context, groupID uuid.UUID) ([]schedules.Schedule, error)
+	MapEduGroupsBySchedules(ctx context.Context, scheduleIDs uuid.UUIDs) (map[uuid.UUID]edugroups.EduGroup, error)
+	MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs) (map[uuid.UUID]teachers.Teacher, error)
+
+	db.TransactionalRepository
+}
+
+type UsageUsecase struct {
+	repo    UsageUsecaseRepo
+	authSvc *services.AuthorizationService
+	logger  *slog.Logger
+}
+
+func NewUsageUsecase(authSvc *services.AuthorizationService, repo UsageUsecaseRepo, logger *slog.Logger) *UsageUsecase {
+	return &UsageUsecase{
+		repo:    repo,
+		authSvc: authSvc,
+		logger:  logger,
+	}
+}
+
+// ScheduleUsageDTO schedule and its items which depend on record
+type ScheduleUsageDTO struct {
+	ScheduleID     uuid.UUID
+	EduGroupID     uuid.UUID
+	EduGroupNumber string
+	Semester       int
+	Items          []ScheduleItemDTO
+}
+
+type ListUsageOutput = []ScheduleUsageDTO
+
+// ListTeacherUsage
+func (uc *UsageUsecase) ListTeacherUsage(ctx context.Context, teacherID uuid.UUID, user *users.User) (ListUsageOutput, error) {
+	logger := uc.logger.With("teacher_id", teacherID)
+
+	teacher, err := uc.repo.GetTeacher(ctx, teacherID)
+	if err != nil {
+		logger.Error("Get teacher error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("teacher not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToTeacher(ctx, teacher, user); err != nil {
+		logger.Error("Check access to teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to teacher"))
+	}
+
+	list, err := uc.repo.ListScheduleByTeacher(ctx, teacher.ID)
+	if err != nil {
+		logger.Error("List schedule by teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return uc.collectUsage(ctx, logger, list, func(item schedules.ScheduleItem) bool {
+		return item.HasTeacher(teacher.ID)
+	})
+}
+
+// ListCabinetUsage
+func (uc *UsageUsecase) ListCabinetUsage(ctx context.Context, cabinetID uuid.UUID, user *users.User) (ListUsageOutput, error) {
+	logger := uc.logger.With("cabinet_id", cabinetID)
+
+	cabinet, err := uc.repo.GetCabinet(ctx, cabinetID)
+	if err != nil {
+		logger.Error("Get cabinet error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("cabinet not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToCabinet(ctx, cabinet, user); err != nil {
+		logger.Error("Check access to cabinet error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to cabinet"))
+	}
+
+	list, err := uc.repo.ListScheduleByCabinet(ctx, cabinet.ID)
+	if err != nil {
+		logger.Error("List schedule by cabinet error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return uc.collectUsage(ctx, logger, list, func(item schedules.ScheduleItem) bool {
+		return item.InCabinet() && item.Cabinet.ID == cabinet.ID
+	})
+}
+
+// ListEduGroupUsage returns schedules of group which are deleted together with group
+func (uc *UsageUsecase) ListEduGroupUsage(ctx context.Context, groupID uuid.UUID, user *users.User) (ListUsageOutput, error) {
+	logger := uc.logger.With("edu_group_id", groupID)
+
+	group, err := uc.repo.GetEduGroup(ctx, groupID)
+	if err != nil {
+		logger.Error("Get edu group error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("edu group not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	if ok, err := uc.authSvc.HaveAccessToEduGroup(ctx, group, user); err != nil {
+		logger.Error("Check access to group error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	} else if !ok {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have access to edu group"))
+	}
+
+	list, err := uc.repo.ListScheduleByEduGroup(ctx, group.ID)
+	if err != nil {
+		logger.Error("List schedule by edu group error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return uc.collectUsage(ctx, logger, list, func(item schedules.ScheduleItem) bool {
+		return true
+	})
+}
+
+// collectUsage returns schedules with items matched by filter. Schedules without matched items are skipped
+func (uc *UsageUsecase) collectUsage(
+	ctx context.Context,
+	logger *slog.Logger,
+	list []schedules.Schedule,
+	filter func(item schedules.ScheduleItem) bool,
+) (ListUsageOutput, error) {
+	scheduleIDs := make(uuid.UUIDs, len(list))
+	for i, schedule := range list {
+		scheduleIDs[i] = schedule.ID
+	}
+
+	groups, err := uc.repo.MapEduGroupsBySchedules(ctx, scheduleIDs)
+	if err != nil {
+		logger.Error("Map edu groups by schedules error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	var items []schedules.ScheduleItem
+	for _, schedule := range list {
+		items = append(items, schedule.ListItem()...)
+	}
+
+	teachersMap, err := uc.repo.MapTeacherByIDs(ctx, collectTeacherIDs(items))
+	if err != nil {
+		logger.Error("Get teachers map error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	result := make(ListUsageOutput, 0, len(list))
+	for _, schedule := range list {
+		usage := ScheduleUsageDTO{
+			ScheduleID:     schedule.ID,
+			EduGroupID:     schedule.EduGroupID,
+			EduGroupNumber: groups[schedule.EduGroupID].Number,
+			Semester:       schedule.Semester,
+		}
+
+		for _, item := range schedule.ListItem() {
+			if !filter(item) {
+				continue
+			}
+
+			dto, err := newScheduleItemDTO(item, teachersMap)
+			if err != nil {
+				logger.Error("Create schedule item dto error", "error", err, "schedule_id", schedule.ID)
+				return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+			}
+
+			usage.Items = append(usage.Items, dto)
+		}
+
+		if len(usage.Items) > 0 {
+			result = append(result, usage)
+		}
+	}
+
+	return result, nil
+}
+
+type ReassignTeacherInput struct {
+	TeacherID    uuid.UUID
+	NewTeacherID uuid.UUID
+	// Since replaces teacher in items taking place since date, today when empty
+	Since *time.Time
+}
+
+type ReassignCabinetInput struct {
+	CabinetID    uuid.UUID
+	NewCabinetID uuid.UUID
+	// Since moves items taking place since date, today when empty
+	Since *time.Time
+}
+
+type ReassignOutput struct {
+	SchedulesCount int
+	ItemsCount     int
+}
+
+// ReassignTeacher replaces teacher with another one in all schedules
+func (uc *UsageUsecase) ReassignTeacher(ctx context.Context, input ReassignTeacherInput, user *users.User) (*ReassignOutput, error) {
+	logger := uc.logger.With("teacher_id", input.TeacherID, "new_teacher_id", input.NewTeacherID)
+
+	if !uc.authSvc.IsAdmin(user) {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have acces to usecase"))
+	}
+
+	if input.TeacherID == input.NewTeacherID {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("teacher can not be reassigned to itself"))
+	}
+
+	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	if err != nil {
+		logger.Error("Start transaction error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+	defer rollback(ctx)
+
+	repo := tx.(UsageUsecaseRepo)
+
+	for _, id := range []uuid.UUID{input.TeacherID, input.NewTeacherID} {
+		_, err := repo.GetTeacher(ctx, id)
+		if err != nil {
+			logger.Error("Get teacher error", "error", err)
+			if errors.Is(err, db.ErrorNotFound) {
+				return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("teacher not found")).AddDetails("teacher_id", id.String())
+			}
+
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+	}
+
+	list, err := repo.ListScheduleByTeacher(ctx, input.TeacherID)
+	if err != nil {
+		logger.Error("List schedule by teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	out, err := uc.reassign(ctx, logger, repo, list, func(schedule *schedules.Schedule) (int, error) {
+		return schedule.ReplaceTeacher(input.TeacherID, input.NewTeacherID, reassignSince(input.Since))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = commit(ctx)
+	if err != nil {
+		logger.Error("Commit reassign teacher error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return out, nil
+}
+
+// ReassignCabinet moves lessons from cabinet to another one in all schedules
+func (uc *UsageUsecase) ReassignCabinet(ctx context.Context, input ReassignCabinetInput, user *users.User) (*ReassignOutput, error) {
+	logger := uc.logger.With("cabinet_id", input.CabinetID, "new_cabinet_id", input.NewCabinetID)
+
+	if !uc.authSvc.IsAdmin(user) {
+		return nil, execerror.NewExecError(execerror.TypeForbbiden, errors.New("user does not have acces to usecase"))
+	}
+
+	if input.CabinetID == input.NewCabinetID {
+		return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("cabinet can not be reassigned to itself"))
+	}
+
+	tx, rollback, commit, err := uc.repo.AsTransaction(ctx, db.IsoLevelDefault)
+	if err != nil {
+		logger.Error("Start transaction error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+	defer rollback(ctx)
+
+	repo := tx.(UsageUsecaseRepo)
+
+	cabinet, err := repo.GetCabinet(ctx, input.CabinetID)
+	if err != nil {
+		logger.Error("Get cabinet error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("cabinet not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	newCabinet, err := repo.GetCabinet(ctx, input.NewCabinetID)
+	if err != nil {
+		logger.Error("Get new cabinet error", "error", err)
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, errors.New("new cabinet not found"))
+		}
+
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	list, err := repo.ListScheduleByCabinet(ctx, cabinet.ID)
+	if err != nil {
+		logger.Error("List schedule by cabinet error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	to := schedules.Cabinet{
+		ID:         newCabinet.ID,
+		Building:   newCabinet.Building,
+		Auditorium: newCabinet.Auditorium,
+	}
+
+	out, err := uc.reassign(ctx, logger, repo, list, func(schedule *schedules.Schedule) (int, error) {
+		return schedule.ReplaceCabinet(cabinet.ID, to, reassignSince(input.Since))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = commit(ctx)
+	if err != nil {
+		logger.Error("Commit reassign cabinet error", "error", err)
+		return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+	}
+
+	return out, nil
+}
+
+// reassignSince returns date since which items are reassigned, past lessons are kept unchanged by default
+func reassignSince(since *time.Time) time.Time {
+	if since != nil {
+		return *since
+	}
+
+	y, m, d := time.Now().In(common.DefaultTimezone).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, common.DefaultTimezone)
+}
+
+// reassign applies replace to each schedule and saves changed ones
+func (uc *UsageUsecase) reassign(
+	ctx context.Context,
+	logger *slog.Logger,
+	repo UsageUsecaseRepo,
+	list []schedules.Schedule,
+	replace func(schedule *schedules.Schedule) (int, error),
+) (*ReassignOutput, error) {
+	var out ReassignOutput
+
+	for i := range list {
+		schedule := &list[i]
+
+		changed, err := replace(schedule)
+		if err != nil {
+			logger.Error("Replace in schedule error", "error", err, "schedule_id", schedule.ID)
+			return nil, execerror.NewExecError(execerror.TypeInvalidInput, err).AddDetails("schedule_id", schedule.ID.String())
+		}
+
+		if changed == 0 {
+			continue
+		}
+
+		err = repo.SaveSchedule(ctx, schedule)
+		if err != nil {
+			logger.Error("Save schedule error", "error", err, "schedule_id", schedule.ID)
+			if errors.Is(err, db.ErrorVersionConflict) {
+				return nil, scheduleVersionConflict(schedule.Version)
+			}
+
+			return nil, execerror.NewExecError(execerror.TypeInternal, nil)
+		}
+
+		out.SchedulesCount++
+		out.ItemsCount += changed
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,99 @@
+package academicyears
+
+import (
+	"errors"
+	"time"
+
+	"schedule-generator/internal/common"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultFallSemesterStartMonth   = time.September
+	DefaultFallSemesterStartDay     = 1
+	DefaultSpringSemesterStartMonth = time.February
+	DefaultSpringSemesterStartDay   = 9
+)
+
+// AcademicYear faculty academic calendar. Year is calendar year in which academic year starts
+type AcademicYear struct {
+	ID                  uuid.UUID
+	FacultyID           uuid.UUID
+	Year                int
+	FallSemesterStart   time.Time
+	SpringSemesterStart time.Time
+	// WeeksFromMonday education weeks start on monday, the first week is the one containing semester start.
+	// Otherwise weeks are counted from semester start day as schedules made before academic calendar do
+	WeeksFromMonday bool
+}
+
+func NewAcademicYear(facultyID uuid.UUID, year int, fallSemesterStart, springSemesterStart time.Time, weeksFromMonday bool) (*AcademicYear, error) {
+	y := &AcademicYear{
+		ID:                  uuid.New(),
+		FacultyID:           facultyID,
+		Year:                year,
+		FallSemesterStart:   fallSemesterStart,
+		SpringSemesterStart: springSemesterStart,
+		WeeksFromMonday:     weeksFromMonday,
+	}
+
+	if err := y.Validate(); err != nil {
+		return nil, err
+	}
+
+	return y, nil
+}
+
+// DefaultAcademicYear academic year used when faculty does not have configured one
+func DefaultAcademicYear(facultyID uuid.UUID, year int) AcademicYear {
+	return AcademicYear{
+		FacultyID:           facultyID,
+		Year:                year,
+		FallSemesterStart:   time.Date(year, DefaultFallSemesterStartMonth, DefaultFallSemesterStartDay, 0, 0, 0, 0, common.DefaultTimezone),
+		SpringSemesterStart: time.Date(year+1, DefaultSpringSemesterStartMonth, DefaultSpringSemesterStartDay, 0, 0, 0, 0, common.DefaultTimezone),
+	}
+}
+
+// SemesterStartDate start of semester by its number. Odd semesters are fall, even are spring
+func (y AcademicYear) SemesterStartDate(semester int) time.Time {
+	if semester > 0 && semester%2 == 0 {
+		return y.SpringSemesterStart
+	}
+
+	return y.FallSemesterStart
+}
+
+// SemesterWeeksStart start of the first education week of semester, used as origin for week numbering
+func (y AcademicYear) SemesterWeeksStart(semester int) time.Time {
+	start := y.SemesterStartDate(semester)
+	if !y.WeeksFromMonday {
+		return start
+	}
+
+	return start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+}
+
+func (y *AcademicYear) Validate() error {
+	if y.Year <= 0 {
+		return errors.New("invalid year")
+	}
+
+	if y.FallSemesterStart.IsZero() || y.SpringSemesterStart.IsZero() {
+		return errors.New("semester start dates are required")
+	}
+
+	if y.FallSemesterStart.Year() != y.Year {
+		return errors.New("fall semester must start in academic year start year")
+	}
+
+	if !y.SpringSemesterStart.After(y.FallSemesterStart) {
+		return errors.New("spring semester must start after fall semester")
+	}
+
+	if y.SpringSemesterStart.Year() > y.Year+1 {
+		return errors.New("spring semester must start in the same academic year")
+	}
+
+	return nil
+}
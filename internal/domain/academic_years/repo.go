@@ -0,0 +1,16 @@
+package academicyears
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	SaveAcademicYear(ctx context.Context, year *AcademicYear) error
+	GetAcademicYear(ctx context.Context, id uuid.UUID) (*AcademicYear, error)
+	GetAcademicYearByFacultyAndYear(ctx context.Context, facultyID uuid.UUID, year int) (*AcademicYear, error)
+	ListAcademicYear(ctx context.Context) ([]AcademicYear, error)
+	ListAcademicYearByFaculty(ctx context.Context, facultyID uuid.UUID) ([]AcademicYear, error)
+	DeleteAcademicYear(ctx context.Context, id uuid.UUID) error
+}
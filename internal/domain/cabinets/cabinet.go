@@ -2,6 +2,7 @@ package cabinets
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -50,6 +51,7 @@ type Cabinet struct {
 	Building                           string
 	Auditorium                         string
 	SuitableForPeoplesWithSpecialNeeds bool
+	Capacity                           int
 	Appointment                        *string
 	Equipment                          *CabinetEquipment
 }
@@ -57,6 +59,10 @@ type Cabinet struct {
 func (c *Cabinet) Validate() error {
 	var argErr error
 
+	if c.Capacity < 0 {
+		argErr = errors.Join(argErr, errors.New("invalid capacity value"))
+	}
+
 	if len(c.Auditorium) == 0 {
 		argErr = errors.Join(argErr, errors.New("invalid auditorium value"))
 	}
@@ -76,7 +82,7 @@ func (c *Cabinet) Validate() error {
 	return nil
 }
 
-func NewCabinet(facultyID uuid.UUID, cabinetType CabinetType, auditorium string, suitableForPeoplesWithSpecialNeeds bool, building string, appointment *string, equipment *CabinetEquipment) (*Cabinet, error) {
+func NewCabinet(facultyID uuid.UUID, cabinetType CabinetType, auditorium string, suitableForPeoplesWithSpecialNeeds bool, building string, capacity int, appointment *string, equipment *CabinetEquipment) (*Cabinet, error) {
 	cab := Cabinet{
 		ID:                                 uuid.New(),
 		FacultyID:                          facultyID,
@@ -84,6 +90,7 @@ func NewCabinet(facultyID uuid.UUID, cabinetType CabinetType, auditorium string,
 		Auditorium:                         auditorium,
 		SuitableForPeoplesWithSpecialNeeds: suitableForPeoplesWithSpecialNeeds,
 		Building:                           building,
+		Capacity:                           capacity,
 		Appointment:                        appointment,
 		Equipment:                          equipment,
 	}
@@ -94,3 +101,49 @@ func NewCabinet(facultyID uuid.UUID, cabinetType CabinetType, auditorium string,
 
 	return &cab, nil
 }
+
+// Filter describes optional cabinet requirements
+type Filter struct {
+	Type                               *CabinetType
+	MinCapacity                        *int
+	SuitableForPeoplesWithSpecialNeeds *bool
+	Equipment                          []string
+}
+
+// Match reports whether cabinet satisfies all filter requirements.
+// Equipment requirements are matched case-insensitively against any equipment description
+func (f Filter) Match(c *Cabinet) bool {
+	if f.Type != nil && c.Type != *f.Type {
+		return false
+	}
+
+	if f.MinCapacity != nil && c.Capacity < *f.MinCapacity {
+		return false
+	}
+
+	if f.SuitableForPeoplesWithSpecialNeeds != nil && c.SuitableForPeoplesWithSpecialNeeds != *f.SuitableForPeoplesWithSpecialNeeds {
+		return false
+	}
+
+	if len(f.Equipment) == 0 {
+		return true
+	}
+
+	if c.Equipment == nil {
+		return false
+	}
+
+	description := strings.ToLower(strings.Join([]string{
+		c.Equipment.Furniture,
+		c.Equipment.TechnicalMeans,
+		c.Equipment.ComputerEquipment,
+	}, "\n"))
+
+	for _, e := range f.Equipment {
+		if !strings.Contains(description, strings.ToLower(e)) {
+			return false
+		}
+	}
+
+	return true
+}
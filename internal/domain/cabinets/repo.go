@@ -10,5 +10,6 @@ type Repository interface {
 	SaveCabinet(ctx context.Context, c *Cabinet) error
 	GetCabinet(ctx context.Context, id uuid.UUID) (*Cabinet, error)
 	ListCabinet(ctx context.Context) ([]Cabinet, error)
+	ListCabinetByFaculty(ctx context.Context, facultyID uuid.UUID) ([]Cabinet, error)
 	DeleteCabinet(ctx context.Context, id uuid.UUID) error
 }
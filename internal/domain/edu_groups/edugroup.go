@@ -2,18 +2,11 @@ package edugroups
 
 import (
 	"errors"
-	"schedule-generator/internal/common"
 	eduplans "schedule-generator/internal/domain/edu_plans"
-	"time"
 
 	"github.com/google/uuid"
 )
 
-const (
-	EduactionStartMonth = time.September
-	EducationStartDay   = 1
-)
-
 type EduGroup struct {
 	ID            uuid.UUID
 	Number        string
@@ -40,9 +33,9 @@ func NewEduGroup(number string, eduPlan *eduplans.EduPlan) (*EduGroup, error) {
 	}, nil
 }
 
-// GetEducationStartDate start of current education year by semester number
-func (e EduGroup) GetEducationStartDateBySemester(semester int) time.Time {
-	if semester < 0 {
+// GetEducationYearBySemester calendar year in which academic year of semester starts
+func (e EduGroup) GetEducationYearBySemester(semester int) int {
+	if semester <= 0 {
 		semester = 1
 	} else if semester%2 == 0 {
 		semester -= 1
@@ -50,7 +43,7 @@ func (e EduGroup) GetEducationStartDateBySemester(semester int) time.Time {
 
 	course := semester / 2
 
-	return time.Date(int(e.AdmissionYear)+course, EduactionStartMonth, EducationStartDay, 0, 0, 0, 0, common.DefaultTimezone)
+	return int(e.AdmissionYear) + course
 }
 
 func (e *EduGroup) Validate() error {
@@ -2,6 +2,7 @@ package faculties
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -9,6 +10,8 @@ import (
 type Faculty struct {
 	ID   uuid.UUID
 	Name string
+	// WorkingDays default working days for faculty schedules
+	WorkingDays []time.Weekday
 }
 
 type Repository interface {
@@ -0,0 +1,56 @@
+package schedules
+
+import (
+	"errors"
+	"time"
+)
+
+// LessonTime start and end of lesson period as offset from midnight
+type LessonTime struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// BellSchedule times of lesson periods indexed by lesson number
+type BellSchedule []LessonTime
+
+// DefaultBellSchedule 90 minutes periods with long break after second one
+var DefaultBellSchedule = BellSchedule{
+	{Start: 8*time.Hour + 30*time.Minute, End: 10 * time.Hour},
+	{Start: 10*time.Hour + 10*time.Minute, End: 11*time.Hour + 40*time.Minute},
+	{Start: 12*time.Hour + 20*time.Minute, End: 13*time.Hour + 50*time.Minute},
+	{Start: 14 * time.Hour, End: 15*time.Hour + 30*time.Minute},
+	{Start: 15*time.Hour + 40*time.Minute, End: 17*time.Hour + 10*time.Minute},
+	{Start: 17*time.Hour + 20*time.Minute, End: 18*time.Hour + 50*time.Minute},
+	{Start: 19 * time.Hour, End: 20*time.Hour + 30*time.Minute},
+	{Start: 20*time.Hour + 40*time.Minute, End: 22*time.Hour + 10*time.Minute},
+}
+
+// Validate checks that periods are ordered and do not overlap
+func (b BellSchedule) Validate() error {
+	if len(b) == 0 {
+		return errors.New("bell schedule can not be empty")
+	}
+
+	var prevEnd time.Duration
+	for _, t := range b {
+		if t.Start >= t.End || t.Start < prevEnd || t.End > 24*time.Hour {
+			return errors.New("lesson periods must be ordered and must not overlap")
+		}
+
+		prevEnd = t.End
+	}
+
+	return nil
+}
+
+// ItemTime returns start of first and end of last period taken by item. Returns false when
+// item takes period missing in bell schedule
+func (b BellSchedule) ItemTime(item ScheduleItem) (LessonTime, bool) {
+	first, last := int(item.LessonNumber), int(item.LessonNumber+item.Span())-1
+	if first < 0 || last >= len(b) {
+		return LessonTime{}, false
+	}
+
+	return LessonTime{Start: b[first].Start, End: b[last].End}, true
+}
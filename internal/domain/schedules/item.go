@@ -2,6 +2,11 @@ package schedules
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +30,11 @@ func (w Weektype) String() string {
 	return weektypeNames[w]
 }
 
+// Overlaps reports whether both weektypes share at least one week
+func (w Weektype) Overlaps(other Weektype) bool {
+	return w == other || w == WeekTypeBoth || other == WeekTypeBoth
+}
+
 func NewWeekType(wt int8) (Weektype, error) {
 	if int(wt) < 0 || int(wt) >= len(weektypeNames) {
 		return 0, errors.New("unknown week type")
@@ -33,6 +43,112 @@ func NewWeekType(wt int8) (Weektype, error) {
 	return Weektype(wt), nil
 }
 
+// WeektypeOfWeek returns type of education week with provided number, the first week is odd
+func WeektypeOfWeek(weekNumber int) Weektype {
+	if weekNumber%2 == 0 {
+		return WeekTypeEven
+	}
+
+	return WeekTypeUneven
+}
+
+const (
+	// DefaultCycleLength classic odd/even rotation
+	DefaultCycleLength = 2
+	MaxCycleLength     = 8
+	// MaxWeekNumber max education week number in semester
+	MaxWeekNumber = 53
+)
+
+// Weeks sorted set of week numbers starting from 1
+type Weeks []int
+
+// NewWeeks validates week numbers to be in range 1..max and returns them sorted without duplicates
+func NewWeeks(weeks []int, max int) (Weeks, error) {
+	if len(weeks) == 0 {
+		return nil, nil
+	}
+
+	result := slices.Clone(weeks)
+	slices.Sort(result)
+	result = slices.Compact(result)
+
+	if result[0] < 1 || result[len(result)-1] > max {
+		return nil, fmt.Errorf("week numbers must be in range 1..%d", max)
+	}
+
+	return result, nil
+}
+
+// ParseWeeks parses week list like "1-8,10,12"
+func ParseWeeks(s string, max int) (Weeks, error) {
+	var weeks []int
+
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid week '%s'", part)
+		}
+
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid week range '%s'", part)
+			}
+		}
+
+		if end > max {
+			return nil, fmt.Errorf("week numbers must be in range 1..%d", max)
+		}
+
+		for w := start; w <= end; w++ {
+			weeks = append(weeks, w)
+		}
+	}
+
+	return NewWeeks(weeks, max)
+}
+
+// Contains reports whether week number is in set
+func (w Weeks) Contains(n int) bool {
+	_, ok := slices.BinarySearch(w, n)
+	return ok
+}
+
+// String formats weeks collapsing sequences into ranges, e.g. "1-8,10,12"
+func (w Weeks) String() string {
+	var sb strings.Builder
+
+	for i := 0; i < len(w); i++ {
+		j := i
+		for j+1 < len(w) && w[j+1] == w[j]+1 {
+			j++
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(strconv.Itoa(w[i]))
+		if j > i {
+			sb.WriteByte('-')
+			sb.WriteString(strconv.Itoa(w[j]))
+		}
+
+		i = j
+	}
+
+	return sb.String()
+}
+
 type ItemLessonType int8
 
 const (
@@ -63,21 +179,315 @@ func NewItemLessonType(t int8) (ItemLessonType, error) {
 	return ItemLessonType(t), nil
 }
 
+// LessonsPerDay is default count of lesson slots in one day
+const LessonsPerDay = 8
+
+const (
+	// DefaultItemDuration single lesson period
+	DefaultItemDuration = 1
+	// MaxItemDuration max count of consecutive lesson periods taken by one item
+	MaxItemDuration = 4
+)
+
+// NewItemDuration validates count of consecutive lesson periods. Zero value means single lesson
+func NewItemDuration(duration int8) (int8, error) {
+	if duration == 0 {
+		return DefaultItemDuration, nil
+	}
+
+	if duration < 0 || duration > MaxItemDuration {
+		return 0, fmt.Errorf("duration must be in range 1..%d", MaxItemDuration)
+	}
+
+	return duration, nil
+}
+
+type TeacherRole int8
+
+const (
+	TeacherRoleMain TeacherRole = iota
+	TeacherRoleAssistant
+)
+
+var teacherRoleNames = []string{
+	"main",
+	"assistant",
+}
+
+func (r TeacherRole) String() string {
+	if int(r) < 0 || int(r) >= len(teacherRoleNames) {
+		return "unknown"
+	}
+
+	return teacherRoleNames[r]
+}
+
+func NewTeacherRole(r int8) (TeacherRole, error) {
+	if int(r) < 0 || int(r) >= len(teacherRoleNames) {
+		return 0, errors.New("unknown teacher role")
+	}
+
+	return TeacherRole(r), nil
+}
+
+type ItemTeacher struct {
+	TeacherID uuid.UUID
+	Role      TeacherRole
+}
+
+// NewItemTeachers validates teachers of lesson. Lesson must have at least one main teacher and each teacher is listed once.
+// Main teachers go first
+func NewItemTeachers(teachers []ItemTeacher) ([]ItemTeacher, error) {
+	if len(teachers) == 0 {
+		return nil, errors.New("lesson must have at least one teacher")
+	}
+
+	result := make([]ItemTeacher, 0, len(teachers))
+	seen := make(map[uuid.UUID]struct{}, len(teachers))
+
+	for _, t := range teachers {
+		if t.TeacherID == uuid.Nil {
+			return nil, errors.New("empty teacher id")
+		}
+
+		if _, err := NewTeacherRole(int8(t.Role)); err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[t.TeacherID]; ok {
+			return nil, fmt.Errorf("teacher %s is listed twice", t.TeacherID)
+		}
+
+		seen[t.TeacherID] = struct{}{}
+		result = append(result, t)
+	}
+
+	sortItemTeachers(result)
+
+	if result[0].Role != TeacherRoleMain {
+		return nil, errors.New("lesson must have main teacher")
+	}
+
+	return result, nil
+}
+
+func sortItemTeachers(teachers []ItemTeacher) {
+	slices.SortStableFunc(teachers, func(a, b ItemTeacher) int {
+		return int(a.Role) - int(b.Role)
+	})
+}
+
 type Cabinet struct {
+	ID         uuid.UUID
 	Auditorium string
 	Building   string
 }
 
+type LocationKind int8
+
+const (
+	LocationKindCabinet LocationKind = iota
+	LocationKindOnline
+	LocationKindExternal
+)
+
+var locationKindNames = []string{
+	"cabinet",
+	"online",
+	"external",
+}
+
+func (k LocationKind) String() string {
+	return locationKindNames[k]
+}
+
+func NewLocationKind(k int8) (LocationKind, error) {
+	if int(k) < 0 || int(k) >= len(locationKindNames) {
+		return 0, errors.New("unknown location kind")
+	}
+
+	return LocationKind(k), nil
+}
+
+// Location where lesson takes place. Lessons in cabinet use item Cabinet
+type Location struct {
+	Kind LocationKind
+	// MeetingURL link to online meeting
+	MeetingURL string
+	// Address of off-site lesson, e.g. partner company office
+	Address string
+}
+
+// NewLocation validates lesson location. Online lesson requires meeting url, external lesson requires address
+func NewLocation(kind int8, meetingURL, address string) (Location, error) {
+	k, err := NewLocationKind(kind)
+	if err != nil {
+		return Location{}, err
+	}
+
+	location := Location{Kind: k}
+
+	switch k {
+	case LocationKindOnline:
+		u, err := url.ParseRequestURI(meetingURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return Location{}, errors.New("online lesson requires valid meeting url")
+		}
+
+		location.MeetingURL = meetingURL
+	case LocationKindExternal:
+		address = strings.TrimSpace(address)
+		if len(address) == 0 {
+			return Location{}, errors.New("external lesson requires address")
+		}
+
+		location.Address = address
+	}
+
+	return location, nil
+}
+
 type ScheduleItem struct {
-	Discipline    string
-	TeacherID     uuid.UUID
+	// ID stable identity of item, kept when item is changed
+	ID         uuid.UUID
+	Discipline string
+	// Teachers of lesson, main teachers go first
+	Teachers      []ItemTeacher
 	Weekday       time.Weekday
 	StudentsCount int16
 	Date          *time.Time
 	LessonNumber  int8
-	Subgroup      int8
-	Weektype      *Weektype
-	Weeknum       *int
-	LessonType    ItemLessonType
-	Cabinet       Cabinet
+	// Duration count of consecutive lesson periods taken by item starting from LessonNumber
+	Duration int8
+	Subgroup int8
+	Weektype *Weektype
+	// CycleWeeks weeks of N-week rotation when cycled item takes place. Empty means item follows Weektype
+	CycleWeeks Weeks
+	// Weeks education weeks of semester when cycled item takes place. Empty means all weeks
+	Weeks      Weeks
+	Weeknum    *int
+	LessonType ItemLessonType
+	Location   Location
+	// Cabinet is empty for lessons outside of cabinet
+	Cabinet Cabinet
+}
+
+// OccursOnWeek reports whether cycled item takes place on education week with provided number
+func (i ScheduleItem) OccursOnWeek(weekNumber, cycleLength int) bool {
+	if len(i.Weeks) > 0 && !i.Weeks.Contains(weekNumber) {
+		return false
+	}
+
+	if len(i.CycleWeeks) > 0 && cycleLength > 0 {
+		return i.CycleWeeks.Contains((weekNumber-1)%cycleLength + 1)
+	}
+
+	if i.Weektype == nil {
+		return true
+	}
+
+	switch *i.Weektype {
+	case WeekTypeUneven:
+		return weekNumber%2 == 1
+	case WeekTypeEven:
+		return weekNumber%2 == 0
+	}
+
+	return true
+}
+
+// MainTeacherID returns id of first main teacher of lesson
+func (i ScheduleItem) MainTeacherID() uuid.UUID {
+	if len(i.Teachers) == 0 {
+		return uuid.Nil
+	}
+
+	return i.Teachers[0].TeacherID
+}
+
+// TeacherIDs returns ids of all teachers of lesson
+func (i ScheduleItem) TeacherIDs() uuid.UUIDs {
+	ids := make(uuid.UUIDs, len(i.Teachers))
+	for j, t := range i.Teachers {
+		ids[j] = t.TeacherID
+	}
+
+	return ids
+}
+
+// HasTeacher reports whether teacher takes part in lesson
+func (i ScheduleItem) HasTeacher(teacherID uuid.UUID) bool {
+	return slices.ContainsFunc(i.Teachers, func(t ItemTeacher) bool {
+		return t.TeacherID == teacherID
+	})
+}
+
+// replaceTeacher replaces teacher keeping its role. When new teacher already takes part in lesson
+// replaced teacher is removed and new one takes the higher role of both
+func (i *ScheduleItem) replaceTeacher(from, to uuid.UUID) bool {
+	idx := slices.IndexFunc(i.Teachers, func(t ItemTeacher) bool {
+		return t.TeacherID == from
+	})
+
+	if idx < 0 || from == to {
+		return false
+	}
+
+	teachers := slices.Clone(i.Teachers)
+
+	if j := slices.IndexFunc(teachers, func(t ItemTeacher) bool { return t.TeacherID == to }); j >= 0 {
+		teachers[j].Role = min(teachers[j].Role, teachers[idx].Role)
+		teachers = slices.Delete(teachers, idx, idx+1)
+	} else {
+		teachers[idx].TeacherID = to
+	}
+
+	sortItemTeachers(teachers)
+	i.Teachers = teachers
+
+	return true
+}
+
+// sharesTeacher reports whether items have at least one common teacher
+func (i ScheduleItem) sharesTeacher(other ScheduleItem) bool {
+	return slices.ContainsFunc(i.Teachers, func(t ItemTeacher) bool {
+		return other.HasTeacher(t.TeacherID)
+	})
+}
+
+// InCabinet reports whether lesson takes place in cabinet and takes part in room conflict checks
+func (i ScheduleItem) InCabinet() bool {
+	return i.Location.Kind == LocationKindCabinet
+}
+
+// Span returns count of lesson periods taken by item, at least one
+func (i ScheduleItem) Span() int8 {
+	return max(i.Duration, DefaultItemDuration)
+}
+
+// LessonNumbers returns numbers of all lesson periods taken by item
+func (i ScheduleItem) LessonNumbers() []int8 {
+	numbers := make([]int8, 0, i.Span())
+	for n := i.LessonNumber; n < i.LessonNumber+i.Span(); n++ {
+		numbers = append(numbers, n)
+	}
+
+	return numbers
+}
+
+// CoversLesson reports whether item takes lesson period with provided number
+func (i ScheduleItem) CoversLesson(lessonNumber int8) bool {
+	return lessonNumber >= i.LessonNumber && lessonNumber < i.LessonNumber+i.Span()
+}
+
+// OverlapsLessons reports whether items share at least one lesson period
+func (i ScheduleItem) OverlapsLessons(other ScheduleItem) bool {
+	return i.LessonNumber < other.LessonNumber+other.Span() && other.LessonNumber < i.LessonNumber+i.Span()
+}
+
+// sameWeekPattern reports whether items take place on exactly the same weeks rule
+func (i ScheduleItem) sameWeekPattern(other ScheduleItem) bool {
+	return (i.Weektype == nil && other.Weektype == nil || i.Weektype != nil && other.Weektype != nil && *i.Weektype == *other.Weektype) &&
+		slices.Equal(i.CycleWeeks, other.CycleWeeks) &&
+		slices.Equal(i.Weeks, other.Weeks)
 }
@@ -12,5 +12,6 @@ type Repository interface {
 	ListScheduleByEduGroup(ctx context.Context, groupID uuid.UUID) ([]Schedule, error)
 	ListScheduleByFaculty(ctx context.Context, facultyID uuid.UUID) ([]Schedule, error)
 	SaveSchedule(ctx context.Context, schedule *Schedule) error
-	DeleteSchedule(ctx context.Context, id uuid.UUID) error
+	// DeleteSchedule deletes schedule only when its version was not changed since schedule was read
+	DeleteSchedule(ctx context.Context, id uuid.UUID, version int) error
 }
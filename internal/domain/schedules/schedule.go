@@ -10,9 +10,10 @@ import (
 )
 
 var (
-	ErrInvalidData  = errors.New("invalid data")
-	ErrItemNotFound = errors.New("item not found")
-	ErrItemConflict = errors.New("item conflict")
+	ErrInvalidData     = errors.New("invalid data")
+	ErrItemNotFound    = errors.New("item not found")
+	ErrItemConflict    = errors.New("item conflict")
+	ErrVersionConflict = errors.New("version conflict")
 )
 
 type ScheduleType int8
@@ -36,8 +37,19 @@ type Schedule struct {
 	EduGroupID uuid.UUID
 	Semester   int
 	Type       ScheduleType
-	Cycled     *CycledSchedule
-	Calendar   *CalendarSchedule
+	// Version is incremented on each save, zero for schedule which is not saved yet
+	Version  int
+	Cycled   *CycledSchedule
+	Calendar *CalendarSchedule
+}
+
+// CheckVersion checks that schedule was not changed since expected version was read. Any version matches nil
+func (s *Schedule) CheckVersion(expected *int) error {
+	if expected != nil && *expected != s.Version {
+		return fmt.Errorf("%w: expected %d, current %d", ErrVersionConflict, *expected, s.Version)
+	}
+
+	return nil
 }
 
 func (s *Schedule) ListItem() []ScheduleItem {
@@ -55,6 +67,176 @@ func (s *Schedule) ListItem() []ScheduleItem {
 	return nil
 }
 
+// GetWorkingWeek returns working days of schedule
+func (s *Schedule) GetWorkingWeek() WorkingWeek {
+	switch s.Type {
+	case ScheduleTypeCycled:
+		if s.Cycled != nil {
+			return s.Cycled.WorkingWeek.OrDefault()
+		}
+	case ScheduleTypeCalendar:
+		if s.Calendar != nil {
+			return s.Calendar.WorkingWeek.OrDefault()
+		}
+	}
+
+	return DefaultWorkingWeek
+}
+
+// SetWorkingWeek changes working days of schedule. Existing items must fit into new working week
+func (s *Schedule) SetWorkingWeek(w WorkingWeek) error {
+	if err := w.Validate(); err != nil {
+		return errors.Join(ErrInvalidData, err)
+	}
+
+	for _, item := range s.ListItem() {
+		if !w.Contains(item.Weekday) {
+			return errors.Join(ErrInvalidData, fmt.Errorf("schedule has items on %s", item.Weekday))
+		}
+	}
+
+	switch s.Type {
+	case ScheduleTypeCycled:
+		s.Cycled.WorkingWeek = w
+	case ScheduleTypeCalendar:
+		s.Calendar.WorkingWeek = w
+	}
+
+	return nil
+}
+
+// ReplaceTeacher replaces teacher in items taking place since provided date. Returns count of changed items
+func (s *Schedule) ReplaceTeacher(from, to uuid.UUID, since time.Time) (int, error) {
+	return s.replaceInItems(since, func(item *ScheduleItem) bool {
+		return item.replaceTeacher(from, to)
+	})
+}
+
+// ReplaceCabinet moves items taking place since provided date from cabinet to another one. Returns count of changed items
+func (s *Schedule) ReplaceCabinet(from uuid.UUID, to Cabinet, since time.Time) (int, error) {
+	return s.replaceInItems(since, func(item *ScheduleItem) bool {
+		if !item.InCabinet() || item.Cabinet.ID != from || from == to.ID {
+			return false
+		}
+
+		item.Cabinet = to
+		return true
+	})
+}
+
+// replaceInItems applies replace to items taking place since date
+func (s *Schedule) replaceInItems(since time.Time, replace func(item *ScheduleItem) bool) (int, error) {
+	switch s.Type {
+	case ScheduleTypeCycled:
+		return s.Cycled.replaceInItems(since, replace)
+	case ScheduleTypeCalendar:
+		return s.Calendar.replaceInItems(since, replace)
+	}
+
+	return 0, fmt.Errorf("unknown schedule")
+}
+
+// GetItem returns item by id
+func (s *Schedule) GetItem(id uuid.UUID) (ScheduleItem, error) {
+	for _, item := range s.ListItem() {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+
+	return ScheduleItem{}, ErrItemNotFound
+}
+
+// RemoveItemByID removes item by its id, returns ErrItemNotFound when there is no such item
+func (s *Schedule) RemoveItemByID(id uuid.UUID) error {
+	match := func(item ScheduleItem) bool {
+		return item.ID == id
+	}
+
+	switch s.Type {
+	case ScheduleTypeCycled:
+		for weekday, items := range s.Cycled.Items {
+			if idx := slices.IndexFunc(items, match); idx >= 0 {
+				s.Cycled.Items[weekday] = slices.Delete(items, idx, idx+1)
+				return nil
+			}
+		}
+	case ScheduleTypeCalendar:
+		if idx := slices.IndexFunc(s.Calendar.Items, match); idx >= 0 {
+			s.Calendar.Items = slices.Delete(s.Calendar.Items, idx, idx+1)
+			return nil
+		}
+	}
+
+	return ErrItemNotFound
+}
+
+// UpdateItem replaces item with the one created by add, e.g. with AddItem of cycled or calendar schedule.
+// New item keeps identity of replaced one. Schedule is left unchanged on error
+func (s *Schedule) UpdateItem(id uuid.UUID, add func() error) error {
+	var (
+		prevCycled   map[time.Weekday][]ScheduleItem
+		prevCalendar []ScheduleItem
+	)
+
+	switch s.Type {
+	case ScheduleTypeCycled:
+		prevCycled = make(map[time.Weekday][]ScheduleItem, len(s.Cycled.Items))
+		for weekday, items := range s.Cycled.Items {
+			prevCycled[weekday] = slices.Clone(items)
+		}
+	case ScheduleTypeCalendar:
+		prevCalendar = slices.Clone(s.Calendar.Items)
+	}
+
+	restore := func() {
+		switch s.Type {
+		case ScheduleTypeCycled:
+			s.Cycled.Items = prevCycled
+		case ScheduleTypeCalendar:
+			s.Calendar.Items = prevCalendar
+		}
+	}
+
+	if err := s.RemoveItemByID(id); err != nil {
+		return err
+	}
+
+	known := make(map[uuid.UUID]struct{})
+	for _, item := range s.ListItem() {
+		known[item.ID] = struct{}{}
+	}
+
+	if err := add(); err != nil {
+		restore()
+		return err
+	}
+
+	s.setItemID(func(item ScheduleItem) bool {
+		_, ok := known[item.ID]
+		return !ok
+	}, id)
+
+	return nil
+}
+
+// setItemID sets id to first item matched by filter
+func (s *Schedule) setItemID(filter func(item ScheduleItem) bool, id uuid.UUID) {
+	switch s.Type {
+	case ScheduleTypeCycled:
+		for _, items := range s.Cycled.Items {
+			if idx := slices.IndexFunc(items, filter); idx >= 0 {
+				items[idx].ID = id
+				return
+			}
+		}
+	case ScheduleTypeCalendar:
+		if idx := slices.IndexFunc(s.Calendar.Items, filter); idx >= 0 {
+			s.Calendar.Items[idx].ID = id
+		}
+	}
+}
+
 func (s *Schedule) Validate(admissionYear, currentYear int) error {
 	if err := s.validateSemester(admissionYear, currentYear); err != nil {
 		return err
@@ -97,7 +279,10 @@ func (s *Schedule) validateSemester(admissionYear, currentYear int) error {
 type CycledSchedule struct {
 	StartDate time.Time
 	EndDate   time.Time
-	Items     map[time.Weekday][]ScheduleItem
+	// CycleLength count of weeks in rotation, 2 for classic odd/even schedule
+	CycleLength int
+	WorkingWeek WorkingWeek
+	Items       map[time.Weekday][]ScheduleItem
 }
 
 // NewCycledSchedule
@@ -110,9 +295,11 @@ func NewCycledSchedule(eduGroupID uuid.UUID, semester int, startDate, endDate ti
 		Semester:   semester,
 		Type:       ScheduleTypeCycled,
 		Cycled: &CycledSchedule{
-			StartDate: startDate,
-			EndDate:   endDate,
-			Items:     make(map[time.Weekday][]ScheduleItem, 6),
+			StartDate:   startDate,
+			EndDate:     endDate,
+			CycleLength: DefaultCycleLength,
+			WorkingWeek: DefaultWorkingWeek,
+			Items:       make(map[time.Weekday][]ScheduleItem, 6),
 		},
 	}
 
@@ -126,8 +313,8 @@ func NewCycledSchedule(eduGroupID uuid.UUID, semester int, startDate, endDate ti
 // ListItem returns ScheduleItem array in weekday ordering
 func (s CycledSchedule) ListItem() []ScheduleItem {
 	var items []ScheduleItem
-	for i := time.Monday; i <= time.Saturday; i++ {
-		items = append(items, s.ListItemByWeekday(i)...)
+	for _, d := range weekdayOrder {
+		items = append(items, s.ListItemByWeekday(d)...)
 	}
 
 	return items
@@ -148,19 +335,46 @@ func (s CycledSchedule) Validate() error {
 		return errors.Join(ErrInvalidData, errors.New("start date is after end date"))
 	}
 
+	if s.CycleLength < 1 || s.CycleLength > MaxCycleLength {
+		return errors.Join(ErrInvalidData, fmt.Errorf("cycle length must be in range 1..%d", MaxCycleLength))
+	}
+
+	for _, item := range s.ListItem() {
+		if len(item.CycleWeeks) > 0 && item.CycleWeeks[len(item.CycleWeeks)-1] > s.CycleLength {
+			return errors.Join(ErrInvalidData, fmt.Errorf("item %s on %s uses cycle week out of %d-week cycle", item.Discipline, item.Weekday, s.CycleLength))
+		}
+	}
+
+	return nil
+}
+
+// SetCycleLength changes count of weeks in rotation
+func (s *CycledSchedule) SetCycleLength(cycleLength int) error {
+	prev := s.CycleLength
+	s.CycleLength = cycleLength
+
+	if err := s.Validate(); err != nil {
+		s.CycleLength = prev
+		return err
+	}
+
 	return nil
 }
 
 // AddItem adds item to schedule
 func (s *CycledSchedule) AddItem(
 	discipline string,
-	teacherID uuid.UUID,
+	teachers []ItemTeacher,
 	weekday time.Weekday,
 	studentsCount int16,
 	lessonNumber int8,
+	duration int8,
 	subgroup int8,
 	weektype int8,
+	cycleWeeks []int,
+	weeks []int,
 	lessonType int8,
+	location Location,
 	cabinet Cabinet,
 ) error {
 	var argErr error
@@ -173,8 +387,8 @@ func (s *CycledSchedule) AddItem(
 		argErr = errors.Join(argErr, errors.New("invalid subgroup"))
 	}
 
-	if weekday == time.Sunday {
-		argErr = errors.Join(argErr, errors.New("item can not be created for sunday"))
+	if !s.WorkingWeek.OrDefault().Contains(weekday) {
+		argErr = errors.Join(argErr, fmt.Errorf("item can not be created for %s: not a working day", weekday))
 	}
 
 	if studentsCount < 0 {
@@ -185,29 +399,67 @@ func (s *CycledSchedule) AddItem(
 		argErr = errors.Join(argErr, errors.New("empty discipline"))
 	}
 
+	it, err := NewItemTeachers(teachers)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	dur, err := NewItemDuration(duration)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
 	wt, err := NewWeekType(weektype)
 	if err != nil {
 		argErr = errors.Join(argErr, err)
 	}
 
+	cw, err := NewWeeks(cycleWeeks, s.CycleLength)
+	if err != nil {
+		argErr = errors.Join(argErr, fmt.Errorf("invalid cycle weeks: %w", err))
+	}
+
+	if len(cw) > 0 && wt != WeekTypeBoth {
+		argErr = errors.Join(argErr, errors.New("cycle weeks can not be combined with odd or even week type"))
+	}
+
+	w, err := NewWeeks(weeks, MaxWeekNumber)
+	if err != nil {
+		argErr = errors.Join(argErr, fmt.Errorf("invalid weeks: %w", err))
+	}
+
 	lt, err := NewItemLessonType(lessonType)
 	if err != nil {
 		argErr = errors.Join(argErr, err)
 	}
 
+	location, err = NewLocation(int8(location.Kind), location.MeetingURL, location.Address)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	if location.Kind != LocationKindCabinet {
+		cabinet = Cabinet{}
+	}
+
 	if argErr != nil {
 		return errors.Join(ErrInvalidData, argErr)
 	}
 
 	item := ScheduleItem{
+		ID:            uuid.New(),
 		Discipline:    discipline,
-		TeacherID:     teacherID,
+		Teachers:      it,
 		StudentsCount: studentsCount,
 		Weekday:       weekday,
 		LessonNumber:  lessonNumber,
+		Duration:      dur,
 		Subgroup:      subgroup,
 		Weektype:      &wt,
+		CycleWeeks:    cw,
+		Weeks:         w,
 		LessonType:    lt,
+		Location:      location,
 		Cabinet:       cabinet,
 	}
 
@@ -221,7 +473,7 @@ func (s *CycledSchedule) AddItem(
 }
 
 // RemoveItem
-func (s *CycledSchedule) RemoveItem(weekday time.Weekday, lessonNumber, subgroup, weektype int8) error {
+func (s *CycledSchedule) RemoveItem(weekday time.Weekday, lessonNumber, subgroup, weektype int8, cycleWeeks, weeks []int) error {
 	var argErr error
 
 	if lessonNumber < 0 {
@@ -237,12 +489,24 @@ func (s *CycledSchedule) RemoveItem(weekday time.Weekday, lessonNumber, subgroup
 		argErr = errors.Join(argErr, err)
 	}
 
+	cw, err := NewWeeks(cycleWeeks, s.CycleLength)
+	if err != nil {
+		argErr = errors.Join(argErr, fmt.Errorf("invalid cycle weeks: %w", err))
+	}
+
+	w, err := NewWeeks(weeks, MaxWeekNumber)
+	if err != nil {
+		argErr = errors.Join(argErr, fmt.Errorf("invalid weeks: %w", err))
+	}
+
 	if argErr != nil {
 		return errors.Join(ErrInvalidData, argErr)
 	}
 
+	target := ScheduleItem{Weektype: &wt, CycleWeeks: cw, Weeks: w}
+
 	idx := slices.IndexFunc(s.Items[weekday], func(item ScheduleItem) bool {
-		return item.LessonNumber == lessonNumber && item.Subgroup == subgroup && *item.Weektype == wt
+		return item.LessonNumber == lessonNumber && item.Subgroup == subgroup && item.sameWeekPattern(target)
 	})
 
 	if idx < 0 {
@@ -265,25 +529,192 @@ func (s *CycledSchedule) validateItem(item *ScheduleItem) error {
 	}
 
 	for _, current := range items {
+		if !current.OverlapsLessons(*item) {
+			continue
+		}
+
 		subgroupConflict :=
 			current.Subgroup == item.Subgroup ||
 				current.Subgroup == 0 ||
 				item.Subgroup == 0
 
-		conflict := (*current.Weektype == *item.Weektype && subgroupConflict) ||
-			(*current.Weektype == WeekTypeBoth && (*item.Weektype != WeekTypeBoth || subgroupConflict)) ||
-			(*item.Weektype == WeekTypeBoth && (*current.Weektype != WeekTypeBoth || subgroupConflict))
+		week, ok := s.firstSharedWeek(current, *item)
+		if !ok {
+			continue
+		}
+
+		// subgroups can share lesson only when both items have the same weeks rule
+		if subgroupConflict || !current.sameWeekPattern(*item) {
+			return fmt.Errorf("%w: duplicate lesson for this weekday and subgroup on week %d", ErrItemConflict, week)
+		}
 
-		if current.LessonNumber == item.LessonNumber && conflict {
-			return fmt.Errorf("%w: duplicate lesson for this weekday and subgroup on week %v", ErrItemConflict, current.Weektype)
+		if current.sharesTeacher(*item) {
+			return fmt.Errorf("%w: teacher already has lesson %s for another subgroup on week %d", ErrItemConflict, current.Discipline, week)
 		}
 	}
 
 	return nil
 }
 
+// replaceInItems applies replace to items taking place since date and checks items for conflicts again. Item which
+// takes place both before and since date is split: item keeps weeks before date and its copy with new id gets
+// replacement for the rest of weeks. Lessons of other subgroups at the same time are split on the same week, as
+// subgroups share lesson only with the same weeks rule. Weeks are counted from start date of schedule.
+// Schedule is left unchanged on error
+func (s *CycledSchedule) replaceInItems(since time.Time, replace func(item *ScheduleItem) bool) (int, error) {
+	since = truncateToDate(since.In(s.StartDate.Location()))
+	if s.EndDate.Before(since) {
+		return 0, nil
+	}
+
+	type replacement struct {
+		item, replaced ScheduleItem
+		first          int
+		replace, split bool
+	}
+
+	items := s.ListItem()
+	list := make([]replacement, len(items))
+	for i, item := range items {
+		r := replacement{item: item, replaced: item, first: s.firstWeekSince(item.Weekday, since)}
+
+		if replace(&r.replaced) {
+			_, _, occursBefore, occursSince := s.splitWeeks(item, r.first)
+			r.replace = occursSince
+			r.split = occursBefore && occursSince
+		}
+
+		list[i] = r
+	}
+
+	for found := true; found; {
+		found = false
+		for i := range list {
+			if list[i].split {
+				continue
+			}
+
+			for _, other := range list {
+				if !other.split || other.item.Weekday != list[i].item.Weekday || !other.item.OverlapsLessons(list[i].item) {
+					continue
+				}
+
+				if _, _, occursBefore, occursSince := s.splitWeeks(list[i].item, list[i].first); occursBefore && occursSince {
+					list[i].split, found = true, true
+				}
+
+				break
+			}
+		}
+	}
+
+	prev := s.Items
+
+	s.Items = make(map[time.Weekday][]ScheduleItem, len(prev))
+
+	var changed int
+	for _, r := range list {
+		result := []ScheduleItem{r.item}
+
+		switch {
+		case r.split:
+			past, future, _, _ := s.splitWeeks(r.item, r.first)
+
+			rest := r.item
+			if r.replace {
+				rest = r.replaced
+			}
+
+			rest.ID = uuid.New()
+			rest.Weeks = future
+			r.item.Weeks = past
+			result = []ScheduleItem{r.item, rest}
+		case r.replace:
+			result = []ScheduleItem{r.replaced}
+		}
+
+		if r.replace {
+			changed++
+		}
+
+		for _, it := range result {
+			if err := s.validateItem(&it); err != nil {
+				s.Items = prev
+				return 0, errors.Join(ErrInvalidData, err)
+			}
+
+			s.Items[it.Weekday] = append(s.Items[it.Weekday], it)
+		}
+	}
+
+	return changed, nil
+}
+
+// firstWeekSince returns number of the first week which lesson on weekday takes place since date
+func (s *CycledSchedule) firstWeekSince(weekday time.Weekday, since time.Time) int {
+	start := truncateToDate(s.StartDate)
+	if !since.After(start) {
+		return 1
+	}
+
+	week := WeekNumber(start, since)
+
+	weekStart := start.AddDate(0, 0, 7*(week-1))
+	if date := weekStart.AddDate(0, 0, (int(weekday)-int(weekStart.Weekday())+7)%7); date.Before(since) {
+		week++
+	}
+
+	return week
+}
+
+// splitWeeks splits weeks of item into weeks before first one and the rest, item without explicit weeks takes all
+// weeks of schedule. Reports whether item takes place on weeks of each part
+func (s *CycledSchedule) splitWeeks(item ScheduleItem, first int) (past, future Weeks, occursBefore, occursSince bool) {
+	last := min(WeekNumber(s.StartDate, s.EndDate), MaxWeekNumber)
+	if len(item.Weeks) > 0 {
+		last = max(last, item.Weeks[len(item.Weeks)-1])
+	}
+
+	for week := 1; week <= last; week++ {
+		if len(item.Weeks) > 0 && !item.Weeks.Contains(week) {
+			continue
+		}
+
+		occurs := item.OccursOnWeek(week, s.CycleLength)
+		if week < first {
+			past = append(past, week)
+			occursBefore = occursBefore || occurs
+		} else {
+			future = append(future, week)
+			occursSince = occursSince || occurs
+		}
+	}
+
+	return past, future, occursBefore, occursSince
+}
+
+// firstSharedWeek returns first education week on which both items take place.
+// Without explicit weeks items are periodic with period dividing 2*CycleLength
+func (s *CycledSchedule) firstSharedWeek(a, b ScheduleItem) (int, bool) {
+	horizon := 2 * max(s.CycleLength, 1)
+	for _, weeks := range []Weeks{a.Weeks, b.Weeks} {
+		if len(weeks) > 0 {
+			horizon = max(horizon, weeks[len(weeks)-1])
+		}
+	}
+
+	for week := 1; week <= horizon; week++ {
+		if a.OccursOnWeek(week, s.CycleLength) && b.OccursOnWeek(week, s.CycleLength) {
+			return week, true
+		}
+	}
+
+	return 0, false
+}
+
 type CalendarSchedule struct {
-	Items []ScheduleItem
+	WorkingWeek WorkingWeek
+	Items       []ScheduleItem
 }
 
 // CalendarScheduleFromCycled returns Calendar Schedule based on Cycled schedule Start and End dates
@@ -297,8 +728,10 @@ func CalendarScheduleFromCycled(eduGroupID uuid.UUID, semester int, cycled *Cycl
 	svc := NewScheduleService()
 
 	var items []ScheduleItem
+	workingWeek := cycled.WorkingWeek.OrDefault()
+
 	for d := cycled.StartDate; !d.After(cycled.EndDate); d = d.AddDate(0, 0, 1) {
-		if d.Weekday() == time.Sunday {
+		if !workingWeek.Contains(d.Weekday()) {
 			continue
 		}
 
@@ -307,8 +740,11 @@ func CalendarScheduleFromCycled(eduGroupID uuid.UUID, semester int, cycled *Cycl
 			return nil, fmt.Errorf("get item for date %s error: %w", d.Format(time.DateOnly), err)
 		}
 
-		if len(dateItems) > 0 {
-			items = append(items, dateItems...)
+		for _, item := range dateItems {
+			// each occurrence of cycled item becomes separate calendar item. Id is derived from cycled item
+			// and date, so repeated conversion gives the same ids
+			item.ID = uuid.NewSHA1(item.ID, []byte(d.Format(time.DateOnly)))
+			items = append(items, item)
 		}
 	}
 
@@ -318,7 +754,8 @@ func CalendarScheduleFromCycled(eduGroupID uuid.UUID, semester int, cycled *Cycl
 		Semester:   semester,
 		Type:       ScheduleTypeCalendar,
 		Calendar: &CalendarSchedule{
-			Items: items,
+			WorkingWeek: workingWeek,
+			Items:       items,
 		},
 	}, nil
 }
@@ -336,13 +773,15 @@ func (s *CalendarSchedule) Validate() error {
 // AddItem
 func (s *CalendarSchedule) AddItem(
 	discipline string,
-	teacherID uuid.UUID,
+	teachers []ItemTeacher,
 	date time.Time,
 	studentsCount int16,
 	lessonNumber int8,
+	duration int8,
 	subgroup int8,
 	weeknum int,
 	lessonType int8,
+	location Location,
 	cabinet Cabinet,
 ) error {
 	var argErr error
@@ -367,8 +806,18 @@ func (s *CalendarSchedule) AddItem(
 	date = time.Date(y, m, d, 0, 0, 0, 0, date.Location())
 
 	weekday := date.Weekday()
-	if weekday == time.Sunday {
-		argErr = errors.Join(argErr, errors.New("schedule item can not be added for sunday"))
+	if !s.WorkingWeek.OrDefault().Contains(weekday) {
+		argErr = errors.Join(argErr, fmt.Errorf("schedule item can not be added for %s: not a working day", weekday))
+	}
+
+	it, err := NewItemTeachers(teachers)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	dur, err := NewItemDuration(duration)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
 	}
 
 	lt, err := NewItemLessonType(lessonType)
@@ -376,20 +825,32 @@ func (s *CalendarSchedule) AddItem(
 		argErr = errors.Join(argErr, err)
 	}
 
+	location, err = NewLocation(int8(location.Kind), location.MeetingURL, location.Address)
+	if err != nil {
+		argErr = errors.Join(argErr, err)
+	}
+
+	if location.Kind != LocationKindCabinet {
+		cabinet = Cabinet{}
+	}
+
 	if argErr != nil {
 		return errors.Join(ErrInvalidData, argErr)
 	}
 
 	item := ScheduleItem{
+		ID:            uuid.New(),
 		Discipline:    discipline,
-		TeacherID:     teacherID,
+		Teachers:      it,
 		Date:          &date,
 		StudentsCount: studentsCount,
 		Weekday:       weekday,
 		LessonNumber:  lessonNumber,
+		Duration:      dur,
 		Subgroup:      subgroup,
 		Weeknum:       &weeknum,
 		LessonType:    lt,
+		Location:      location,
 		Cabinet:       cabinet,
 	}
 
@@ -431,6 +892,32 @@ func (s *CalendarSchedule) RemoveItem(date time.Time, lessonNumber, subgroup int
 	return nil
 }
 
+// replaceInItems applies replace to items since provided date and checks items for conflicts again.
+// Schedule is left unchanged on error
+func (s *CalendarSchedule) replaceInItems(since time.Time, replace func(item *ScheduleItem) bool) (int, error) {
+	prev := s.Items
+
+	s.Items = make([]ScheduleItem, 0, len(prev))
+
+	var changed int
+	for _, item := range prev {
+		if item.Date != nil && !item.Date.Before(truncateToDate(since.In(item.Date.Location()))) {
+			if replace(&item) {
+				changed++
+			}
+		}
+
+		if err := s.validateItem(&item); err != nil {
+			s.Items = prev
+			return 0, errors.Join(ErrInvalidData, err)
+		}
+
+		s.Items = append(s.Items, item)
+	}
+
+	return changed, nil
+}
+
 func (s *CalendarSchedule) validateItem(item *ScheduleItem) error {
 	if item.Weeknum == nil {
 		return fmt.Errorf("weeknum can not be empty in calendar schedule")
@@ -441,9 +928,17 @@ func (s *CalendarSchedule) validateItem(item *ScheduleItem) error {
 	}
 
 	for _, current := range s.Items {
-		if current.Date.Equal(*item.Date) && current.LessonNumber == item.LessonNumber && current.Subgroup == item.Subgroup {
+		if !current.Date.Equal(*item.Date) || !current.OverlapsLessons(*item) {
+			continue
+		}
+
+		if current.Subgroup == item.Subgroup {
 			return fmt.Errorf("%w: duplicate lesson for this subgroup on date %s", ErrItemConflict, item.Date.Format(time.DateOnly))
 		}
+
+		if current.sharesTeacher(*item) {
+			return fmt.Errorf("%w: teacher already has lesson %s for another subgroup on date %s", ErrItemConflict, current.Discipline, item.Date.Format(time.DateOnly))
+		}
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package schedules
 
 import (
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -90,7 +91,7 @@ func TestCycledSchedule_AddItem(t *testing.T) {
 				},
 				result: &ScheduleItem{
 					Discipline:    "test",
-					TeacherID:     teacherID,
+					Teachers:      []ItemTeacher{{TeacherID: teacherID}},
 					Weekday:       time.Monday,
 					StudentsCount: 0,
 					LessonNumber:  0,
@@ -210,13 +211,17 @@ func TestCycledSchedule_AddItem(t *testing.T) {
 
 				err = schedule.Cycled.AddItem(
 					suitcase.discipline,
-					suitcase.teacherID,
+					[]ItemTeacher{{TeacherID: suitcase.teacherID}},
 					suitcase.weekday,
 					suitcase.studentsCount,
 					suitcase.lessonNumber,
+					0,
 					suitcase.subgroup,
 					suitcase.weektype,
+					nil,
+					nil,
 					suitcase.lessonType,
+					Location{},
 					suitcase.cabinet,
 				)
 
@@ -488,13 +493,17 @@ func TestCycledSchedule_AddItem(t *testing.T) {
 
 				err = schedule.Cycled.AddItem(
 					suitcase.existing.discipline,
-					suitcase.existing.teacherID,
+					[]ItemTeacher{{TeacherID: suitcase.existing.teacherID}},
 					suitcase.existing.weekday,
 					suitcase.existing.studentsCount,
 					suitcase.existing.lessonNumber,
+					0,
 					suitcase.existing.subgroup,
 					suitcase.existing.weektype,
+					nil,
+					nil,
 					suitcase.existing.lessonType,
+					Location{},
 					suitcase.existing.cabinet,
 				)
 				if err != nil {
@@ -503,13 +512,17 @@ func TestCycledSchedule_AddItem(t *testing.T) {
 
 				err = schedule.Cycled.AddItem(
 					suitcase.conflicting.discipline,
-					suitcase.conflicting.teacherID,
+					[]ItemTeacher{{TeacherID: suitcase.conflicting.teacherID}},
 					suitcase.conflicting.weekday,
 					suitcase.conflicting.studentsCount,
 					suitcase.conflicting.lessonNumber,
+					0,
 					suitcase.conflicting.subgroup,
 					suitcase.conflicting.weektype,
+					nil,
+					nil,
 					suitcase.conflicting.lessonType,
+					Location{},
 					suitcase.conflicting.cabinet,
 				)
 				if !errors.Is(err, ErrItemConflict) {
@@ -559,7 +572,7 @@ func cmpItems(i1, i2 *ScheduleItem) bool {
 	}
 
 	return i1.Discipline == i2.Discipline &&
-		i1.TeacherID == i2.TeacherID &&
+		slices.Equal(i1.Teachers, i2.Teachers) &&
 		i1.Weekday == i2.Weekday &&
 		i1.StudentsCount == i2.StudentsCount &&
 		i1.LessonNumber == i2.LessonNumber &&
@@ -570,3 +583,541 @@ func cmpItems(i1, i2 *ScheduleItem) bool {
 		(i1.Weektype == nil && i2.Weektype == nil || (*i1.Weektype == *i2.Weektype)) &&
 		(i1.Weeknum == nil && i2.Weeknum == nil || (*i1.Weeknum == *i2.Weeknum))
 }
+
+func TestCycledSchedule_Rotation(t *testing.T) {
+	educationStart := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := NewCycledSchedule(uuid.New(), 1, educationStart, educationStart.AddDate(0, 0, 7*12-1), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schedule.Cycled.SetCycleLength(4); err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(discipline string, subgroup int8, cycleWeeks, weeks []int) error {
+		return schedule.Cycled.AddItem(discipline, []ItemTeacher{{TeacherID: uuid.New()}}, time.Monday, 0, 0, 0, subgroup, int8(WeekTypeBoth), cycleWeeks, weeks, int8(ItemTypeLecture), Location{}, Cabinet{})
+	}
+
+	if err := add("first", 0, []int{1}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := add("third", 0, []int{3}, []int{1, 2, 3, 4, 5, 6, 7, 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := add("conflict", 0, []int{1, 2}, nil); !errors.Is(err, ErrItemConflict) {
+		t.Fatalf("expected conflict error, got: %v", err)
+	}
+
+	if err := add("out of cycle", 0, []int{5}, nil); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected invalid data error, got: %v", err)
+	}
+
+	if err := schedule.Cycled.SetCycleLength(2); err == nil {
+		t.Fatal("expected error on shrinking cycle below used cycle weeks")
+	}
+
+	items, err := NewScheduleService().ListScheduleItemByDateRange(schedule, educationStart, schedule.Cycled.StartDate, schedule.Cycled.EndDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var weeks []int
+	for _, item := range items {
+		weeks = append(weeks, *item.Weeknum)
+	}
+
+	// "first" on weeks 1, 5, 9 and "third" on weeks 3, 7 only
+	expected := []int{1, 3, 5, 7, 9}
+	slices.Sort(weeks)
+	if !slices.Equal(weeks, expected) {
+		t.Errorf("expected items on weeks %v, got: %v", expected, weeks)
+	}
+}
+
+func TestSchedule_WorkingWeek(t *testing.T) {
+	educationStart := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := NewCycledSchedule(uuid.New(), 1, educationStart, educationStart.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(weekday time.Weekday) error {
+		return schedule.Cycled.AddItem("discipline", []ItemTeacher{{TeacherID: uuid.New()}}, weekday, 0, 0, 0, 0, int8(WeekTypeBoth), nil, nil, int8(ItemTypeLecture), Location{}, Cabinet{})
+	}
+
+	if err := add(time.Sunday); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected invalid data error for sunday in default week, got: %v", err)
+	}
+
+	if err := add(time.Saturday); err != nil {
+		t.Fatal(err)
+	}
+
+	fiveDays := MustWorkingWeek(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	if err := schedule.SetWorkingWeek(fiveDays); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected invalid data error on removing day with items, got: %v", err)
+	}
+
+	if err := schedule.SetWorkingWeek(fiveDays.Union(MustWorkingWeek(time.Saturday, time.Sunday))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := add(time.Sunday); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := NewScheduleService().ListScheduleItemByDateRange(schedule, educationStart, schedule.Cycled.StartDate, schedule.Cycled.EndDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 4 {
+		t.Errorf("expected 4 items on two weeks, got: %d", len(items))
+	}
+}
+
+// newTestSchedule cycled schedule of two weeks starting on monday
+func newTestSchedule(t *testing.T) *Schedule {
+	t.Helper()
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := NewCycledSchedule(uuid.New(), 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return schedule
+}
+
+// testItem monday laboratory of both weeks, taught by new teacher when teachers are not set
+type testItem struct {
+	lessonNumber int8
+	duration     int8
+	subgroup     int8
+	teachers     []ItemTeacher
+}
+
+func (i testItem) add(schedule *Schedule) error {
+	teachers := i.teachers
+	if teachers == nil {
+		teachers = []ItemTeacher{{TeacherID: uuid.New()}}
+	}
+
+	return schedule.Cycled.AddItem("discipline", teachers, time.Monday, 0, i.lessonNumber, i.duration, i.subgroup, int8(WeekTypeBoth), nil, nil, int8(ItemTypeLaboratory), Location{}, Cabinet{})
+}
+
+func TestCycledSchedule_DoubleLesson(t *testing.T) {
+	schedule := newTestSchedule(t)
+
+	// steps are applied in order to the same schedule
+	steps := []struct {
+		name string
+		item testItem
+		err  error
+	}{
+		{"double lesson", testItem{lessonNumber: 2, duration: 2}, nil},
+		{"second period of double lesson", testItem{lessonNumber: 3}, ErrItemConflict},
+		{"overlapping double lesson", testItem{lessonNumber: 1, duration: 2}, ErrItemConflict},
+		{"lesson after double lesson", testItem{lessonNumber: 4}, nil},
+		{"too long lesson", testItem{lessonNumber: 5, duration: MaxItemDuration + 1}, ErrInvalidData},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if err := step.item.add(schedule); !errors.Is(err, step.err) {
+				t.Fatalf("expected error %v, got: %v", step.err, err)
+			}
+		})
+	}
+
+	item := schedule.Cycled.ListItemByWeekday(time.Monday)[0]
+	if !slices.Equal(item.LessonNumbers(), []int8{2, 3}) {
+		t.Errorf("expected lesson numbers [2 3], got: %v", item.LessonNumbers())
+	}
+}
+
+func TestCycledSchedule_Teachers(t *testing.T) {
+	schedule := newTestSchedule(t)
+
+	main, assistant, other := uuid.New(), uuid.New(), uuid.New()
+
+	// steps are applied in order to the same schedule
+	steps := []struct {
+		name string
+		item testItem
+		err  error
+	}{
+		{"without main teacher", testItem{subgroup: 1, teachers: []ItemTeacher{{TeacherID: main, Role: TeacherRoleAssistant}}}, ErrInvalidData},
+		{"duplicate teacher", testItem{subgroup: 1, teachers: []ItemTeacher{{TeacherID: main}, {TeacherID: main, Role: TeacherRoleAssistant}}}, ErrInvalidData},
+		{"unknown role", testItem{subgroup: 1, teachers: []ItemTeacher{{TeacherID: main}, {TeacherID: assistant, Role: 5}}}, ErrInvalidData},
+		{"main teacher with assistant", testItem{subgroup: 1, teachers: []ItemTeacher{{TeacherID: assistant, Role: TeacherRoleAssistant}, {TeacherID: main}}}, nil},
+		{"assistant busy with another subgroup", testItem{subgroup: 2, teachers: []ItemTeacher{{TeacherID: other}, {TeacherID: assistant, Role: TeacherRoleAssistant}}}, ErrItemConflict},
+		{"another subgroup", testItem{subgroup: 2, teachers: []ItemTeacher{{TeacherID: other}}}, nil},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if err := step.item.add(schedule); !errors.Is(err, step.err) {
+				t.Fatalf("expected error %v, got: %v", step.err, err)
+			}
+		})
+	}
+
+	item := schedule.Cycled.ListItemByWeekday(time.Monday)[0]
+	if item.MainTeacherID() != main || !item.HasTeacher(assistant) {
+		t.Errorf("expected main teacher first and assistant in teachers list, got: %v", item.Teachers)
+	}
+}
+
+func TestTeacherRole_String(t *testing.T) {
+	cases := map[TeacherRole]string{
+		TeacherRoleMain:      "main",
+		TeacherRoleAssistant: "assistant",
+		TeacherRole(-1):      "unknown",
+		TeacherRole(5):       "unknown",
+	}
+
+	for role, want := range cases {
+		if got := role.String(); got != want {
+			t.Errorf("expected %q for role %d, got: %q", want, role, got)
+		}
+	}
+}
+
+func TestSchedule_ReplaceTeacher(t *testing.T) {
+	from, to, busy := uuid.New(), uuid.New(), uuid.New()
+
+	// schedule of two weeks from 2025-09-01 with two items of replaced teacher
+	newSchedule := func(t *testing.T) *Schedule {
+		schedule := newTestSchedule(t)
+
+		for _, item := range []testItem{
+			{lessonNumber: 1, subgroup: 1, teachers: []ItemTeacher{{TeacherID: from}}},
+			{lessonNumber: 1, subgroup: 2, teachers: []ItemTeacher{{TeacherID: busy}}},
+			{lessonNumber: 2, teachers: []ItemTeacher{{TeacherID: to}, {TeacherID: from, Role: TeacherRoleAssistant}}},
+		} {
+			if err := item.add(schedule); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		return schedule
+	}
+
+	date := func(day int) time.Time {
+		return time.Date(2025, time.September, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := map[string]struct {
+		from, to uuid.UUID
+		since    time.Time
+		changed  int
+		err      error
+	}{
+		"before start": {
+			from: from, to: to, since: date(1).AddDate(0, 0, -7), changed: 2,
+		},
+		"on start day": {
+			from: from, to: to, since: date(1), changed: 2,
+		},
+		"after end": {
+			from: from, to: to, since: date(15),
+		},
+		"teacher busy at the same time": {
+			from: from, to: busy, since: date(1), err: ErrItemConflict,
+		},
+		"schedule in progress without teacher": {
+			from: uuid.New(), to: to, since: date(3),
+		},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			schedule := newSchedule(t)
+
+			changed, err := schedule.ReplaceTeacher(c.from, c.to, c.since)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("expected error %v, got: %v", c.err, err)
+			}
+
+			if changed != c.changed {
+				t.Errorf("expected %d changed items, got: %d", c.changed, changed)
+			}
+
+			var kept int
+			for _, item := range schedule.ListItem() {
+				if item.HasTeacher(from) {
+					kept++
+				}
+			}
+
+			if kept != 2-c.changed {
+				t.Errorf("expected %d items of replaced teacher to be kept, got: %d", 2-c.changed, kept)
+			}
+		})
+	}
+
+	t.Run("merge teachers", func(t *testing.T) {
+		schedule := newSchedule(t)
+
+		if _, err := schedule.ReplaceTeacher(from, to, date(1)); err != nil {
+			t.Fatal(err)
+		}
+
+		merged := schedule.Cycled.ListItemByWeekday(time.Monday)[2]
+		if !slices.Equal(merged.Teachers, []ItemTeacher{{TeacherID: to}}) {
+			t.Errorf("expected teachers to be merged keeping main role, got: %v", merged.Teachers)
+		}
+	})
+
+	t.Run("schedule in progress", func(t *testing.T) {
+		schedule := newSchedule(t)
+		original := schedule.Cycled.ListItemByWeekday(time.Monday)[0]
+
+		// lessons of mondays since wednesday of the first week take place on the second week only
+		changed, err := schedule.ReplaceTeacher(from, to, date(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if changed != 2 {
+			t.Errorf("expected 2 changed items, got: %d", changed)
+		}
+
+		// replaced items and lesson of another subgroup at the same time are split into weeks before and since date
+		if items := schedule.ListItem(); len(items) != 6 {
+			t.Fatalf("expected 6 items, got: %+v", items)
+		}
+
+		past, err := schedule.GetItem(original.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !past.HasTeacher(from) || !slices.Equal(past.Weeks, Weeks{1}) {
+			t.Errorf("expected item to keep replaced teacher on the first week, got: %v of weeks %v", past.Teachers, past.Weeks)
+		}
+
+		calendar, err := CalendarScheduleFromCycled(schedule.EduGroupID, schedule.Semester, schedule.Cycled, date(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lessons, kept := make(map[int]int), 0
+		for _, item := range calendar.Calendar.ListItem() {
+			lessons[item.Date.Day()]++
+
+			if !item.HasTeacher(from) {
+				continue
+			}
+
+			if item.Date.Before(date(3)) {
+				kept++
+			} else {
+				t.Errorf("expected teacher to be replaced since %s, got item of %s: %v", date(3).Format(time.DateOnly), item.Date.Format(time.DateOnly), item.Teachers)
+			}
+		}
+
+		if kept != 2 {
+			t.Errorf("expected 2 lessons of replaced teacher before %s, got: %d", date(3).Format(time.DateOnly), kept)
+		}
+
+		if lessons[1] != 3 || lessons[8] != 3 {
+			t.Errorf("expected 3 lessons on both mondays, got: %v", lessons)
+		}
+	})
+
+	t.Run("calendar schedule", func(t *testing.T) {
+		cycled := newSchedule(t)
+
+		schedule, err := CalendarScheduleFromCycled(cycled.EduGroupID, cycled.Semester, cycled.Cycled, date(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		changed, err := schedule.ReplaceTeacher(from, to, date(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if changed != 2 {
+			t.Errorf("expected 2 changed items of the second week, got: %d", changed)
+		}
+
+		for _, item := range schedule.Calendar.ListItem() {
+			if item.HasTeacher(from) && !item.Date.Before(date(3)) {
+				t.Errorf("expected teacher to be replaced since %s, got item of %s: %v", date(3).Format(time.DateOnly), item.Date.Format(time.DateOnly), item.Teachers)
+			}
+		}
+	})
+}
+
+func TestSchedule_CheckVersion(t *testing.T) {
+	schedule := Schedule{Version: 3}
+
+	if err := schedule.CheckVersion(nil); err != nil {
+		t.Errorf("expected any version to match nil, got: %v", err)
+	}
+
+	current, stale := 3, 2
+	if err := schedule.CheckVersion(&current); err != nil {
+		t.Errorf("expected current version to match, got: %v", err)
+	}
+
+	if err := schedule.CheckVersion(&stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected version conflict for stale version, got: %v", err)
+	}
+}
+
+func TestSchedule_UpdateItem(t *testing.T) {
+	schedule := newTestSchedule(t)
+
+	for _, item := range []testItem{{lessonNumber: 1}, {lessonNumber: 2}} {
+		if err := item.add(schedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := schedule.Cycled.ListItemByWeekday(time.Monday)
+	first, second := items[0].ID, items[1].ID
+	if first == uuid.Nil || first == second {
+		t.Fatalf("expected items to have distinct ids, got: %s, %s", first, second)
+	}
+
+	// steps are applied in order to the same schedule, failed steps must leave it unchanged
+	steps := []struct {
+		name string
+		id   uuid.UUID
+		item testItem
+		err  error
+	}{
+		{"move to free lesson", first, testItem{lessonNumber: 3}, nil},
+		{"move to taken lesson", first, testItem{lessonNumber: 2}, ErrItemConflict},
+		{"unknown item", uuid.New(), testItem{lessonNumber: 4}, ErrItemNotFound},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			err := schedule.UpdateItem(step.id, func() error {
+				return step.item.add(schedule)
+			})
+			if !errors.Is(err, step.err) {
+				t.Fatalf("expected error %v, got: %v", step.err, err)
+			}
+
+			item, err := schedule.GetItem(first)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if item.LessonNumber != 3 || len(schedule.ListItem()) != 2 {
+				t.Errorf("expected moved item to keep its id on lesson 3, got: %v", schedule.ListItem())
+			}
+		})
+	}
+
+	if err := schedule.RemoveItemByID(second); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := schedule.GetItem(second); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("expected removed item not to be found, got: %v", err)
+	}
+}
+
+func TestBellSchedule_ItemTime(t *testing.T) {
+	if err := DefaultBellSchedule.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	lessonTime, ok := DefaultBellSchedule.ItemTime(ScheduleItem{LessonNumber: 1, Duration: 2})
+	if !ok {
+		t.Fatal("expected double lesson to fit into bell schedule")
+	}
+
+	if lessonTime.Start != DefaultBellSchedule[1].Start || lessonTime.End != DefaultBellSchedule[2].End {
+		t.Errorf("expected double lesson to last from start of second to end of third period, got: %v", lessonTime)
+	}
+
+	if _, ok := DefaultBellSchedule.ItemTime(ScheduleItem{LessonNumber: int8(len(DefaultBellSchedule)) - 1, Duration: 2}); ok {
+		t.Error("expected lesson out of bell schedule to be reported")
+	}
+}
+
+func TestNewLocation(t *testing.T) {
+	cases := map[string]struct {
+		kind       LocationKind
+		meetingURL string
+		address    string
+		wantErr    bool
+	}{
+		"cabinet":               {kind: LocationKindCabinet},
+		"online":                {kind: LocationKindOnline, meetingURL: "https://meet.example.com/abc"},
+		"online without url":    {kind: LocationKindOnline, wantErr: true},
+		"online with plain url": {kind: LocationKindOnline, meetingURL: "meet.example.com/abc", wantErr: true},
+		"external":              {kind: LocationKindExternal, address: "Lenina st. 1"},
+		"external blank":        {kind: LocationKindExternal, address: "  ", wantErr: true},
+		"unknown kind":          {kind: 5, wantErr: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewLocation(int8(c.kind), c.meetingURL, c.address)
+			if (err != nil) != c.wantErr {
+				t.Errorf("expected error: %v, got: %v", c.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseWeeks(t *testing.T) {
+	weeks, err := ParseWeeks("10, 1-3,2,5", MaxWeekNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if weeks.String() != "1-3,5,10" {
+		t.Errorf("expected 1-3,5,10, got: %s", weeks.String())
+	}
+
+	for _, s := range []string{"0-2", "3-1", "a", "54"} {
+		if _, err := ParseWeeks(s, MaxWeekNumber); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestCycledSchedule_FirstWeekSince(t *testing.T) {
+	schedule := newTestSchedule(t)
+
+	date := func(day int) time.Time {
+		return time.Date(2025, time.September, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		weekday time.Weekday
+		since   time.Time
+		want    int
+	}{
+		{time.Monday, date(1).AddDate(0, 0, -7), 1},
+		{time.Monday, date(1), 1},
+		{time.Monday, date(2), 2},
+		{time.Tuesday, date(2), 1},
+		{time.Saturday, date(3), 1},
+		{time.Monday, date(8), 2},
+		{time.Wednesday, date(10), 2},
+		{time.Tuesday, date(10), 3},
+	}
+
+	for _, c := range cases {
+		if got := schedule.Cycled.firstWeekSince(c.weekday, c.since); got != c.want {
+			t.Errorf("first week of %s since %s: expected %d, got %d", c.weekday, c.since.Format(time.DateOnly), c.want, got)
+		}
+	}
+}
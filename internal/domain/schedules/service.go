@@ -2,6 +2,7 @@ package schedules
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -21,19 +22,10 @@ func (s *ScheduleService) ListScheduleItemByDate(schedule *CycledSchedule, educa
 		return nil, errors.New("invalid date")
 	}
 
-	days := int(date.Truncate(24*time.Hour).
-		Sub(educationStartDate.Truncate(24*time.Hour)).
-		Hours() / 24)
-
-	weekNumber := days / 7 + 1
-
-	weekType := WeekTypeUneven
-	if weekNumber%2 == 0 {
-		weekType = WeekTypeEven
-	}
+	weekNumber := WeekNumber(educationStartDate, date)
 
 	weekday := date.Weekday()
-	if weekday == time.Sunday {
+	if !schedule.WorkingWeek.OrDefault().Contains(weekday) {
 		return nil, nil
 	}
 
@@ -41,7 +33,7 @@ func (s *ScheduleService) ListScheduleItemByDate(schedule *CycledSchedule, educa
 
 	dayItems := schedule.ListItemByWeekday(weekday)
 	for _, item := range dayItems {
-		if *item.Weektype == weekType || *item.Weektype == WeekTypeBoth {
+		if item.OccursOnWeek(weekNumber, schedule.CycleLength) {
 			item.Date = &date
 			item.Weeknum = &weekNumber
 			result = append(result, item)
@@ -50,3 +42,95 @@ func (s *ScheduleService) ListScheduleItemByDate(schedule *CycledSchedule, educa
 
 	return result, nil
 }
+
+// ListScheduleItemByDateRange returns dated items of cycled or calendar schedule for dates between from and to inclusive
+func (s *ScheduleService) ListScheduleItemByDateRange(schedule *Schedule, educationStartDate time.Time, from, to time.Time) ([]ScheduleItem, error) {
+	if schedule == nil {
+		return nil, errors.New("schedule can not be nil")
+	}
+
+	if from.After(to) {
+		return nil, errors.Join(ErrInvalidData, errors.New("range start is after range end"))
+	}
+
+	from = truncateToDate(from)
+	to = truncateToDate(to)
+
+	var result []ScheduleItem
+
+	switch schedule.Type {
+	case ScheduleTypeCycled:
+		if schedule.Cycled == nil {
+			return nil, nil
+		}
+
+		start := from
+		if scheduleStart := truncateToDate(schedule.Cycled.StartDate.In(from.Location())); start.Before(scheduleStart) {
+			start = scheduleStart
+		}
+
+		if eduStart := truncateToDate(educationStartDate.In(from.Location())); start.Before(eduStart) {
+			start = eduStart
+		}
+
+		end := to
+		if scheduleEnd := truncateToDate(schedule.Cycled.EndDate.In(from.Location())); end.After(scheduleEnd) {
+			end = scheduleEnd
+		}
+
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			items, err := s.ListScheduleItemByDate(schedule.Cycled, educationStartDate, d)
+			if err != nil {
+				return nil, fmt.Errorf("get item for date %s error: %w", d.Format(time.DateOnly), err)
+			}
+
+			result = append(result, items...)
+		}
+	case ScheduleTypeCalendar:
+		if schedule.Calendar == nil {
+			return nil, nil
+		}
+
+		for _, item := range schedule.Calendar.ListItem() {
+			if item.Date == nil {
+				continue
+			}
+
+			date := truncateToDate(item.Date.In(from.Location()))
+			if date.Before(from) || date.After(to) {
+				continue
+			}
+
+			// week of dated item follows academic calendar, stored weeknum may be missing or imported from other calendar
+			if !date.Before(truncateToDate(educationStartDate.In(from.Location()))) {
+				weekNumber := WeekNumber(educationStartDate, date)
+				weektype := WeektypeOfWeek(weekNumber)
+				item.Weeknum = &weekNumber
+				item.Weektype = &weektype
+			}
+
+			result = append(result, item)
+		}
+	default:
+		return nil, errors.New("unknown schedule type")
+	}
+
+	return result, nil
+}
+
+// WeekNumber number of education week for date counting from 1. Weeks are counted by seven days from education
+// start date
+func WeekNumber(educationStartDate, date time.Time) int {
+	sy, sm, sd := educationStartDate.In(date.Location()).Date()
+	start := time.Date(sy, sm, sd, 0, 0, 0, 0, time.UTC)
+
+	dy, dm, dd := date.Date()
+	days := int(time.Date(dy, dm, dd, 0, 0, 0, 0, time.UTC).Sub(start).Hours() / 24)
+
+	return days/7 + 1
+}
+
+func truncateToDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,139 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestScheduleService_ListScheduleItemByDateRange(t *testing.T) {
+	educationStart := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := NewCycledSchedule(uuid.New(), 1, educationStart, educationStart.AddDate(0, 0, 27), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.Cycled.AddItem("odd", []ItemTeacher{{TeacherID: uuid.New()}}, time.Monday, 0, 0, 0, 0, int8(WeekTypeUneven), nil, nil, int8(ItemTypeLecture), Location{}, Cabinet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.Cycled.AddItem("both", []ItemTeacher{{TeacherID: uuid.New()}}, time.Tuesday, 0, 0, 0, 0, int8(WeekTypeBoth), nil, nil, int8(ItemTypeLecture), Location{}, Cabinet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewScheduleService()
+
+	t.Run("whole schedule", func(t *testing.T) {
+		items, err := svc.ListScheduleItemByDateRange(schedule, educationStart, educationStart.AddDate(0, 0, -7), educationStart.AddDate(0, 1, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 2 odd mondays and 4 tuesdays in 4 weeks
+		if len(items) != 6 {
+			t.Fatalf("expected 6 items, got: %d", len(items))
+		}
+
+		for _, item := range items {
+			if item.Date == nil || item.Weeknum == nil {
+				t.Fatalf("expected dated item, got: %+v", item)
+			}
+
+			if item.Date.Before(schedule.Cycled.StartDate) || item.Date.After(schedule.Cycled.EndDate) {
+				t.Errorf("item date %s is out of schedule range", item.Date)
+			}
+		}
+	})
+
+	t.Run("single week", func(t *testing.T) {
+		from := educationStart.AddDate(0, 0, 7)
+
+		items, err := svc.ListScheduleItemByDateRange(schedule, educationStart, from, from.AddDate(0, 0, 6))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(items) != 1 || items[0].Discipline != "both" {
+			t.Fatalf("expected only 'both' item on even week, got: %+v", items)
+		}
+
+		if *items[0].Weeknum != 2 {
+			t.Errorf("expected weeknum 2, got: %d", *items[0].Weeknum)
+		}
+	})
+
+	t.Run("calendar schedule", func(t *testing.T) {
+		calendar, err := CalendarScheduleFromCycled(schedule.EduGroupID, schedule.Semester, schedule.Cycled, educationStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		items, err := svc.ListScheduleItemByDateRange(calendar, educationStart, educationStart, educationStart.AddDate(0, 0, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items, got: %d", len(items))
+		}
+
+		for _, item := range items {
+			if item.Weeknum == nil || *item.Weeknum != 1 || item.Weektype == nil || *item.Weektype != WeekTypeUneven {
+				t.Errorf("expected item of the first odd week, got: %+v", item)
+			}
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := svc.ListScheduleItemByDateRange(schedule, educationStart, educationStart, educationStart.AddDate(0, 0, -1))
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestScheduleService_RankSlot(t *testing.T) {
+	svc := NewScheduleService()
+
+	adjacent := svc.RankSlot([]int8{1, 2}, 3)
+	gap := svc.RankSlot([]int8{1, 2}, 5)
+	window := svc.RankSlot([]int8{1, 3}, 2)
+
+	if adjacent <= gap {
+		t.Errorf("expected adjacent slot (%d) to rank higher than slot with gap (%d)", adjacent, gap)
+	}
+
+	if window <= adjacent {
+		t.Errorf("expected window slot (%d) to rank higher than adjacent slot (%d)", window, adjacent)
+	}
+
+	if early, late := svc.RankSlot(nil, 0), svc.RankSlot(nil, 4); early <= late {
+		t.Errorf("expected early slot (%d) to rank higher than late slot (%d) on empty day", early, late)
+	}
+}
+
+func TestWeekNumber(t *testing.T) {
+	// wednesday
+	springStart := time.Date(2026, time.February, 11, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		date time.Time
+		want int
+	}{
+		{springStart, 1},
+		{time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, time.February, 18, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC), 4},
+		{time.Date(2026, time.February, 11, 23, 0, 0, 0, time.FixedZone("MSK", 3*60*60)), 1},
+	}
+
+	for _, c := range cases {
+		if got := WeekNumber(springStart, c.date); got != c.want {
+			t.Errorf("week number for %s: expected %d, got %d", c.date.Format(time.DateOnly), c.want, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package schedules
+
+import (
+	"slices"
+	"time"
+)
+
+// Slot is lesson position in cycled schedule (Weekday and Weektype) or in calendar schedule (Date)
+type Slot struct {
+	Weekday      time.Weekday
+	LessonNumber int8
+	Weektype     *Weektype
+	Date         *time.Time
+}
+
+const (
+	slotFitAdjacent        = 100
+	slotFitGapPenalty      = 20
+	slotFitWindowBonus     = 10
+	slotFitEmptyDay        = 50
+	slotFitLatePenalty     = 5
+	slotFitComfortLessons  = 3
+	slotFitOverloadPenalty = 15
+)
+
+// RankSlot scores how well lesson number fits into a day which already has dayLessons. Higher is better.
+// Slots adjacent to existing lessons or filling windows between them are preferred, slots creating
+// gaps or overloading the day are penalized. On empty days earlier lessons are preferred
+func (s *ScheduleService) RankSlot(dayLessons []int8, lessonNumber int8) int {
+	lessons := slices.Clone(dayLessons)
+	slices.Sort(lessons)
+	lessons = slices.Compact(lessons)
+
+	if len(lessons) == 0 {
+		return slotFitEmptyDay - slotFitLatePenalty*int(lessonNumber)
+	}
+
+	distance := -1
+	for _, l := range lessons {
+		d := int(l) - int(lessonNumber)
+		if d < 0 {
+			d = -d
+		}
+
+		if distance < 0 || d < distance {
+			distance = d
+		}
+	}
+
+	score := slotFitAdjacent
+	if distance > 1 {
+		score -= slotFitGapPenalty * (distance - 1)
+	}
+
+	if lessonNumber > lessons[0] && lessonNumber < lessons[len(lessons)-1] {
+		score += slotFitWindowBonus
+	}
+
+	if len(lessons) > slotFitComfortLessons {
+		score -= slotFitOverloadPenalty * (len(lessons) - slotFitComfortLessons)
+	}
+
+	return score
+}
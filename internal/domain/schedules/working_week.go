@@ -0,0 +1,89 @@
+package schedules
+
+import (
+	"errors"
+	"time"
+)
+
+// WorkingWeek set of weekdays when lessons can take place. Stored as bitmask by time.Weekday
+type WorkingWeek uint8
+
+// DefaultWorkingWeek six-day week from monday to saturday
+var DefaultWorkingWeek = MustWorkingWeek(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday)
+
+// weekdayOrder weekdays in order of education week
+var weekdayOrder = []time.Weekday{
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+	time.Sunday,
+}
+
+func NewWorkingWeek(days ...time.Weekday) (WorkingWeek, error) {
+	var w WorkingWeek
+
+	for _, d := range days {
+		if d < time.Sunday || d > time.Saturday {
+			return 0, errors.New("unknown weekday")
+		}
+
+		w |= 1 << d
+	}
+
+	if w == 0 {
+		return 0, errors.New("working week must contain at least one day")
+	}
+
+	return w, nil
+}
+
+func MustWorkingWeek(days ...time.Weekday) WorkingWeek {
+	w, err := NewWorkingWeek(days...)
+	if err != nil {
+		panic(err)
+	}
+
+	return w
+}
+
+// Contains reports whether weekday is a working day
+func (w WorkingWeek) Contains(day time.Weekday) bool {
+	return w&(1<<day) != 0
+}
+
+// Days returns working days in order of education week, monday first
+func (w WorkingWeek) Days() []time.Weekday {
+	var days []time.Weekday
+	for _, d := range weekdayOrder {
+		if w.Contains(d) {
+			days = append(days, d)
+		}
+	}
+
+	return days
+}
+
+// OrDefault returns DefaultWorkingWeek for zero value
+func (w WorkingWeek) OrDefault() WorkingWeek {
+	if w == 0 {
+		return DefaultWorkingWeek
+	}
+
+	return w
+}
+
+// Union returns week containing working days of both weeks
+func (w WorkingWeek) Union(other WorkingWeek) WorkingWeek {
+	return w | other
+}
+
+func (w WorkingWeek) Validate() error {
+	if w == 0 || w >= 1<<7 {
+		return errors.New("invalid working week")
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"schedule-generator/internal/application/usecases"
+	"schedule-generator/internal/common"
+	academicyears "schedule-generator/internal/domain/academic_years"
+	"schedule-generator/internal/domain/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type AcademicYearUsecase interface {
+	CreateAcademicYear(ctx context.Context, input usecases.CreateAcademicYearInput, user *users.User) (*usecases.CreateAcademicYearOutput, error)
+	ListAcademicYear(ctx context.Context, user *users.User) (usecases.ListAcademicYearOutput, error)
+	UpdateAcademicYear(ctx context.Context, input usecases.UpdateAcademicYearInput, user *users.User) (*usecases.UpdateAcademicYearOutput, error)
+	DeleteAcademicYear(ctx context.Context, academicYearID uuid.UUID, user *users.User) error
+}
+
+type AcademicYear struct {
+	ID                  uuid.UUID `json:"id"`
+	FacultyID           uuid.UUID `json:"faculty_id"`
+	Year                int       `json:"year"`
+	FallSemesterStart   string    `json:"fall_semester_start"`
+	SpringSemesterStart string    `json:"spring_semester_start"`
+	WeeksFromMonday     bool      `json:"weeks_from_monday"`
+}
+
+type CreateAcademicYearRequest struct {
+	FacultyID           uuid.UUID `json:"faculty_id"`
+	Year                int       `json:"year"`
+	FallSemesterStart   string    `json:"fall_semester_start"`
+	SpringSemesterStart string    `json:"spring_semester_start"`
+	WeeksFromMonday     bool      `json:"weeks_from_monday"`
+}
+
+// CreateAcademicYear - POST /v1/academic-years
+func (h *Handler) CreateAcademicYear(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq CreateAcademicYearRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	fallStart, err := time.ParseInLocation(time.DateOnly, rq.FallSemesterStart, common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	springStart, err := time.ParseInLocation(time.DateOnly, rq.SpringSemesterStart, common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.academicYear.CreateAcademicYear(ctx, usecases.CreateAcademicYearInput{
+		FacultyID:           rq.FacultyID,
+		Year:                rq.Year,
+		FallSemesterStart:   fallStart,
+		SpringSemesterStart: springStart,
+		WeeksFromMonday:     rq.WeeksFromMonday,
+	}, user)
+	if err != nil {
+		h.logger.Error("Create academic year error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, academicYearToView(out)).Send(c)
+}
+
+// ListAcademicYear - GET /v1/academic-years
+func (h *Handler) ListAcademicYear(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	out, err := h.academicYear.ListAcademicYear(ctx, user)
+	if err != nil {
+		h.logger.Error("List academic year error", "error", err)
+		return err
+	}
+
+	result := make([]AcademicYear, len(out))
+	for i, y := range out {
+		result[i] = academicYearToView(&y)
+	}
+
+	return WrapResponse(http.StatusOK, result).Send(c)
+}
+
+type UpdateAcademicYearRequest struct {
+	FallSemesterStart   *string `json:"fall_semester_start"`
+	SpringSemesterStart *string `json:"spring_semester_start"`
+	WeeksFromMonday     *bool   `json:"weeks_from_monday"`
+}
+
+// UpdateAcademicYear - PUT /v1/academic-years/:id
+func (h *Handler) UpdateAcademicYear(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	academicYearID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	var rq UpdateAcademicYearRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	input := usecases.UpdateAcademicYearInput{
+		AcademicYearID:  academicYearID,
+		WeeksFromMonday: rq.WeeksFromMonday,
+	}
+
+	if rq.FallSemesterStart != nil {
+		d, err := time.ParseInLocation(time.DateOnly, *rq.FallSemesterStart, common.DefaultTimezone)
+		if err != nil {
+			return ErrInvalidInput
+		}
+
+		input.FallSemesterStart = &d
+	}
+
+	if rq.SpringSemesterStart != nil {
+		d, err := time.ParseInLocation(time.DateOnly, *rq.SpringSemesterStart, common.DefaultTimezone)
+		if err != nil {
+			return ErrInvalidInput
+		}
+
+		input.SpringSemesterStart = &d
+	}
+
+	out, err := h.academicYear.UpdateAcademicYear(ctx, input, user)
+	if err != nil {
+		h.logger.Error("Update academic year error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, academicYearToView(out)).Send(c)
+}
+
+// DeleteAcademicYear - DELETE /v1/academic-years/:id
+func (h *Handler) DeleteAcademicYear(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	academicYearID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	err = h.academicYear.DeleteAcademicYear(ctx, academicYearID, user)
+	if err != nil {
+		h.logger.Error("Delete academic year error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, nil).Send(c)
+}
+
+func academicYearToView(model *academicyears.AcademicYear) AcademicYear {
+	return AcademicYear{
+		ID:                  model.ID,
+		FacultyID:           model.FacultyID,
+		Year:                model.Year,
+		FallSemesterStart:   model.FallSemesterStart.In(common.DefaultTimezone).Format(time.DateOnly),
+		SpringSemesterStart: model.SpringSemesterStart.In(common.DefaultTimezone).Format(time.DateOnly),
+		WeeksFromMonday:     model.WeeksFromMonday,
+	}
+}
@@ -34,6 +34,7 @@ type Cabinet struct {
 	Building                           string            `json:"building"`
 	Auditorium                         string            `json:"auditorium"`
 	SuitableForPeoplesWithSpecialNeeds bool              `json:"suitable_for_peoples_with_special_needs"`
+	Capacity                           int               `json:"capacity"`
 	Appointment                        *string           `json:"appointment"`
 	Equipment                          *CabinetEquipment `json:"equipment"`
 }
@@ -44,6 +45,7 @@ type CreateCabinetRequest struct {
 	Building                           string            `json:"building"`
 	Auditorium                         string            `json:"auditorium"`
 	SuitableForPeoplesWithSpecialNeeds bool              `json:"suitable_for_peoples_with_special_needs"`
+	Capacity                           int               `json:"capacity"`
 	Appointment                        *string           `json:"appointment"`
 	Equipment                          *CabinetEquipment `json:"equipment"`
 }
@@ -78,6 +80,7 @@ func (h *Handler) CreateCabinet(c echo.Context) error {
 		Auditorium:                         rq.Auditorium,
 		SuitableForPeoplesWithSpecialNeeds: rq.SuitableForPeoplesWithSpecialNeeds,
 		Building:                           rq.Building,
+		Capacity:                           rq.Capacity,
 		Appointment:                        rq.Appointment,
 		Equipment:                          equipment,
 	}, user)
@@ -142,6 +145,7 @@ type UpdateCabinetRequest struct {
 	Building                           *string           `json:"building"`
 	Auditorium                         *string           `json:"auditorium"`
 	SuitableForPeoplesWithSpecialNeeds *bool             `json:"suitable_for_peoples_with_special_needs"`
+	Capacity                           *int              `json:"capacity"`
 	Appointment                        *string           `json:"appointment"`
 	Equipment                          *CabinetEquipment `json:"equipment"`
 }
@@ -182,6 +186,7 @@ func (h *Handler) UpdateCabinet(c echo.Context) error {
 		Building:                           rq.Building,
 		Auditorium:                         rq.Auditorium,
 		SuitableForPeoplesWithSpecialNeeds: rq.SuitableForPeoplesWithSpecialNeeds,
+		Capacity:                           rq.Capacity,
 		Appointment:                        rq.Appointment,
 		Equipment:                          equipment,
 	}, user)
@@ -234,6 +239,7 @@ func cabinetToView(model *cabinets.Cabinet, facultyName string) Cabinet {
 		Auditorium:                         model.Auditorium,
 		SuitableForPeoplesWithSpecialNeeds: model.SuitableForPeoplesWithSpecialNeeds,
 		Building:                           model.Building,
+		Capacity:                           model.Capacity,
 		Appointment:                        model.Appointment,
 		Equipment:                          equipment,
 	}
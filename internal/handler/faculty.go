@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"schedule-generator/internal/application/usecases"
 	"schedule-generator/internal/domain/users"
@@ -13,11 +14,13 @@ import (
 
 type FacultyUsecase interface {
 	ListFaculty(ctx context.Context, user *users.User) (usecases.ListFacultyOutput, error)
+	UpdateFaculty(ctx context.Context, input usecases.UpdateFacultyInput, user *users.User) (*usecases.UpdateFacultyOutput, error)
 }
 
 type Faculty struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	WorkingDays []time.Weekday `json:"working_days"`
 }
 
 // ListFaculty - GET /v1/faculties
@@ -38,10 +41,51 @@ func (h *Handler) ListFaculty(c echo.Context) error {
 
 	for i, faculty := range out {
 		result[i] = Faculty{
-			ID:   faculty.ID,
-			Name: faculty.Name,
+			ID:          faculty.ID,
+			Name:        faculty.Name,
+			WorkingDays: faculty.WorkingDays,
 		}
 	}
 
 	return WrapResponse(http.StatusOK, result).Send(c)
 }
+
+type UpdateFacultyRequest struct {
+	WorkingDays []time.Weekday `json:"working_days"`
+}
+
+// UpdateFaculty - PUT /v1/faculties/:id
+func (h *Handler) UpdateFaculty(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	facultyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	var rq UpdateFacultyRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	out, err := h.faculty.UpdateFaculty(ctx, usecases.UpdateFacultyInput{
+		FacultyID:   facultyID,
+		WorkingDays: rq.WorkingDays,
+	}, user)
+	if err != nil {
+		h.logger.Error("Update faculty error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, Faculty{
+		ID:          out.ID,
+		Name:        out.Name,
+		WorkingDays: out.WorkingDays,
+	}).Send(c)
+}
@@ -19,6 +19,9 @@ type Handler struct {
 	teacher      TeacherUsecase
 	cabinet      CabinetUsecase
 	user         UserUsecase
+	timetable    TimetableUsecase
+	academicYear AcademicYearUsecase
+	usage        UsageUsecase
 	logger       *slog.Logger
 }
 
@@ -32,6 +35,9 @@ func NewHandler(
 	teacher TeacherUsecase,
 	cabinet CabinetUsecase,
 	user UserUsecase,
+	timetable TimetableUsecase,
+	academicYear AcademicYearUsecase,
+	usage UsageUsecase,
 	logger *slog.Logger,
 ) *Handler {
 	return &Handler{
@@ -44,6 +50,9 @@ func NewHandler(
 		schedule:     schedule,
 		cabinet:      cabinet,
 		user:         user,
+		timetable:    timetable,
+		academicYear: academicYear,
+		usage:        usage,
 		logger:       logger,
 	}
 }
@@ -61,7 +70,9 @@ func (h *Handler) InitRouter() *echo.Echo {
 			echo.HeaderContentType,
 			echo.HeaderAccept,
 			echo.HeaderAuthorization,
+			"If-Match",
 		}, // Разрешённые заголовки
+		ExposeHeaders: []string{"ETag"},
 	}))
 
 	auth := router.Group("/auth")
@@ -82,6 +93,17 @@ func (h *Handler) InitRouter() *echo.Echo {
 	faculties := api.Group("/faculties")
 	{
 		faculties.GET("", h.ListFaculty)
+		faculties.PUT("/:id", h.UpdateFaculty)
+		faculties.GET("/:id/schedules/export", h.ExportFacultySchedules)
+		faculties.GET("/:id/schedules/archive", h.ExportFacultyArchive)
+	}
+
+	academicYears := api.Group("/academic-years")
+	{
+		academicYears.POST("", h.CreateAcademicYear)
+		academicYears.GET("", h.ListAcademicYear)
+		academicYears.PUT("/:id", h.UpdateAcademicYear)
+		academicYears.DELETE("/:id", h.DeleteAcademicYear)
 	}
 
 	departments := api.Group("/departments")
@@ -117,6 +139,9 @@ func (h *Handler) InitRouter() *echo.Echo {
 		groups.GET("/:id", h.GetEduGroup)
 		groups.PUT("/:id", h.UpdateEduGroup)
 		groups.DELETE("/:id", h.DeleteEduGroup)
+
+		groups.GET("/:id/free-slots", h.FindFreeSlots)
+		groups.GET("/:id/usages", h.ListEduGroupUsage)
 	}
 
 	teachers := api.Group("/teachers")
@@ -126,28 +151,43 @@ func (h *Handler) InitRouter() *echo.Echo {
 		teachers.GET("/:id", h.GetTeacher)
 		teachers.PUT("/:id", h.UpdateTeacher)
 		teachers.DELETE("/:id", h.DeleteTeacher)
+		teachers.GET("/:id/schedule", h.GetTeacherTimetable)
+		teachers.GET("/:id/schedule/export", h.ExportTeacherTimetable)
+		teachers.GET("/:id/usages", h.ListTeacherUsage)
+		teachers.POST("/:id/reassign", h.ReassignTeacher)
 	}
 
 	schedules := api.Group("/schedules")
 	{
 		schedules.POST("", h.CreateSchedule)
 		schedules.GET("", h.ListSchedule)
+		schedules.POST("/import", h.ImportSchedule)
+		schedules.POST("/import/csv", h.ImportCycledCsv)
 		schedules.GET("/:id", h.GetSchedule)
 		schedules.PATCH("/:id", h.UpdateSchedule)
 		schedules.DELETE("/:id", h.DeleteSchedule)
 		schedules.GET("/:id/export", h.ExportSchedule)
+		schedules.GET("/:id/days/:date", h.GetScheduleDay)
+		schedules.GET("/:id/calendar", h.GetScheduleCalendar)
 		schedules.POST("/:id/items", h.AddScheduleItem)
 		schedules.PUT("/:id/items", h.UpdateScheduleItem)
 		schedules.DELETE("/:id/items", h.RemoveScheduleItem)
+		schedules.PUT("/:id/items/:item_id", h.UpdateScheduleItemByID)
+		schedules.DELETE("/:id/items/:item_id", h.RemoveScheduleItemByID)
 	}
 
 	cabinets := api.Group("/cabinets")
 	{
 		cabinets.POST("", h.CreateCabinet)
 		cabinets.GET("", h.ListCabinet)
+		cabinets.GET("/free", h.SearchFreeCabinets)
 		cabinets.GET("/:id", h.GetCabinet)
 		cabinets.PUT("/:id", h.UpdateCabinet)
 		cabinets.DELETE("/:id", h.DeleteCabinet)
+		cabinets.GET("/:id/schedule", h.GetCabinetTimetable)
+		cabinets.GET("/:id/schedule/export", h.ExportCabinetTimetable)
+		cabinets.GET("/:id/usages", h.ListCabinetUsage)
+		cabinets.POST("/:id/reassign", h.ReassignCabinet)
 	}
 
 	return router
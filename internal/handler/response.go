@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"bytes"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -31,30 +30,62 @@ func (rw *ResponseWrapper) Send(c echo.Context) error {
 	return c.JSON(rw.Status, rw)
 }
 
-// Send returns to API client wrapped
-func (rw *ResponseWrapper) SendAsFile(c echo.Context, filename, format string) error {
-	if rw == nil {
-		return nil
-	}
+// fileContentTypes content types of exported files by format
+var fileContentTypes = map[string]string{
+	"csv":  "text/csv",
+	"ics":  "text/calendar; charset=utf-8",
+	"pdf":  "application/pdf",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"json": "application/json; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+	"zip":  "application/zip",
+}
 
-	buffer, ok := rw.Response.(*bytes.Buffer)
+func setFileHeaders(c echo.Context, filename, format string) error {
+	contentType, ok := fileContentTypes[format]
 	if !ok {
-		return c.NoContent(http.StatusNoContent)
+		return ErrUnsupportedFormat
 	}
 
-	switch format {
-	case "csv":
-		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+filename)
-		c.Response().Header().Set(echo.HeaderContentType, "text/csv")
-	default:
-		return ErrUnsupportedFormat
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+filename)
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+
+	return nil
+}
+
+// FileStream writes exported file directly into response. Headers are set on creation, status and headers are sent
+// with the first written bytes, so errors occurred before are still returned to client as usual API errors
+type FileStream struct {
+	c echo.Context
+}
+
+func NewFileStream(c echo.Context, filename, format string) (*FileStream, error) {
+	if err := setFileHeaders(c, filename, format); err != nil {
+		return nil, err
+	}
+
+	return &FileStream{c: c}, nil
+}
+
+func (s *FileStream) Write(p []byte) (int, error) {
+	resp := s.c.Response()
+	if !resp.Committed {
+		resp.WriteHeader(http.StatusOK)
 	}
 
-	c.Response().Status = http.StatusOK
-	_, err := buffer.WriteTo(c.Response())
-	if err != nil {
+	return resp.Write(p)
+}
+
+// Fail finishes stream with export error. Not started response is reset to be replaced with error response,
+// partially sent file is aborted, so client gets broken connection instead of truncated file
+func (s *FileStream) Fail(err error) error {
+	resp := s.c.Response()
+	if !resp.Committed {
+		resp.Header().Del(echo.HeaderContentDisposition)
+		resp.Header().Del(echo.HeaderContentType)
+
 		return err
 	}
 
-	return nil
+	panic(http.ErrAbortHandler)
 }
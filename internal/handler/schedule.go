@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"schedule-generator/internal/application/usecases"
@@ -21,29 +21,50 @@ type ScheduleUsecase interface {
 	CreateSchedule(ctx context.Context, input usecases.CreateScheduleInput, user *users.User) (*usecases.CreateScheduleOutput, error)
 	ListSchedule(ctx context.Context, user *users.User) (usecases.ListScheduleOutput, error)
 	GetSchedule(ctx context.Context, scheduleID uuid.UUID, user *users.User) (*usecases.GetScheduleOutput, error)
-	AddItemsToSchedule(ctx context.Context, scheduleID uuid.UUID, input []usecases.AddItemToScheduleInput, user *users.User) error
-	UpdateItemInSchedule(ctx context.Context, scheduleID uuid.UUID, input usecases.AddItemToScheduleInput, user *users.User) error
-	RemoveItemsFromSchedule(ctx context.Context, scheduleID uuid.UUID, input []usecases.RemoveItemFromScheduleInput, user *users.User) error
-	ExportSchedule(ctx context.Context, scheduleID uuid.UUID, format string, dst io.Writer, user *users.User) error
-	ExportCycledScheduleAsCalendar(ctx context.Context, scheduleID uuid.UUID, format string, dst io.Writer, user *users.User) error
+	AddItemsToSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, input []usecases.AddItemToScheduleInput, user *users.User) (int, error)
+	UpdateItemInSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, input usecases.AddItemToScheduleInput, user *users.User) (int, error)
+	RemoveItemsFromSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, input []usecases.RemoveItemFromScheduleInput, user *users.User) (int, error)
+	ExportSchedule(ctx context.Context, scheduleID uuid.UUID, format, csvProfile string, dst io.Writer, user *users.User) error
+	ExportCycledScheduleAsCalendar(ctx context.Context, scheduleID uuid.UUID, format, csvProfile string, dst io.Writer, user *users.User) error
+	ExportFacultySchedules(ctx context.Context, facultyID uuid.UUID, semester int, format string, dst io.Writer, user *users.User) error
+	ExportFacultyArchive(ctx context.Context, facultyID uuid.UUID, filter usecases.FacultySchedulesFilter, format, csvProfile string, dst io.Writer, user *users.User) error
+	ImportSchedule(ctx context.Context, input usecases.ImportScheduleInput, user *users.User) (*usecases.CreateScheduleOutput, error)
+	ImportCycledCsv(ctx context.Context, input usecases.ImportCycledCsvInput, user *users.User) (*usecases.ImportCycledCsvOutput, error)
 	UpdateSchedule(ctx context.Context, input usecases.UpdateScheduleInput, user *users.User) (*usecases.UpdateScheduleOutput, error)
-	DeleteSchedule(ctx context.Context, scheduleID uuid.UUID, user *users.User) error
+	DeleteSchedule(ctx context.Context, scheduleID uuid.UUID, version *int, user *users.User) error
+	GetListScheduleItemForSpecifiedDate(ctx context.Context, scheduleID uuid.UUID, date time.Time, user *users.User) (*usecases.GetListScheduleItemForSpecifiedDateOutput, error)
+	GetScheduleCalendar(ctx context.Context, scheduleID uuid.UUID, from, to time.Time, user *users.User) (*usecases.GetScheduleCalendarOutput, error)
+}
+
+type ItemTeacher struct {
+	TeacherID   uuid.UUID `json:"teacher_id"`
+	TeacherName string    `json:"teacher_name"`
+	Role        int8      `json:"role"`
 }
 
 type ScheduleItem struct {
-	Discipline        string     `json:"discipline"`
-	TeacherID         uuid.UUID  `json:"teacher_id"`
-	TeacherName       string     `json:"teacher_name"`
-	Weekday           string     `json:"weekday"`
-	StudentsCount     int16      `json:"students_count"`
-	Date              *time.Time `json:"date"`
-	LessonNumber      int8       `json:"lesson_number"`
-	Subgroup          int8       `json:"subgroup"`
-	Weektype          *int8      `json:"weektype"`
-	Weeknum           *int       `json:"weeknum"`
-	LessonType        int8       `json:"lesson_type"`
-	CabinetAuditorium string     `json:"cabinet_auditorium"`
-	CabinetBuilding   string     `json:"cabinet_building"`
+	ID                uuid.UUID     `json:"id"`
+	Discipline        string        `json:"discipline"`
+	TeacherID         uuid.UUID     `json:"teacher_id"`
+	TeacherName       string        `json:"teacher_name"`
+	Teachers          []ItemTeacher `json:"teachers"`
+	Weekday           string        `json:"weekday"`
+	StudentsCount     int16         `json:"students_count"`
+	Date              *time.Time    `json:"date"`
+	LessonNumber      int8          `json:"lesson_number"`
+	Duration          int8          `json:"duration"`
+	Subgroup          int8          `json:"subgroup"`
+	Weektype          *int8         `json:"weektype"`
+	CycleWeeks        []int         `json:"cycle_weeks,omitempty"`
+	Weeks             []int         `json:"weeks,omitempty"`
+	Weeknum           *int          `json:"weeknum"`
+	LessonType        int8          `json:"lesson_type"`
+	LocationKind      int8          `json:"location_kind"`
+	MeetingURL        string        `json:"meeting_url,omitempty"`
+	Address           string        `json:"address,omitempty"`
+	CabinetID         *uuid.UUID    `json:"cabinet_id"`
+	CabinetAuditorium string        `json:"cabinet_auditorium"`
+	CabinetBuilding   string        `json:"cabinet_building"`
 }
 
 type Schedule struct {
@@ -54,15 +75,24 @@ type Schedule struct {
 	Type           string         `json:"type"`
 	StartDate      *string        `json:"start_date"`
 	EndDate        *string        `json:"end_date"`
+	CycleLength    int            `json:"cycle_length,omitempty"`
+	WorkingDays    []time.Weekday `json:"working_days"`
+	Version        int            `json:"version"`
 	Items          []ScheduleItem `json:"items"`
 }
 
+type ScheduleVersion struct {
+	Version int `json:"version"`
+}
+
 type CreateScheduleRequest struct {
 	//TODO: add calendar
-	EduGroupID uuid.UUID `json:"edu_group_id"`
-	Semester   int       `json:"semester"`
-	StartDate  string    `json:"start_date"`
-	EndDate    string    `json:"end_date"`
+	EduGroupID  uuid.UUID      `json:"edu_group_id"`
+	Semester    int            `json:"semester"`
+	StartDate   string         `json:"start_date"`
+	EndDate     string         `json:"end_date"`
+	CycleLength *int           `json:"cycle_length"`
+	WorkingDays []time.Weekday `json:"working_days"`
 }
 
 type CreateScheduleResponse struct {
@@ -98,10 +128,12 @@ func (h *Handler) CreateSchedule(c echo.Context) error {
 	}
 
 	out, err := h.schedule.CreateSchedule(ctx, usecases.CreateScheduleInput{
-		EduGroupID: rq.EduGroupID,
-		Semester:   rq.Semester,
-		StartDate:  startDate,
-		EndDate:    endDate,
+		EduGroupID:  rq.EduGroupID,
+		Semester:    rq.Semester,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		CycleLength: rq.CycleLength,
+		WorkingDays: rq.WorkingDays,
 	}, user)
 	if err != nil {
 		h.logger.Error("Create schedule error", "error", err)
@@ -155,19 +187,35 @@ func (h *Handler) GetSchedule(c echo.Context) error {
 		return err
 	}
 
+	setScheduleVersion(c, out.Version)
+
 	return WrapResponse(http.StatusOK, scheduleDTOtoView(out.ScheduleDTO, out.EduGroupNumber)).Send(c)
 }
 
+type ItemTeacherRequest struct {
+	TeacherID uuid.UUID `json:"teacher_id"`
+	Role      int8      `json:"role"`
+}
+
 type AddScheduleItemRequest struct {
-	Discipline    string        `json:"discipline"`
-	TeacherID     uuid.UUID     `json:"teacher_id"`
-	Weekday       *time.Weekday `json:"weekday"`
-	StudentsCount int16         `json:"students_count"`
-	LessonNumber  int8          `json:"lesson_number"`
-	Subgroup      int8          `json:"subgroup"`
-	Weektype      *int8         `json:"weektype"`
-	LessonType    int8          `json:"lesson_type"`
-	CabinetID     uuid.UUID     `json:"cabinet_id"`
+	Discipline string    `json:"discipline"`
+	TeacherID  uuid.UUID `json:"teacher_id"`
+	// Teachers full list of lesson teachers, TeacherID is used as the only main teacher when empty
+	Teachers      []ItemTeacherRequest `json:"teachers"`
+	Weekday       *time.Weekday        `json:"weekday"`
+	StudentsCount int16                `json:"students_count"`
+	LessonNumber  int8                 `json:"lesson_number"`
+	Duration      int8                 `json:"duration"`
+	Subgroup      int8                 `json:"subgroup"`
+	Weektype      *int8                `json:"weektype"`
+	CycleWeeks    []int                `json:"cycle_weeks"`
+	Weeks         []int                `json:"weeks"`
+	LessonType    int8                 `json:"lesson_type"`
+	// LocationKind 0 - cabinet, 1 - online, 2 - external. CabinetID is required for cabinet only
+	LocationKind int8      `json:"location_kind"`
+	MeetingURL   string    `json:"meeting_url"`
+	Address      string    `json:"address"`
+	CabinetID    uuid.UUID `json:"cabinet_id"`
 }
 
 // AddScheduleItem - POST /v1/schedules/:id/items
@@ -190,29 +238,26 @@ func (h *Handler) AddScheduleItem(c echo.Context) error {
 		return ErrInvalidInput
 	}
 
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
 	input := make([]usecases.AddItemToScheduleInput, len(rq))
 
 	for i, item := range rq {
-		input[i] = usecases.AddItemToScheduleInput{
-			Discipline:    item.Discipline,
-			TeacherID:     item.TeacherID,
-			StudentsCount: item.StudentsCount,
-			Weekday:       item.Weekday,
-			LessonNumber:  item.LessonNumber,
-			Subgroup:      item.Subgroup,
-			Weektype:      item.Weektype,
-			LessonType:    item.LessonType,
-			CabinetID:     item.CabinetID,
-		}
+		input[i] = addItemRequestToInput(&item)
 	}
 
-	err = h.schedule.AddItemsToSchedule(ctx, scheduleID, input, user)
+	newVersion, err := h.schedule.AddItemsToSchedule(ctx, scheduleID, version, input, user)
 	if err != nil {
 		h.logger.Error("Add items to schedule error", "error", err)
 		return err
 	}
 
-	return WrapResponse(http.StatusOK, nil).Send(c)
+	setScheduleVersion(c, newVersion)
+
+	return WrapResponse(http.StatusOK, ScheduleVersion{Version: newVersion}).Send(c)
 }
 
 // UpdateScheduleItem - PUT /v1/schedules/:id/items
@@ -235,32 +280,112 @@ func (h *Handler) UpdateScheduleItem(c echo.Context) error {
 		return ErrInvalidInput
 	}
 
-	input := usecases.AddItemToScheduleInput{
-		Discipline:    rq.Discipline,
-		TeacherID:     rq.TeacherID,
-		StudentsCount: rq.StudentsCount,
-		Weekday:       rq.Weekday,
-		LessonNumber:  rq.LessonNumber,
-		Subgroup:      rq.Subgroup,
-		Weektype:      rq.Weektype,
-		LessonType:    rq.LessonType,
-		CabinetID:     rq.CabinetID,
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
 	}
 
-	err = h.schedule.UpdateItemInSchedule(ctx, scheduleID, input, user)
+	newVersion, err := h.schedule.UpdateItemInSchedule(ctx, scheduleID, version, addItemRequestToInput(rq), user)
 	if err != nil {
 		h.logger.Error("Add items to schedule error", "error", err)
 		return err
 	}
 
-	return WrapResponse(http.StatusOK, nil).Send(c)
+	setScheduleVersion(c, newVersion)
+
+	return WrapResponse(http.StatusOK, ScheduleVersion{Version: newVersion}).Send(c)
+}
+
+// UpdateScheduleItemByID - PUT /v1/schedules/:id/items/:item_id
+func (h *Handler) UpdateScheduleItemByID(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq AddScheduleItemRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	input := addItemRequestToInput(&rq)
+	input.ItemID = &itemID
+
+	newVersion, err := h.schedule.UpdateItemInSchedule(ctx, scheduleID, version, input, user)
+	if err != nil {
+		h.logger.Error("Update schedule item error", "error", err)
+		return err
+	}
+
+	setScheduleVersion(c, newVersion)
+
+	return WrapResponse(http.StatusOK, ScheduleVersion{Version: newVersion}).Send(c)
+}
+
+// RemoveScheduleItemByID - DELETE /v1/schedules/:id/items/:item_id
+func (h *Handler) RemoveScheduleItemByID(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	newVersion, err := h.schedule.RemoveItemsFromSchedule(ctx, scheduleID, version, []usecases.RemoveItemFromScheduleInput{
+		{ItemID: &itemID},
+	}, user)
+	if err != nil {
+		h.logger.Error("Remove schedule item error", "error", err)
+		return err
+	}
+
+	setScheduleVersion(c, newVersion)
+
+	return WrapResponse(http.StatusOK, ScheduleVersion{Version: newVersion}).Send(c)
 }
 
 type RemoveScheduleItemRequest struct {
+	// ItemID addresses removed item, other fields are ignored when set
+	ItemID       *uuid.UUID    `json:"item_id"`
 	Weekday      *time.Weekday `json:"weekday"`
 	LessonNumber int8          `json:"lesson_number"`
 	Subgroup     int8          `json:"subgroup"`
 	Weektype     *int8         `json:"weektype"`
+	CycleWeeks   []int         `json:"cycle_weeks"`
+	Weeks        []int         `json:"weeks"`
 }
 
 // RemoveScheduleItem - DELETE /v1/schedules/:id/items
@@ -283,30 +408,42 @@ func (h *Handler) RemoveScheduleItem(c echo.Context) error {
 		return ErrInvalidInput
 	}
 
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
 	input := make([]usecases.RemoveItemFromScheduleInput, len(rq))
 
 	for i, item := range rq {
 		input[i] = usecases.RemoveItemFromScheduleInput{
+			ItemID:       item.ItemID,
 			Weekday:      item.Weekday,
 			LessonNumber: item.LessonNumber,
 			Subgroup:     item.Subgroup,
 			Weektype:     item.Weektype,
+			CycleWeeks:   item.CycleWeeks,
+			Weeks:        item.Weeks,
 		}
 	}
 
-	err = h.schedule.RemoveItemsFromSchedule(ctx, scheduleID, input, user)
+	newVersion, err := h.schedule.RemoveItemsFromSchedule(ctx, scheduleID, version, input, user)
 	if err != nil {
 		h.logger.Error("Remove items from schedule error", "error", err)
 		return err
 	}
 
-	return WrapResponse(http.StatusOK, nil).Send(c)
+	setScheduleVersion(c, newVersion)
+
+	return WrapResponse(http.StatusOK, ScheduleVersion{Version: newVersion}).Send(c)
 }
 
 type ExportScheduleRequest struct {
 	ScheduleID uuid.UUID `param:"id"`
 	Format     string    `query:"format"`
 	AsCalendar bool      `query:"as_calendar"`
+	// CsvProfile name of csv layout, default layout when empty
+	CsvProfile string `query:"csv_profile"`
 }
 
 // ExportSchedule - GET /v1/schedules/:id/export
@@ -324,23 +461,242 @@ func (h *Handler) ExportSchedule(c echo.Context) error {
 		return ErrNotParsable
 	}
 
-	buffer := bytes.NewBuffer([]byte{})
+	fname := fmt.Sprintf("%s-%s.%s", rq.ScheduleID, time.Now().Format("20060102150405"), rq.Format)
+
+	stream, err := NewFileStream(c, fname, rq.Format)
+	if err != nil {
+		return err
+	}
 
 	var exportErr error
 	if rq.AsCalendar {
-		exportErr = h.schedule.ExportCycledScheduleAsCalendar(ctx, rq.ScheduleID, rq.Format, buffer, user)
+		exportErr = h.schedule.ExportCycledScheduleAsCalendar(ctx, rq.ScheduleID, rq.Format, rq.CsvProfile, stream, user)
 	} else {
-		exportErr = h.schedule.ExportSchedule(ctx, rq.ScheduleID, rq.Format, buffer, user)
+		exportErr = h.schedule.ExportSchedule(ctx, rq.ScheduleID, rq.Format, rq.CsvProfile, stream, user)
 	}
 
 	if exportErr != nil {
 		h.logger.Error("Export schedule error", "error", exportErr)
-		return exportErr
+		return stream.Fail(exportErr)
+	}
+
+	return nil
+}
+
+type ExportFacultySchedulesRequest struct {
+	FacultyID uuid.UUID `param:"id"`
+	Format    string    `query:"format"`
+	Semester  int       `query:"semester"`
+}
+
+// ExportFacultySchedules - GET /v1/faculties/:id/schedules/export
+func (h *Handler) ExportFacultySchedules(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq ExportFacultySchedulesRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	fname := fmt.Sprintf("%s-%s.%s", rq.FacultyID, time.Now().Format("20060102150405"), rq.Format)
+
+	stream, err := NewFileStream(c, fname, rq.Format)
+	if err != nil {
+		return err
+	}
+
+	if err := h.schedule.ExportFacultySchedules(ctx, rq.FacultyID, rq.Semester, rq.Format, stream, user); err != nil {
+		h.logger.Error("Export faculty schedules error", "error", err)
+		return stream.Fail(err)
+	}
+
+	return nil
+}
+
+// ImportSchedule - POST /v1/schedules/import?edu_group_id=
+// Request body is schedule document produced by json export
+func (h *Handler) ImportSchedule(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
 	}
 
-	fname := fmt.Sprintf("%s-%s.csv", rq.ScheduleID, time.Now().Format("20060102150405"))
+	groupID, err := uuid.Parse(c.QueryParam("edu_group_id"))
+	if err != nil {
+		h.logger.Error("Parse edu group id error", "error", err)
+		return ErrInvalidInput
+	}
 
-	return WrapResponse(http.StatusOK, buffer).SendAsFile(c, fname, rq.Format)
+	out, err := h.schedule.ImportSchedule(ctx, usecases.ImportScheduleInput{
+		EduGroupID: groupID,
+		Document:   c.Request().Body,
+	}, user)
+	if err != nil {
+		h.logger.Error("Import schedule error", "error", err)
+		return err
+	}
+
+	setScheduleVersion(c, out.Version)
+
+	return WrapResponse(http.StatusOK, scheduleDTOtoView(out.ScheduleDTO, out.EduGroupNumber)).Send(c)
+}
+
+type ImportedSchedule struct {
+	EduGroupID     uuid.UUID  `json:"edu_group_id"`
+	EduGroupNumber string     `json:"edu_group_number"`
+	ScheduleID     *uuid.UUID `json:"schedule_id"`
+	ItemsCount     int        `json:"items_count"`
+}
+
+type ImportLineError struct {
+	Lines []int  `json:"lines"`
+	Error string `json:"error"`
+}
+
+type ImportCycledCsvResponse struct {
+	DryRun             bool               `json:"dry_run"`
+	Schedules          []ImportedSchedule `json:"schedules"`
+	UnresolvedGroups   []string           `json:"unresolved_groups"`
+	UnresolvedTeachers []string           `json:"unresolved_teachers"`
+	UnresolvedCabinets []string           `json:"unresolved_cabinets"`
+	Errors             []ImportLineError  `json:"errors"`
+	// PlaceholderMeetingURLLines lines of online lessons which meeting url must be set after import
+	PlaceholderMeetingURLLines []int `json:"placeholder_meeting_url_lines"`
+}
+
+// ImportCycledCsv - POST /v1/schedules/import/csv?semester=&start_date=&end_date=&dry_run=
+// Request body is file of cycled csv export format
+func (h *Handler) ImportCycledCsv(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	semester, err := strconv.Atoi(c.QueryParam("semester"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	startDate, err := time.ParseInLocation(time.DateOnly, c.QueryParam("start_date"), common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	endDate, err := time.ParseInLocation(time.DateOnly, c.QueryParam("end_date"), common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	var dryRun bool
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			return ErrInvalidInput
+		}
+	}
+
+	out, err := h.schedule.ImportCycledCsv(ctx, usecases.ImportCycledCsvInput{
+		Document:  c.Request().Body,
+		Semester:  semester,
+		StartDate: startDate,
+		EndDate:   endDate,
+		DryRun:    dryRun,
+	}, user)
+	if err != nil {
+		h.logger.Error("Import cycled csv error", "error", err)
+		return err
+	}
+
+	response := ImportCycledCsvResponse{
+		DryRun:                     out.DryRun,
+		Schedules:                  make([]ImportedSchedule, len(out.Schedules)),
+		UnresolvedGroups:           out.UnresolvedGroups,
+		UnresolvedTeachers:         out.UnresolvedTeachers,
+		UnresolvedCabinets:         out.UnresolvedCabinets,
+		Errors:                     make([]ImportLineError, len(out.Errors)),
+		PlaceholderMeetingURLLines: out.PlaceholderMeetingURLLines,
+	}
+
+	for i, s := range out.Schedules {
+		response.Schedules[i] = ImportedSchedule{
+			EduGroupID:     s.EduGroupID,
+			EduGroupNumber: s.EduGroupNumber,
+			ItemsCount:     s.ItemsCount,
+		}
+
+		if s.ScheduleID != uuid.Nil {
+			response.Schedules[i].ScheduleID = &s.ScheduleID
+		}
+	}
+
+	for i, e := range out.Errors {
+		response.Errors[i] = ImportLineError{Lines: e.Lines, Error: e.Error}
+	}
+
+	return WrapResponse(http.StatusOK, response).Send(c)
+}
+
+type ExportFacultyArchiveRequest struct {
+	FacultyID uuid.UUID `param:"id"`
+	Format    string    `query:"format"`
+	Semester  int       `query:"semester"`
+	// EduGroupIDs comma separated ids of groups
+	EduGroupIDs string `query:"edu_group_ids"`
+	// CsvProfile name of csv layout, default layout when empty
+	CsvProfile string `query:"csv_profile"`
+}
+
+// ExportFacultyArchive - GET /v1/faculties/:id/schedules/archive
+func (h *Handler) ExportFacultyArchive(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq ExportFacultyArchiveRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	filter := usecases.FacultySchedulesFilter{Semester: rq.Semester}
+	for raw := range strings.SplitSeq(rq.EduGroupIDs, ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return ErrInvalidInput
+		}
+
+		filter.EduGroupIDs = append(filter.EduGroupIDs, id)
+	}
+
+	fname := fmt.Sprintf("%s-%s.zip", rq.FacultyID, time.Now().Format("20060102150405"))
+
+	stream, err := NewFileStream(c, fname, "zip")
+	if err != nil {
+		return err
+	}
+
+	if err := h.schedule.ExportFacultyArchive(ctx, rq.FacultyID, filter, rq.Format, rq.CsvProfile, stream, user); err != nil {
+		h.logger.Error("Export faculty archive error", "error", err)
+		return stream.Fail(err)
+	}
+
+	return nil
 }
 
 // DeleteSchedule - DELETE /v1/schedules/:id
@@ -357,7 +713,12 @@ func (h *Handler) DeleteSchedule(c echo.Context) error {
 		return ErrInvalidInput
 	}
 
-	if err := h.schedule.DeleteSchedule(ctx, scheduleID, user); err != nil {
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	if err := h.schedule.DeleteSchedule(ctx, scheduleID, version, user); err != nil {
 		return err
 	}
 
@@ -365,9 +726,11 @@ func (h *Handler) DeleteSchedule(c echo.Context) error {
 }
 
 type UpdateScheduleRequest struct {
-	Semester  *int    `json:"semester"`
-	StartDate *string `json:"start_date"`
-	EndDate   *string `json:"end_date"`
+	Semester    *int           `json:"semester"`
+	StartDate   *string        `json:"start_date"`
+	EndDate     *string        `json:"end_date"`
+	CycleLength *int           `json:"cycle_length"`
+	WorkingDays []time.Weekday `json:"working_days"`
 }
 
 // UpdateSchedule - PATCH /v1/schedules/:id
@@ -408,49 +771,167 @@ func (h *Handler) UpdateSchedule(c echo.Context) error {
 
 	}
 
-	log.Println("AAAAAAAAAAAAAAAAAAA", startDate)
+	version, err := parseScheduleVersion(c)
+	if err != nil {
+		return ErrInvalidInput
+	}
 
 	out, err := h.schedule.UpdateSchedule(ctx, usecases.UpdateScheduleInput{
-		ID:        scheduleID,
-		Semester:  rq.Semester,
-		StartDate: startDate,
-		EndDate:   endDate,
+		ID:          scheduleID,
+		Semester:    rq.Semester,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		CycleLength: rq.CycleLength,
+		WorkingDays: rq.WorkingDays,
+		Version:     version,
 	}, user)
 	if err != nil {
 		h.logger.Error("Get list schedule error", "error", err)
 		return err
 	}
 
+	setScheduleVersion(c, out.Version)
+
 	return WrapResponse(http.StatusOK, scheduleDTOtoView(out.ScheduleDTO, out.EduGroupNumber)).Send(c)
 }
 
+type ScheduleDay struct {
+	Date     string         `json:"date"`
+	Weekday  string         `json:"weekday"`
+	Weeknum  *int           `json:"weeknum"`
+	Weektype *int8          `json:"weektype"`
+	Items    []ScheduleItem `json:"items"`
+}
+
+type ScheduleDayResponse struct {
+	ScheduleDay
+	ScheduleID     uuid.UUID `json:"schedule_id"`
+	EduGroupNumber string    `json:"edu_group_number"`
+}
+
+// GetScheduleDay - GET /v1/schedules/:id/days/:date
+func (h *Handler) GetScheduleDay(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, c.Param("date"), common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.schedule.GetListScheduleItemForSpecifiedDate(ctx, scheduleID, date, user)
+	if err != nil {
+		h.logger.Error("Get schedule day error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, ScheduleDayResponse{
+		ScheduleDay:    scheduleDayDTOtoView(out.ScheduleDayDTO),
+		ScheduleID:     out.ScheduleID,
+		EduGroupNumber: out.EduGroupNumber,
+	}).Send(c)
+}
+
+type GetScheduleCalendarRequest struct {
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+type ScheduleCalendar struct {
+	ScheduleID     uuid.UUID     `json:"schedule_id"`
+	EduGroupNumber string        `json:"edu_group_number"`
+	From           string        `json:"from"`
+	To             string        `json:"to"`
+	Days           []ScheduleDay `json:"days"`
+}
+
+// GetScheduleCalendar - GET /v1/schedules/:id/calendar
+func (h *Handler) GetScheduleCalendar(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	var rq GetScheduleCalendarRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	from, err := time.ParseInLocation(time.DateOnly, rq.From, common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	to, err := time.ParseInLocation(time.DateOnly, rq.To, common.DefaultTimezone)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.schedule.GetScheduleCalendar(ctx, scheduleID, from, to, user)
+	if err != nil {
+		h.logger.Error("Get schedule calendar error", "error", err)
+		return err
+	}
+
+	days := make([]ScheduleDay, len(out.Days))
+	for i, day := range out.Days {
+		days[i] = scheduleDayDTOtoView(day)
+	}
+
+	return WrapResponse(http.StatusOK, ScheduleCalendar{
+		ScheduleID:     out.ScheduleID,
+		EduGroupNumber: out.EduGroupNumber,
+		From:           out.From.Format(time.DateOnly),
+		To:             out.To.Format(time.DateOnly),
+		Days:           days,
+	}).Send(c)
+}
+
+func scheduleDayDTOtoView(dto usecases.ScheduleDayDTO) ScheduleDay {
+	items := make([]ScheduleItem, len(dto.Items))
+	for i, item := range dto.Items {
+		items[i] = scheduleItemDTOtoView(item)
+	}
+
+	var wt *int8
+	if dto.Weektype != nil {
+		s := int8(*dto.Weektype)
+		wt = &s
+	}
+
+	return ScheduleDay{
+		Date:     dto.Date.Format(time.DateOnly),
+		Weekday:  dto.Weekday.String(),
+		Weeknum:  dto.Weeknum,
+		Weektype: wt,
+		Items:    items,
+	}
+}
+
 func scheduleDTOtoView(dto usecases.ScheduleDTO, eduGroupNumber string) Schedule {
 	var items []ScheduleItem
 
 	if len(dto.Items) > 0 {
 		items = make([]ScheduleItem, 0, len(dto.Items))
 		for _, item := range dto.Items {
-			var wt *int8
-			if item.Weektype != nil {
-				s := int8(*item.Weektype)
-				wt = &s
-			}
-
-			items = append(items, ScheduleItem{
-				Discipline:        item.Discipline,
-				TeacherID:         item.TeacherID,
-				TeacherName:       item.TeacherName,
-				Weekday:           item.Weekday.String(),
-				StudentsCount:     item.StudentsCount,
-				Date:              item.Date,
-				LessonNumber:      item.LessonNumber,
-				Subgroup:          item.Subgroup,
-				Weektype:          wt,
-				Weeknum:           item.Weeknum,
-				LessonType:        int8(item.LessonType),
-				CabinetAuditorium: item.Cabinet.Auditorium,
-				CabinetBuilding:   item.Cabinet.Building,
-			})
+			items = append(items, scheduleItemDTOtoView(item))
 		}
 	}
 
@@ -473,6 +954,115 @@ func scheduleDTOtoView(dto usecases.ScheduleDTO, eduGroupNumber string) Schedule
 		Type:           dto.Type.String(),
 		StartDate:      startDate,
 		EndDate:        endDate,
+		CycleLength:    dto.CycleLength,
+		WorkingDays:    dto.WorkingDays,
+		Version:        dto.Version,
 		Items:          items,
 	}
 }
+
+// parseScheduleVersion returns version of schedule which client has modified. Version is read from If-Match header
+// or version query parameter, nil is returned when client does not check version
+func parseScheduleVersion(c echo.Context) (*int, error) {
+	raw := c.Request().Header.Get("If-Match")
+	if raw == "" || raw == "*" {
+		raw = c.QueryParam("version")
+	}
+
+	raw = strings.Trim(strings.TrimPrefix(strings.TrimSpace(raw), "W/"), `"`)
+	if raw == "" {
+		return nil, nil
+	}
+
+	version, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// setScheduleVersion sets ETag header so that client can send it back in If-Match
+func setScheduleVersion(c echo.Context, version int) {
+	c.Response().Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+func addItemRequestToInput(rq *AddScheduleItemRequest) usecases.AddItemToScheduleInput {
+	return usecases.AddItemToScheduleInput{
+		Discipline:    rq.Discipline,
+		TeacherID:     rq.TeacherID,
+		Teachers:      itemTeachersFromRequest(rq.Teachers),
+		StudentsCount: rq.StudentsCount,
+		Weekday:       rq.Weekday,
+		LessonNumber:  rq.LessonNumber,
+		Duration:      rq.Duration,
+		Subgroup:      rq.Subgroup,
+		Weektype:      rq.Weektype,
+		CycleWeeks:    rq.CycleWeeks,
+		Weeks:         rq.Weeks,
+		LessonType:    rq.LessonType,
+		LocationKind:  rq.LocationKind,
+		MeetingURL:    rq.MeetingURL,
+		Address:       rq.Address,
+		CabinetID:     rq.CabinetID,
+	}
+}
+
+func itemTeachersFromRequest(rq []ItemTeacherRequest) []usecases.ItemTeacherInput {
+	result := make([]usecases.ItemTeacherInput, len(rq))
+	for i, t := range rq {
+		result[i] = usecases.ItemTeacherInput{
+			TeacherID: t.TeacherID,
+			Role:      t.Role,
+		}
+	}
+
+	return result
+}
+
+func scheduleItemDTOtoView(item usecases.ScheduleItemDTO) ScheduleItem {
+	var cabinetID *uuid.UUID
+	if item.InCabinet() && item.Cabinet.ID != uuid.Nil {
+		cabinetID = &item.Cabinet.ID
+	}
+
+	teachers := make([]ItemTeacher, len(item.TeacherNames))
+	for i, t := range item.TeacherNames {
+		teachers[i] = ItemTeacher{
+			TeacherID:   t.TeacherID,
+			TeacherName: t.Name,
+			Role:        int8(t.Role),
+		}
+	}
+
+	var wt *int8
+	if item.Weektype != nil {
+		s := int8(*item.Weektype)
+		wt = &s
+	}
+
+	return ScheduleItem{
+		ID:                item.ID,
+		Discipline:        item.Discipline,
+		TeacherID:         item.MainTeacherID(),
+		TeacherName:       item.TeacherName,
+		Teachers:          teachers,
+		Weekday:           item.Weekday.String(),
+		StudentsCount:     item.StudentsCount,
+		Date:              item.Date,
+		LessonNumber:      item.LessonNumber,
+		Duration:          item.Span(),
+		Subgroup:          item.Subgroup,
+		Weektype:          wt,
+		CycleWeeks:        item.CycleWeeks,
+		Weeks:             item.Weeks,
+		Weeknum:           item.Weeknum,
+		LessonType:        int8(item.LessonType),
+		LocationKind:      int8(item.Location.Kind),
+		MeetingURL:        item.Location.MeetingURL,
+		Address:           item.Location.Address,
+		CabinetID:         cabinetID,
+		CabinetAuditorium: item.Cabinet.Auditorium,
+		CabinetBuilding:   item.Cabinet.Building,
+	}
+}
@@ -0,0 +1,473 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"schedule-generator/internal/application/usecases"
+	"schedule-generator/internal/common"
+	"schedule-generator/internal/domain/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type TimetableUsecase interface {
+	GetTeacherTimetable(ctx context.Context, input usecases.GetTeacherTimetableInput, user *users.User) (*usecases.GetTeacherTimetableOutput, error)
+	GetCabinetTimetable(ctx context.Context, input usecases.GetCabinetTimetableInput, user *users.User) (*usecases.GetCabinetTimetableOutput, error)
+	SearchFreeCabinets(ctx context.Context, input usecases.SearchFreeCabinetsInput, user *users.User) (usecases.SearchFreeCabinetsOutput, error)
+	FindFreeSlots(ctx context.Context, input usecases.FindFreeSlotsInput, user *users.User) (*usecases.FindFreeSlotsOutput, error)
+	ExportTeacherTimetable(ctx context.Context, input usecases.GetTeacherTimetableInput, format string, dst io.Writer, user *users.User) error
+	ExportCabinetTimetable(ctx context.Context, input usecases.GetCabinetTimetableInput, format string, dst io.Writer, user *users.User) error
+}
+
+type TimetableItem struct {
+	ScheduleItem
+	ScheduleID     uuid.UUID `json:"schedule_id"`
+	EduGroupID     uuid.UUID `json:"edu_group_id"`
+	EduGroupNumber string    `json:"edu_group_number"`
+}
+
+type TimetableSlot struct {
+	LessonNumber int8            `json:"lesson_number"`
+	Weektype     *int8           `json:"weektype,omitempty"`
+	Occupied     bool            `json:"occupied"`
+	Items        []TimetableItem `json:"items"`
+}
+
+type TimetableDay struct {
+	Weekday string          `json:"weekday"`
+	Date    *string         `json:"date"`
+	Items   []TimetableItem `json:"items"`
+	Slots   []TimetableSlot `json:"slots,omitempty"`
+}
+
+type Timetable struct {
+	View string         `json:"view"`
+	From *string        `json:"from"`
+	To   *string        `json:"to"`
+	Days []TimetableDay `json:"days"`
+}
+
+type TeacherTimetable struct {
+	Timetable
+	TeacherID   uuid.UUID `json:"teacher_id"`
+	TeacherName string    `json:"teacher_name"`
+}
+
+type CabinetTimetable struct {
+	Timetable
+	Cabinet Cabinet `json:"cabinet"`
+}
+
+type TimetableRequest struct {
+	ID   string `param:"id"`
+	Date string `query:"date"`
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+// GetTeacherTimetable - GET /v1/teachers/:id/schedule
+func (h *Handler) GetTeacherTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq TimetableRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	teacherID, err := uuid.Parse(rq.ID)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	input, err := parseTimetableRequest(rq)
+	if err != nil {
+		return err
+	}
+
+	out, err := h.timetable.GetTeacherTimetable(ctx, usecases.GetTeacherTimetableInput{
+		TimetableInput: input,
+		TeacherID:      teacherID,
+	}, user)
+	if err != nil {
+		h.logger.Error("Get teacher timetable error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, TeacherTimetable{
+		Timetable:   timetableDTOtoView(out.TimetableDTO),
+		TeacherID:   out.Teacher.ID,
+		TeacherName: out.Teacher.Name,
+	}).Send(c)
+}
+
+// GetCabinetTimetable - GET /v1/cabinets/:id/schedule
+func (h *Handler) GetCabinetTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq TimetableRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	cabinetID, err := uuid.Parse(rq.ID)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	input, err := parseTimetableRequest(rq)
+	if err != nil {
+		return err
+	}
+
+	out, err := h.timetable.GetCabinetTimetable(ctx, usecases.GetCabinetTimetableInput{
+		TimetableInput: input,
+		CabinetID:      cabinetID,
+	}, user)
+	if err != nil {
+		h.logger.Error("Get cabinet timetable error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, CabinetTimetable{
+		Timetable: timetableDTOtoView(out.TimetableDTO),
+		Cabinet:   cabinetToView(&out.Cabinet, ""),
+	}).Send(c)
+}
+
+type ExportTimetableRequest struct {
+	TimetableRequest
+	Format string `query:"format"`
+}
+
+// ExportTeacherTimetable - GET /v1/teachers/:id/schedule/export
+func (h *Handler) ExportTeacherTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq ExportTimetableRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	teacherID, err := uuid.Parse(rq.ID)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	input, err := parseTimetableRequest(rq.TimetableRequest)
+	if err != nil {
+		return err
+	}
+
+	fname := fmt.Sprintf("%s-%s.%s", teacherID, time.Now().Format("20060102150405"), rq.Format)
+
+	stream, err := NewFileStream(c, fname, rq.Format)
+	if err != nil {
+		return err
+	}
+
+	err = h.timetable.ExportTeacherTimetable(ctx, usecases.GetTeacherTimetableInput{
+		TimetableInput: input,
+		TeacherID:      teacherID,
+	}, rq.Format, stream, user)
+	if err != nil {
+		h.logger.Error("Export teacher timetable error", "error", err)
+		return stream.Fail(err)
+	}
+
+	return nil
+}
+
+// ExportCabinetTimetable - GET /v1/cabinets/:id/schedule/export
+func (h *Handler) ExportCabinetTimetable(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq ExportTimetableRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	cabinetID, err := uuid.Parse(rq.ID)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	input, err := parseTimetableRequest(rq.TimetableRequest)
+	if err != nil {
+		return err
+	}
+
+	fname := fmt.Sprintf("%s-%s.%s", cabinetID, time.Now().Format("20060102150405"), rq.Format)
+
+	stream, err := NewFileStream(c, fname, rq.Format)
+	if err != nil {
+		return err
+	}
+
+	err = h.timetable.ExportCabinetTimetable(ctx, usecases.GetCabinetTimetableInput{
+		TimetableInput: input,
+		CabinetID:      cabinetID,
+	}, rq.Format, stream, user)
+	if err != nil {
+		h.logger.Error("Export cabinet timetable error", "error", err)
+		return stream.Fail(err)
+	}
+
+	return nil
+}
+
+type SearchFreeCabinetsRequest struct {
+	FacultyID                          *uuid.UUID    `query:"faculty_id"`
+	Weekday                            *time.Weekday `query:"weekday"`
+	Weektype                           *int8         `query:"weektype"`
+	Date                               string        `query:"date"`
+	LessonNumber                       int8          `query:"lesson_number"`
+	CabinetType                        *int8         `query:"type"`
+	MinCapacity                        *int          `query:"min_capacity"`
+	SuitableForPeoplesWithSpecialNeeds *bool         `query:"suitable_for_peoples_with_special_needs"`
+	Equipment                          []string      `query:"equipment"`
+}
+
+// SearchFreeCabinets - GET /v1/cabinets/free
+func (h *Handler) SearchFreeCabinets(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq SearchFreeCabinetsRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	var date *time.Time
+	if len(rq.Date) > 0 {
+		if v, err := time.ParseInLocation(time.DateOnly, rq.Date, common.DefaultTimezone); err != nil {
+			return ErrInvalidInput
+		} else {
+			date = &v
+		}
+	}
+
+	out, err := h.timetable.SearchFreeCabinets(ctx, usecases.SearchFreeCabinetsInput{
+		FacultyID:                          rq.FacultyID,
+		Weekday:                            rq.Weekday,
+		Weektype:                           rq.Weektype,
+		Date:                               date,
+		LessonNumber:                       rq.LessonNumber,
+		CabinetType:                        rq.CabinetType,
+		MinCapacity:                        rq.MinCapacity,
+		SuitableForPeoplesWithSpecialNeeds: rq.SuitableForPeoplesWithSpecialNeeds,
+		Equipment:                          rq.Equipment,
+	}, user)
+	if err != nil {
+		h.logger.Error("Search free cabinets error", "error", err)
+		return err
+	}
+
+	result := make([]Cabinet, len(out))
+	for i, cabinet := range out {
+		result[i] = cabinetToView(&cabinet, "")
+	}
+
+	return WrapResponse(http.StatusOK, result).Send(c)
+}
+
+type FindFreeSlotsRequest struct {
+	TimetableRequest
+	Subgroup  int8       `query:"subgroup"`
+	TeacherID uuid.UUID  `query:"teacher_id"`
+	CabinetID *uuid.UUID `query:"cabinet_id"`
+}
+
+type FreeSlot struct {
+	Weekday      string  `json:"weekday"`
+	LessonNumber int8    `json:"lesson_number"`
+	Weektype     *int8   `json:"weektype,omitempty"`
+	Date         *string `json:"date,omitempty"`
+	Score        int     `json:"score"`
+}
+
+type FreeSlots struct {
+	View  string     `json:"view"`
+	Slots []FreeSlot `json:"slots"`
+}
+
+// FindFreeSlots - GET /v1/edu-groups/:id/free-slots
+func (h *Handler) FindFreeSlots(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	var rq FindFreeSlotsRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	groupID, err := uuid.Parse(rq.ID)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	input, err := parseTimetableRequest(rq.TimetableRequest)
+	if err != nil {
+		return err
+	}
+
+	out, err := h.timetable.FindFreeSlots(ctx, usecases.FindFreeSlotsInput{
+		TimetableInput: input,
+		EduGroupID:     groupID,
+		Subgroup:       rq.Subgroup,
+		TeacherID:      rq.TeacherID,
+		CabinetID:      rq.CabinetID,
+	}, user)
+	if err != nil {
+		h.logger.Error("Find free slots error", "error", err)
+		return err
+	}
+
+	slots := make([]FreeSlot, len(out.Slots))
+	for i, slot := range out.Slots {
+		var wt *int8
+		if slot.Weektype != nil {
+			v := int8(*slot.Weektype)
+			wt = &v
+		}
+
+		slots[i] = FreeSlot{
+			Weekday:      slot.Weekday.String(),
+			LessonNumber: slot.LessonNumber,
+			Weektype:     wt,
+			Date:         formatDate(slot.Date),
+			Score:        slot.Score,
+		}
+	}
+
+	return WrapResponse(http.StatusOK, FreeSlots{
+		View:  out.View.String(),
+		Slots: slots,
+	}).Send(c)
+}
+
+func parseTimetableRequest(rq TimetableRequest) (usecases.TimetableInput, error) {
+	var input usecases.TimetableInput
+
+	for _, v := range []struct {
+		raw string
+		dst **time.Time
+	}{
+		{rq.Date, &input.Date},
+		{rq.From, &input.From},
+		{rq.To, &input.To},
+	} {
+		if len(v.raw) == 0 {
+			continue
+		}
+
+		d, err := time.ParseInLocation(time.DateOnly, v.raw, common.DefaultTimezone)
+		if err != nil {
+			return input, ErrInvalidInput
+		}
+
+		*v.dst = &d
+	}
+
+	return input, nil
+}
+
+func timetableDTOtoView(dto usecases.TimetableDTO) Timetable {
+	days := make([]TimetableDay, len(dto.Days))
+
+	for i, day := range dto.Days {
+		var slots []TimetableSlot
+		if len(day.Slots) > 0 {
+			slots = make([]TimetableSlot, len(day.Slots))
+			for j, slot := range day.Slots {
+				var wt *int8
+				if slot.Weektype != nil {
+					v := int8(*slot.Weektype)
+					wt = &v
+				}
+
+				slots[j] = TimetableSlot{
+					LessonNumber: slot.LessonNumber,
+					Weektype:     wt,
+					Occupied:     slot.Occupied(),
+					Items:        timetableItemsToView(slot.Items),
+				}
+			}
+		}
+
+		days[i] = TimetableDay{
+			Weekday: day.Weekday.String(),
+			Date:    formatDate(day.Date),
+			Items:   timetableItemsToView(day.Items),
+			Slots:   slots,
+		}
+	}
+
+	return Timetable{
+		View: dto.View.String(),
+		From: formatDate(dto.From),
+		To:   formatDate(dto.To),
+		Days: days,
+	}
+}
+
+func timetableItemsToView(list []usecases.TimetableItemDTO) []TimetableItem {
+	items := make([]TimetableItem, len(list))
+	for i, item := range list {
+		items[i] = TimetableItem{
+			ScheduleItem:   scheduleItemDTOtoView(item.ScheduleItemDTO),
+			ScheduleID:     item.ScheduleID,
+			EduGroupID:     item.EduGroupID,
+			EduGroupNumber: item.EduGroupNumber,
+		}
+	}
+
+	return items
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	d := t.In(common.DefaultTimezone).Format(time.DateOnly)
+	return &d
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"schedule-generator/internal/application/usecases"
+	"schedule-generator/internal/common"
+	"schedule-generator/internal/domain/users"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type UsageUsecase interface {
+	ListTeacherUsage(ctx context.Context, teacherID uuid.UUID, user *users.User) (usecases.ListUsageOutput, error)
+	ListCabinetUsage(ctx context.Context, cabinetID uuid.UUID, user *users.User) (usecases.ListUsageOutput, error)
+	ListEduGroupUsage(ctx context.Context, groupID uuid.UUID, user *users.User) (usecases.ListUsageOutput, error)
+	ReassignTeacher(ctx context.Context, input usecases.ReassignTeacherInput, user *users.User) (*usecases.ReassignOutput, error)
+	ReassignCabinet(ctx context.Context, input usecases.ReassignCabinetInput, user *users.User) (*usecases.ReassignOutput, error)
+}
+
+type ScheduleUsage struct {
+	ScheduleID     uuid.UUID      `json:"schedule_id"`
+	EduGroupID     uuid.UUID      `json:"edu_group_id"`
+	EduGroupNumber string         `json:"edu_group_number"`
+	Semester       int            `json:"semester"`
+	Items          []ScheduleItem `json:"items"`
+}
+
+type Reassign struct {
+	SchedulesCount int `json:"schedules_count"`
+	ItemsCount     int `json:"items_count"`
+}
+
+// ListTeacherUsage - GET /v1/teachers/:id/usages
+func (h *Handler) ListTeacherUsage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	teacherID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.usage.ListTeacherUsage(ctx, teacherID, user)
+	if err != nil {
+		h.logger.Error("List teacher usage error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, usageToView(out)).Send(c)
+}
+
+// ListCabinetUsage - GET /v1/cabinets/:id/usages
+func (h *Handler) ListCabinetUsage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	cabinetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.usage.ListCabinetUsage(ctx, cabinetID, user)
+	if err != nil {
+		h.logger.Error("List cabinet usage error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, usageToView(out)).Send(c)
+}
+
+// ListEduGroupUsage - GET /v1/edu-groups/:id/usages
+func (h *Handler) ListEduGroupUsage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.usage.ListEduGroupUsage(ctx, groupID, user)
+	if err != nil {
+		h.logger.Error("List edu group usage error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, usageToView(out)).Send(c)
+}
+
+type ReassignTeacherRequest struct {
+	TeacherID uuid.UUID `json:"teacher_id"`
+	Since     *string   `json:"since"`
+}
+
+// ReassignTeacher - POST /v1/teachers/:id/reassign
+func (h *Handler) ReassignTeacher(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	teacherID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	var rq ReassignTeacherRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	since, err := parseSince(rq.Since)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.usage.ReassignTeacher(ctx, usecases.ReassignTeacherInput{
+		TeacherID:    teacherID,
+		NewTeacherID: rq.TeacherID,
+		Since:        since,
+	}, user)
+	if err != nil {
+		h.logger.Error("Reassign teacher error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, Reassign{
+		SchedulesCount: out.SchedulesCount,
+		ItemsCount:     out.ItemsCount,
+	}).Send(c)
+}
+
+type ReassignCabinetRequest struct {
+	CabinetID uuid.UUID `json:"cabinet_id"`
+	Since     *string   `json:"since"`
+}
+
+// ReassignCabinet - POST /v1/cabinets/:id/reassign
+func (h *Handler) ReassignCabinet(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := ExtractUserFromClaims(c)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	cabinetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	var rq ReassignCabinetRequest
+	if err := c.Bind(&rq); err != nil {
+		h.logger.Error("Parse request error", "error", err)
+		return ErrNotParsable
+	}
+
+	since, err := parseSince(rq.Since)
+	if err != nil {
+		return ErrInvalidInput
+	}
+
+	out, err := h.usage.ReassignCabinet(ctx, usecases.ReassignCabinetInput{
+		CabinetID:    cabinetID,
+		NewCabinetID: rq.CabinetID,
+		Since:        since,
+	}, user)
+	if err != nil {
+		h.logger.Error("Reassign cabinet error", "error", err)
+		return err
+	}
+
+	return WrapResponse(http.StatusOK, Reassign{
+		SchedulesCount: out.SchedulesCount,
+		ItemsCount:     out.ItemsCount,
+	}).Send(c)
+}
+
+func parseSince(s *string) (*time.Time, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+
+	since, err := time.ParseInLocation(time.DateOnly, *s, common.DefaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &since, nil
+}
+
+func usageToView(list usecases.ListUsageOutput) []ScheduleUsage {
+	result := make([]ScheduleUsage, len(list))
+	for i, usage := range list {
+		items := make([]ScheduleItem, len(usage.Items))
+		for j, item := range usage.Items {
+			items[j] = scheduleItemDTOtoView(item)
+		}
+
+		result[i] = ScheduleUsage{
+			ScheduleID:     usage.ScheduleID,
+			EduGroupID:     usage.EduGroupID,
+			EduGroupNumber: usage.EduGroupNumber,
+			Semester:       usage.Semester,
+			Items:          items,
+		}
+	}
+
+	return result
+}
@@ -6,4 +6,5 @@ var (
 	ErrorNotFound             = errors.New("not found")
 	ErrorUniqueViolation      = errors.New("unique violation")
 	ErrorAssociationViolation = errors.New("association violation")
+	ErrorVersionConflict      = errors.New("version conflict")
 )
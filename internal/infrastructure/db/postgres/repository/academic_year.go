@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	academicyears "schedule-generator/internal/domain/academic_years"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/internal/infrastructure/db/postgres/schema"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SaveAcademicYear
+func (r *Repository) SaveAcademicYear(ctx context.Context, y *academicyears.AcademicYear) error {
+	s := schema.AcademicYearToSchema(y)
+
+	err := r.client.WithContext(ctx).Save(s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return db.ErrorUniqueViolation
+		}
+
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return db.ErrorAssociationViolation
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// GetAcademicYear
+func (r *Repository) GetAcademicYear(ctx context.Context, id uuid.UUID) (*academicyears.AcademicYear, error) {
+	var s schema.AcademicYear
+	err := r.client.WithContext(ctx).Where("id = ?", id.String()).First(&s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	return schema.AcademicYearFromSchema(&s), nil
+}
+
+// GetAcademicYearByFacultyAndYear
+func (r *Repository) GetAcademicYearByFacultyAndYear(ctx context.Context, facultyID uuid.UUID, year int) (*academicyears.AcademicYear, error) {
+	var s schema.AcademicYear
+	err := r.client.WithContext(ctx).Where("faculty_id = ? AND year = ?", facultyID.String(), year).First(&s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	return schema.AcademicYearFromSchema(&s), nil
+}
+
+// ListAcademicYear
+func (r *Repository) ListAcademicYear(ctx context.Context) ([]academicyears.AcademicYear, error) {
+	var list []schema.AcademicYear
+	err := r.client.WithContext(ctx).Order("year DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]academicyears.AcademicYear, len(list))
+	for i, v := range list {
+		result[i] = *schema.AcademicYearFromSchema(&v)
+	}
+
+	return result, nil
+}
+
+// ListAcademicYearByFaculty
+func (r *Repository) ListAcademicYearByFaculty(ctx context.Context, facultyID uuid.UUID) ([]academicyears.AcademicYear, error) {
+	var list []schema.AcademicYear
+	err := r.client.WithContext(ctx).Where("faculty_id = ?", facultyID.String()).Order("year DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]academicyears.AcademicYear, len(list))
+	for i, v := range list {
+		result[i] = *schema.AcademicYearFromSchema(&v)
+	}
+
+	return result, nil
+}
+
+// DeleteAcademicYear
+func (r *Repository) DeleteAcademicYear(ctx context.Context, id uuid.UUID) error {
+	err := r.client.WithContext(ctx).Where("id = ?", id).Delete(&schema.AcademicYear{}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -12,10 +12,27 @@ import (
 	"gorm.io/gorm"
 )
 
+// SaveCabinet saves cabinet and links to it schedule items which legacy address was not matched on migration
 func (r *Repository) SaveCabinet(ctx context.Context, c *cabinets.Cabinet) error {
 	s := schema.CabinetToSchema(c)
 
-	err := r.client.WithContext(ctx).Save(s).Error
+	err := r.client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(s).Error; err != nil {
+			return err
+		}
+
+		if !tx.Migrator().HasColumn(&schema.ScheduleItem{}, "cabinet_building") {
+			return nil
+		}
+
+		return tx.Exec(`
+			UPDATE schedule_items SET cabinet_id = ?
+			WHERE cabinet_id IS NULL
+				AND location_kind = 0
+				AND cabinet_building = ?
+				AND cabinet_auditorium = ?
+		`, s.ID, s.Building, s.Auditorium).Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return db.ErrorUniqueViolation
@@ -45,6 +62,21 @@ func (r *Repository) GetCabinet(ctx context.Context, id uuid.UUID) (*cabinets.Ca
 	return schema.CabinetFromSchema(&s), nil
 }
 
+// GetCabinetByAddress returns cabinet by building and auditorium
+func (r *Repository) GetCabinetByAddress(ctx context.Context, building, auditorium string) (*cabinets.Cabinet, error) {
+	var s schema.Cabinet
+	err := r.client.WithContext(ctx).Where("building = ? AND auditorium = ?", building, auditorium).First(&s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	return schema.CabinetFromSchema(&s), nil
+}
+
 func (r *Repository) ListCabinet(ctx context.Context) ([]cabinets.Cabinet, error) {
 	var list []schema.Cabinet
 
@@ -65,9 +97,33 @@ func (r *Repository) ListCabinet(ctx context.Context) ([]cabinets.Cabinet, error
 	return result, nil
 }
 
+func (r *Repository) ListCabinetByFaculty(ctx context.Context, facultyID uuid.UUID) ([]cabinets.Cabinet, error) {
+	var list []schema.Cabinet
+
+	err := r.client.WithContext(ctx).Where("faculty_id = ?", facultyID).Order("building, auditorium").Find(&list).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	result := make([]cabinets.Cabinet, len(list))
+	for i, v := range list {
+		result[i] = *schema.CabinetFromSchema(&v)
+	}
+
+	return result, nil
+}
+
 func (r *Repository) DeleteCabinet(ctx context.Context, id uuid.UUID) error {
 	err := r.client.WithContext(ctx).Where("id = ?", id).Delete(&schema.Cabinet{}).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return db.ErrorAssociationViolation
+		}
+
 		return err
 	}
 
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"schedule-generator/internal/application/acl/exporter"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/internal/infrastructure/db/postgres/schema"
+
+	"gorm.io/gorm"
+)
+
+// SaveCsvProfile
+func (r *Repository) SaveCsvProfile(ctx context.Context, p *exporter.CsvProfile) error {
+	s := schema.CsvProfileToSchema(p)
+
+	err := r.client.WithContext(ctx).Save(s).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetCsvProfile
+func (r *Repository) GetCsvProfile(ctx context.Context, name string) (*exporter.CsvProfile, error) {
+	var s schema.CsvProfile
+	err := r.client.WithContext(ctx).Where("name = ?", name).First(&s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	return schema.CsvProfileFromSchema(&s), nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"schedule-generator/internal/application/acl/exporter"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/internal/infrastructure/db/postgres/schema"
+
+	"github.com/google/uuid"
+)
+
+func TestRepository_CsvProfile(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	profile := &exporter.CsvProfile{
+		Name:            "profile " + uuid.NewString(),
+		Encoding:        "windows-1251",
+		Delimiter:       ",",
+		DateFormat:      "2006-01-02",
+		LessonTypes:     map[string]string{"lecture": "Лекция"},
+		OddWeek:         "odd",
+		EvenWeek:        "even",
+		CycledColumns:   []exporter.CsvColumn{{Header: "Group", Field: "group"}, {Header: "Source", Value: "schedule"}},
+		CalendarColumns: []exporter.CsvColumn{{Header: "Date", Field: "date"}},
+	}
+
+	if err := r.SaveCsvProfile(ctx, profile); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		r.client.Where("name = ?", profile.Name).Delete(&schema.CsvProfile{})
+	})
+
+	stored, err := r.GetCsvProfile(ctx, profile.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.Encoding != profile.Encoding || stored.Delimiter != profile.Delimiter || stored.DateFormat != profile.DateFormat ||
+		stored.OddWeek != profile.OddWeek || stored.EvenWeek != profile.EvenWeek {
+		t.Errorf("expected settings %+v, got: %+v", profile, stored)
+	}
+
+	if !maps.Equal(stored.LessonTypes, profile.LessonTypes) || !slices.Equal(stored.CycledColumns, profile.CycledColumns) ||
+		!slices.Equal(stored.CalendarColumns, profile.CalendarColumns) {
+		t.Errorf("expected lesson types and columns %+v, got: %+v", profile, stored)
+	}
+
+	if _, err := r.GetCsvProfile(ctx, "missing "+uuid.NewString()); !errors.Is(err, db.ErrorNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
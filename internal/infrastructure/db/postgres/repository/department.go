@@ -107,6 +107,22 @@ func (r *Repository) MapDepartmentsByEduDirections(ctx context.Context, directio
 	return result, nil
 }
 
+// MapDepartmentByIDs
+func (r *Repository) MapDepartmentByIDs(ctx context.Context, departmentIDs uuid.UUIDs) (map[uuid.UUID]departments.Department, error) {
+	var list []schema.Department
+	err := r.client.WithContext(ctx).Where("id IN ?", departmentIDs).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]departments.Department, len(list))
+	for _, v := range list {
+		result[v.ID] = *schema.DepartmentFromSchema(&v)
+	}
+
+	return result, nil
+}
+
 // DeleteDepartment
 func (r *Repository) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
 	err := r.client.WithContext(ctx).Where("id = ?", id).Delete(&schema.Department{}).Error
@@ -14,8 +14,12 @@ import (
 
 // SaveFaculty
 func (r *Repository) SaveFaculty(ctx context.Context, d *faculties.Faculty) error {
-	s := schema.FacultyToSchema(d)
-	err := r.client.WithContext(ctx).Save(s).Error
+	s, err := schema.FacultyToSchema(d)
+	if err != nil {
+		return err
+	}
+
+	err = r.client.WithContext(ctx).Save(s).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return db.ErrorUniqueViolation
@@ -115,7 +119,7 @@ func (r *Repository) MapFacultiesByUsers(ctx context.Context, userIDs uuid.UUIDs
 
 	result := make(map[uuid.UUID]faculties.Faculty)
 	for _, userSchema := range userList {
-		if userSchema.Faculty == nil || userSchema.Faculty == nil {
+		if userSchema.FacultyID == nil || userSchema.Faculty == nil {
 			continue
 		}
 
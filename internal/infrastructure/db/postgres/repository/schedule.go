@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"schedule-generator/internal/domain/schedules"
 	"schedule-generator/internal/infrastructure/db"
@@ -13,16 +15,33 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-// SaveSchedule
+// SaveSchedule saves new schedule or replaces stored one when its version was not changed since schedule was read.
+// Only added, changed and removed items are written. Version of saved schedule is incremented
 func (r *Repository) SaveSchedule(ctx context.Context, d *schedules.Schedule) error {
 	s := schema.ScheduleToSchema(d)
+	s.Version = d.Version + 1
+
+	err := r.client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if d.Version > 0 {
+			res := tx.Model(&schema.Schedule{}).
+				Where("id = ? AND version = ?", s.ID, d.Version).
+				Update("version", s.Version)
+			if res.Error != nil {
+				return res.Error
+			}
+
+			if res.RowsAffected == 0 {
+				return db.ErrorVersionConflict
+			}
+		}
 
-	err := r.client.WithContext(ctx).Delete(&schema.ScheduleItem{}, "schedule_id = ?", s.ID).Error
-	if err != nil {
-		return err
-	}
+		err := tx.Omit(clause.Associations).Save(s).Error
+		if err != nil {
+			return err
+		}
 
-	err = r.client.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(s).Error
+		return saveScheduleItems(tx, s.ID, s.Items)
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return db.ErrorUniqueViolation
@@ -35,6 +54,79 @@ func (r *Repository) SaveSchedule(ctx context.Context, d *schedules.Schedule) er
 		return err
 	}
 
+	d.Version = s.Version
+
+	return nil
+}
+
+// saveScheduleItems compares items with stored ones by item id and writes the difference
+func saveScheduleItems(tx *gorm.DB, scheduleID uuid.UUID, items []schema.ScheduleItem) error {
+	var stored []schema.ScheduleItem
+	err := tx.Preload("Teachers").Where("schedule_id = ?", scheduleID).Find(&stored).Error
+	if err != nil {
+		return err
+	}
+
+	storedByID := make(map[uuid.UUID]*schema.ScheduleItem, len(stored))
+	for i := range stored {
+		storedByID[stored[i].ItemID] = &stored[i]
+	}
+
+	var added, changed []*schema.ScheduleItem
+	for i := range items {
+		item := &items[i]
+
+		prev, ok := storedByID[item.ItemID]
+		if !ok {
+			added = append(added, item)
+			continue
+		}
+
+		delete(storedByID, item.ItemID)
+
+		if !prev.Equal(item) {
+			item.ID = prev.ID
+			changed = append(changed, item)
+		}
+	}
+
+	// removed items are deleted first to free their lessons in unique indexes
+	if len(storedByID) > 0 {
+		removed := make([]int64, 0, len(storedByID))
+		for _, item := range storedByID {
+			removed = append(removed, item.ID)
+		}
+
+		err = tx.Delete(&schema.ScheduleItemTeacher{}, "schedule_item_id IN ?", removed).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Delete(&schema.ScheduleItem{}, removed).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range changed {
+		err = tx.Delete(&schema.ScheduleItemTeacher{}, "schedule_item_id = ?", item.ID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Save(item).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(added) > 0 {
+		err = tx.Create(added).Error
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -48,7 +140,7 @@ func (r *Repository) GetSchedule(ctx context.Context, id uuid.UUID) (*schedules.
 			schedule_items.date NULLS LAST,
 			schedule_items.weektype NULLS LAST
 		`)
-	}).Where("id = ?", id.String()).First(&s).Error
+	}).Preload("Items.Teachers").Preload("Items.Cabinet").Where("id = ?", id.String()).First(&s).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, db.ErrorNotFound
@@ -57,7 +149,7 @@ func (r *Repository) GetSchedule(ctx context.Context, id uuid.UUID) (*schedules.
 		return nil, err
 	}
 
-	return schema.ScheduleFromSchema(&s), nil
+	return schema.ScheduleFromSchema(&s)
 }
 
 // GetScheduleFacultyID
@@ -78,14 +170,7 @@ func (r *Repository) GetScheduleFacultyID(ctx context.Context, scheduleID uuid.U
 // GetScheduleByEduGroupIDAndSemester
 func (r *Repository) GetScheduleByEduGroupIDAndSemester(ctx context.Context, eduGroupID uuid.UUID, semester int) (*schedules.Schedule, error) {
 	var s schema.Schedule
-	err := r.client.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
-		return db.Order(`
-			schedule_items.date NULLS LAST,
-			schedule_items.weektype NULLS LAST,
-			schedule_items.lesson_number,
-			schedule_items.subgroup
-		`)
-	}).Where("edu_group_id = ? AND semester = ?", eduGroupID.String(), semester).First(&s).Error
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems()).Where("edu_group_id = ? AND semester = ?", eduGroupID.String(), semester).First(&s).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, db.ErrorNotFound
@@ -94,20 +179,13 @@ func (r *Repository) GetScheduleByEduGroupIDAndSemester(ctx context.Context, edu
 		return nil, err
 	}
 
-	return schema.ScheduleFromSchema(&s), nil
+	return schema.ScheduleFromSchema(&s)
 }
 
 // ListSchedule
 func (r *Repository) ListSchedule(ctx context.Context) ([]schedules.Schedule, error) {
 	var list []schema.Schedule
-	err := r.client.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
-		return db.Order(`
-			schedule_items.date NULLS LAST,
-			schedule_items.weektype NULLS LAST,
-			schedule_items.lesson_number,
-			schedule_items.subgroup
-		`)
-	}).Order("edu_group_id ASC, semester DESC").Find(&list).Error
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems()).Order("edu_group_id ASC, semester DESC").Find(&list).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, db.ErrorNotFound
@@ -116,25 +194,13 @@ func (r *Repository) ListSchedule(ctx context.Context) ([]schedules.Schedule, er
 		return nil, err
 	}
 
-	result := make([]schedules.Schedule, len(list))
-	for i, v := range list {
-		result[i] = *schema.ScheduleFromSchema(&v)
-	}
-
-	return result, nil
+	return schedulesFromSchema(list)
 }
 
 // ListSchedule
 func (r *Repository) ListScheduleByFaculty(ctx context.Context, facultyID uuid.UUID) ([]schedules.Schedule, error) {
 	var list []schema.Schedule
-	err := r.client.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
-		return db.Order(`
-			schedule_items.date NULLS LAST,
-			schedule_items.weektype NULLS LAST,
-			schedule_items.lesson_number,
-			schedule_items.subgroup
-		`)
-	}).Joins("EduGroup.EduPlan.Direction.Department").Where(`"EduGroup__EduPlan__Direction__Department".faculty_id = ?`, facultyID).Order("edu_group_id ASC, semester DESC").Find(&list).Error
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems()).Joins("EduGroup.EduPlan.Direction.Department").Where(`"EduGroup__EduPlan__Direction__Department".faculty_id = ?`, facultyID).Order("edu_group_id ASC, semester DESC").Find(&list).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, db.ErrorNotFound
@@ -143,25 +209,13 @@ func (r *Repository) ListScheduleByFaculty(ctx context.Context, facultyID uuid.U
 		return nil, err
 	}
 
-	result := make([]schedules.Schedule, len(list))
-	for i, v := range list {
-		result[i] = *schema.ScheduleFromSchema(&v)
-	}
-
-	return result, nil
+	return schedulesFromSchema(list)
 }
 
 // ListScheduleByEduGroup
 func (r *Repository) ListScheduleByEduGroup(ctx context.Context, groupID uuid.UUID) ([]schedules.Schedule, error) {
 	var list []schema.Schedule
-	err := r.client.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
-		return db.Order(`
-			schedule_items.date NULLS LAST,
-			schedule_items.weektype NULLS LAST,
-			schedule_items.lesson_number,
-			schedule_items.subgroup
-		`)
-	}).Where("edu_group_id = ?", groupID).Order("semester DESC").Find(&list).Error
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems()).Where("edu_group_id = ?", groupID).Order("semester DESC").Find(&list).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, db.ErrorNotFound
@@ -170,20 +224,102 @@ func (r *Repository) ListScheduleByEduGroup(ctx context.Context, groupID uuid.UU
 		return nil, err
 	}
 
-	result := make([]schedules.Schedule, len(list))
-	for i, v := range list {
-		result[i] = *schema.ScheduleFromSchema(&v)
+	return schedulesFromSchema(list)
+}
+
+// ListScheduleByTeacher returns schedules which have at least one item of specified teacher
+func (r *Repository) ListScheduleByTeacher(ctx context.Context, teacherID uuid.UUID) ([]schedules.Schedule, error) {
+	var list []schema.Schedule
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems()).Where("id IN (?)", r.client.Model(&schema.ScheduleItem{}).Select("schedule_id").Where("teacher_id = ? OR id IN (?)", teacherID,
+		r.client.Model(&schema.ScheduleItemTeacher{}).Select("schedule_item_id").Where("teacher_id = ?", teacherID))).
+		Order("edu_group_id ASC, semester DESC").Find(&list).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
 	}
 
-	return result, nil
+	return schedulesFromSchema(list)
 }
 
-// DeleteSchedule
-func (r *Repository) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
-	err := r.client.WithContext(ctx).Where("id = ?", id).Delete(&schema.Schedule{}).Error
+// ListScheduleByCabinet returns schedules which have at least one item in specified cabinet
+func (r *Repository) ListScheduleByCabinet(ctx context.Context, cabinetID uuid.UUID) ([]schedules.Schedule, error) {
+	var list []schema.Schedule
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems()).Where("id IN (?)", r.client.Model(&schema.ScheduleItem{}).Select("schedule_id").Where("cabinet_id = ?", cabinetID)).
+		Order("edu_group_id ASC, semester DESC").Find(&list).Error
 	if err != nil {
-		return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	return schedulesFromSchema(list)
+}
+
+// ListScheduleBySlot returns schedules which have items taking specified lesson number on weekday. Only items
+// of the slot are loaded, so returned schedules must not be saved
+func (r *Repository) ListScheduleBySlot(ctx context.Context, weekday time.Weekday, lessonNumber int8) ([]schedules.Schedule, error) {
+	slot := []any{"weekday = ? AND lesson_number <= ? AND lesson_number + duration > ?", weekday, lessonNumber, lessonNumber}
+
+	var list []schema.Schedule
+	err := r.client.WithContext(ctx).Scopes(preloadScheduleItems(slot...)).
+		Where("id IN (?)", r.client.Model(&schema.ScheduleItem{}).Select("schedule_id").Where(slot[0], slot[1:]...)).
+		Order("edu_group_id ASC, semester DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return schedulesFromSchema(list)
+}
+
+// DeleteSchedule deletes schedule of version, db.ErrorVersionConflict is returned when schedule was changed or
+// deleted since it was read
+func (r *Repository) DeleteSchedule(ctx context.Context, id uuid.UUID, version int) error {
+	res := r.client.WithContext(ctx).Where("id = ? AND version = ?", id, version).Delete(&schema.Schedule{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return db.ErrorVersionConflict
 	}
 
 	return nil
 }
+
+// preloadScheduleItems preloads ordered items of schedules with their teachers and cabinets. Items are filtered
+// by conds when provided
+func preloadScheduleItems(conds ...any) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+			if len(conds) > 0 {
+				db = db.Where(conds[0], conds[1:]...)
+			}
+
+			return db.Order(`
+				schedule_items.date NULLS LAST,
+				schedule_items.weektype NULLS LAST,
+				schedule_items.lesson_number,
+				schedule_items.subgroup
+			`)
+		}).Preload("Items.Teachers").Preload("Items.Cabinet")
+	}
+}
+
+func schedulesFromSchema(list []schema.Schedule) ([]schedules.Schedule, error) {
+	result := make([]schedules.Schedule, len(list))
+	for i, v := range list {
+		s, err := schema.ScheduleFromSchema(&v)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s error: %w", v.ID, err)
+		}
+
+		result[i] = *s
+	}
+
+	return result, nil
+}
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"schedule-generator/internal/domain/cabinets"
+	"schedule-generator/internal/domain/schedules"
+	"schedule-generator/internal/infrastructure/db"
+	"schedule-generator/internal/infrastructure/db/postgres/schema"
+	"schedule-generator/pkg/pggorm"
+
+	"github.com/google/uuid"
+)
+
+// newTestRepository returns repository of migrated database from TEST_POSTGRES_CONNECTION_URL, test is skipped
+// when database is not provided
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	connUrl := os.Getenv("TEST_POSTGRES_CONNECTION_URL")
+	if connUrl == "" {
+		t.Skip("TEST_POSTGRES_CONNECTION_URL is not set")
+	}
+
+	client, err := pggorm.NewDB(connUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	if err := schema.NewMigrator(client.DB()).Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewPostgresRepository(client.DB())
+}
+
+// createTestGroup creates edu group with its faculty and returns it with teachers of group department
+func createTestGroup(t *testing.T, r *Repository, teachersCount int) (*schema.EduGroup, []uuid.UUID) {
+	t.Helper()
+
+	suffix := uuid.NewString()
+
+	faculty := &schema.Faculty{ID: uuid.New(), Name: "faculty " + suffix, WorkingWeek: 126}
+	department := &schema.Department{ID: uuid.New(), ExternalID: "department " + suffix, FacultyID: faculty.ID, Name: "department"}
+	direction := &schema.EduDirection{ID: uuid.New(), Name: "direction", DepartmentID: department.ID}
+	plan := &schema.EduPlan{ID: uuid.New(), DirectionID: direction.ID, Profile: "profile", Year: 2025}
+	group := &schema.EduGroup{ID: uuid.New(), Number: "group " + suffix, EduPlanID: plan.ID, Profile: "profile", AdmissionYear: 2025}
+
+	teacherIDs := make([]uuid.UUID, teachersCount)
+	teacherRows := make([]schema.Teacher, teachersCount)
+	for i := range teacherRows {
+		teacherIDs[i] = uuid.New()
+		teacherRows[i] = schema.Teacher{ID: teacherIDs[i], ExternalID: teacherIDs[i].String(), Name: "teacher", DepartmentID: department.ID}
+	}
+
+	for _, row := range []any{faculty, department, direction, plan, group, &teacherRows} {
+		if err := r.client.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Cleanup(func() {
+		r.client.Where("edu_group_id = ?", group.ID).Delete(&schema.Schedule{})
+		for _, row := range []any{group, plan, direction, &teacherRows, department, faculty} {
+			r.client.Delete(row)
+		}
+	})
+
+	return group, teacherIDs
+}
+
+func TestRepository_SaveSchedule(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	group, teacherIDs := createTestGroup(t, r, 3)
+	main, assistant, other := teacherIDs[0], teacherIDs[1], teacherIDs[2]
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(lessonNumber int8, assistantID uuid.UUID) error {
+		teachers := []schedules.ItemTeacher{{TeacherID: main}, {TeacherID: assistantID, Role: schedules.TeacherRoleAssistant}}
+		location := schedules.Location{Kind: schedules.LocationKindOnline, MeetingURL: "https://meet.example.com/lesson"}
+
+		return schedule.Cycled.AddItem("discipline", teachers, time.Monday, 0, lessonNumber, 0, 0, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLecture), location, schedules.Cabinet{})
+	}
+
+	for _, lessonNumber := range []int8{0, 1} {
+		if err := add(lessonNumber, assistant); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// second save removes item with teachers and changes teachers of another one
+	items := schedule.Cycled.ListItemByWeekday(time.Monday)
+	removed, changed := items[0].ID, items[1].ID
+
+	if err := schedule.RemoveItemByID(removed); err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.UpdateItem(changed, func() error {
+		return add(1, other)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := r.GetSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storedItems := stored.ListItem()
+	if len(storedItems) != 1 || storedItems[0].ID != changed {
+		t.Fatalf("expected only changed item to be stored, got: %+v", storedItems)
+	}
+
+	if storedItems[0].MainTeacherID() != main || !storedItems[0].HasTeacher(other) || storedItems[0].HasTeacher(assistant) {
+		t.Errorf("expected replaced assistant, got: %+v", storedItems[0].Teachers)
+	}
+
+	if stored.Version != 2 {
+		t.Errorf("expected version 2, got: %d", stored.Version)
+	}
+}
+
+func TestRepository_GetSchedule_LegacyCabinet(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	// columns are left by migration from copied cabinet addresses
+	err := r.client.Exec("ALTER TABLE schedule_items ADD COLUMN IF NOT EXISTS cabinet_building text, ADD COLUMN IF NOT EXISTS cabinet_auditorium text").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group, teacherIDs := createTestGroup(t, r, 1)
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.Cycled.AddItem("discipline", []schedules.ItemTeacher{{TeacherID: teacherIDs[0]}}, time.Monday, 0, 0, 0, 0, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLecture), schedules.Location{}, schedules.Cabinet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.client.Exec("UPDATE schedule_items SET cabinet_building = ?, cabinet_auditorium = ? WHERE schedule_id = ?", "1", "101", schedule.ID).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := r.GetSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cabinet := stored.ListItem()[0].Cabinet
+	if cabinet.ID != uuid.Nil || cabinet.Building != "1" || cabinet.Auditorium != "101" {
+		t.Errorf("expected legacy cabinet address, got: %+v", cabinet)
+	}
+}
+
+func TestRepository_SaveCabinet_LinksLegacyItems(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	err := r.client.Exec("ALTER TABLE schedule_items ADD COLUMN IF NOT EXISTS cabinet_building text, ADD COLUMN IF NOT EXISTS cabinet_auditorium text").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cabinet is deleted after schedule of group which references it
+	faculty := &schema.Faculty{ID: uuid.New(), Name: "faculty " + uuid.NewString(), WorkingWeek: 126}
+	if err := r.client.Create(faculty).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cabinet := &cabinets.Cabinet{ID: uuid.New(), FacultyID: faculty.ID, Type: cabinets.CabinetTypePractice, Building: "legacy " + uuid.NewString(), Auditorium: "101"}
+
+	t.Cleanup(func() {
+		r.client.Delete(&schema.Cabinet{}, "id = ?", cabinet.ID)
+		r.client.Delete(faculty)
+	})
+
+	group, teacherIDs := createTestGroup(t, r, 1)
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.Cycled.AddItem("discipline", []schedules.ItemTeacher{{TeacherID: teacherIDs[0]}}, time.Monday, 0, 0, 0, 0, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLecture), schedules.Location{}, schedules.Cabinet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// address of item matches no cabinet on migration
+	err = r.client.Exec("UPDATE schedule_items SET cabinet_building = ?, cabinet_auditorium = ? WHERE schedule_id = ?", cabinet.Building, cabinet.Auditorium, schedule.ID).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SaveCabinet(ctx, cabinet); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := r.ListScheduleByCabinet(ctx, cabinet.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || list[0].ID != schedule.ID {
+		t.Fatalf("expected schedule with legacy item in cabinet, got: %d schedules", len(list))
+	}
+
+	if item := list[0].ListItem()[0]; item.Cabinet.ID != cabinet.ID {
+		t.Errorf("expected item linked to cabinet %s, got: %+v", cabinet.ID, item.Cabinet)
+	}
+
+	if err := r.DeleteCabinet(ctx, cabinet.ID); !errors.Is(err, db.ErrorAssociationViolation) {
+		t.Errorf("expected association violation on delete of used cabinet, got: %v", err)
+	}
+}
+
+func TestRepository_GetSchedule_InvalidItem(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	group, teacherIDs := createTestGroup(t, r, 1)
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schedule.Cycled.AddItem("discipline", []schedules.ItemTeacher{{TeacherID: teacherIDs[0]}}, time.Monday, 0, 0, 0, 0, int8(schedules.WeekTypeBoth), nil, nil, int8(schedules.ItemTypeLecture), schedules.Location{}, schedules.Cabinet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.client.Exec("UPDATE schedule_items SET weeks = ? WHERE schedule_id = ?", "first", schedule.ID).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// schedule without invalid item must not be loaded, otherwise the item is deleted on next save
+	if _, err := r.GetSchedule(ctx, schedule.ID); err == nil {
+		t.Error("expected error on loading schedule with invalid item, got nil")
+	}
+}
+
+func TestRepository_DeleteSchedule(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	group, _ := createTestGroup(t, r, 1)
+
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := schedules.NewCycledSchedule(group.ID, 1, start, start.AddDate(0, 0, 13), 2025, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// schedule is changed by another user after it was read
+	read := schedule.Version
+	if err := r.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.DeleteSchedule(ctx, schedule.ID, read); !errors.Is(err, db.ErrorVersionConflict) {
+		t.Fatalf("expected version conflict, got: %v", err)
+	}
+
+	if _, err := r.GetSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("expected schedule to be kept, got: %v", err)
+	}
+
+	if err := r.DeleteSchedule(ctx, schedule.ID, schedule.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.GetSchedule(ctx, schedule.ID); !errors.Is(err, db.ErrorNotFound) {
+		t.Errorf("expected schedule to be deleted, got: %v", err)
+	}
+}
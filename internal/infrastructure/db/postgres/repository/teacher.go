@@ -46,6 +46,21 @@ func (r *Repository) GetTeacher(ctx context.Context, id uuid.UUID) (*teachers.Te
 	return schema.TeacherFromSchema(&s), nil
 }
 
+// GetTeacherByExternalID
+func (r *Repository) GetTeacherByExternalID(ctx context.Context, externalID string) (*teachers.Teacher, error) {
+	var s schema.Teacher
+	err := r.client.WithContext(ctx).Where("external_id = ?", externalID).First(&s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, db.ErrorNotFound
+		}
+
+		return nil, err
+	}
+
+	return schema.TeacherFromSchema(&s), nil
+}
+
 // GetTeacherFacultyID
 func (r *Repository) GetTeacherFacultyID(ctx context.Context, teacherID uuid.UUID) (uuid.UUID, error) {
 	var s schema.Teacher
@@ -121,6 +136,10 @@ func (r *Repository) MapTeacherByIDs(ctx context.Context, teacherIDs uuid.UUIDs)
 func (r *Repository) DeleteTeacher(ctx context.Context, id uuid.UUID) error {
 	err := r.client.WithContext(ctx).Where("id = ?", id).Delete(&schema.Teacher{}).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return db.ErrorAssociationViolation
+		}
+
 		return err
 	}
 
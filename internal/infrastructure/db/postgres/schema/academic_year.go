@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"time"
+
+	academicyears "schedule-generator/internal/domain/academic_years"
+
+	"github.com/google/uuid"
+)
+
+type AcademicYear struct {
+	ID                  uuid.UUID `gorm:"column:id;type:string;primaryKey"`
+	FacultyID           uuid.UUID `gorm:"column:faculty_id;type:string;uniqueIndex:academic_year_faculty_year;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Faculty             *Faculty  `gorm:"foreignKey:faculty_id"`
+	Year                int       `gorm:"column:year;uniqueIndex:academic_year_faculty_year;not null"`
+	FallSemesterStart   time.Time `gorm:"column:fall_semester_start;not null"`
+	SpringSemesterStart time.Time `gorm:"column:spring_semester_start;not null"`
+	WeeksFromMonday     bool      `gorm:"column:weeks_from_monday;not null;default:false"`
+}
+
+// AcademicYearToSchema
+func AcademicYearToSchema(model *academicyears.AcademicYear) *AcademicYear {
+	return &AcademicYear{
+		ID:                  model.ID,
+		FacultyID:           model.FacultyID,
+		Year:                model.Year,
+		FallSemesterStart:   model.FallSemesterStart,
+		SpringSemesterStart: model.SpringSemesterStart,
+		WeeksFromMonday:     model.WeeksFromMonday,
+	}
+}
+
+// AcademicYearFromSchema
+func AcademicYearFromSchema(scheme *AcademicYear) *academicyears.AcademicYear {
+	return &academicyears.AcademicYear{
+		ID:                  scheme.ID,
+		FacultyID:           scheme.FacultyID,
+		Year:                scheme.Year,
+		FallSemesterStart:   scheme.FallSemesterStart,
+		SpringSemesterStart: scheme.SpringSemesterStart,
+		WeeksFromMonday:     scheme.WeeksFromMonday,
+	}
+}
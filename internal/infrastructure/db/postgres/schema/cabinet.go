@@ -18,6 +18,7 @@ type Cabinet struct {
 	EquipmentTechnicalMeans            *string   `gorm:"column:equipment_technical_means"`
 	EquipmentСomputerEquipment         *string   `gorm:"column:equipment_computer"`
 	SuitableForPeoplesWithSpecialNeeds bool      `gorm:"column:suitable_for_peoples_with_special_needs"`
+	Capacity                           int       `gorm:"column:capacity;not null;default:0"`
 }
 
 // CabinetToSchema
@@ -30,6 +31,7 @@ func CabinetToSchema(c *cabinets.Cabinet) *Cabinet {
 		Type:                               int8(c.Type),
 		Appointment:                        c.Appointment,
 		SuitableForPeoplesWithSpecialNeeds: c.SuitableForPeoplesWithSpecialNeeds,
+		Capacity:                           c.Capacity,
 	}
 
 	if c.Equipment != nil {
@@ -51,6 +53,7 @@ func CabinetFromSchema(scheme *Cabinet) *cabinets.Cabinet {
 		Type:                               cabinets.CabinetType(scheme.Type),
 		Appointment:                        scheme.Appointment,
 		SuitableForPeoplesWithSpecialNeeds: scheme.SuitableForPeoplesWithSpecialNeeds,
+		Capacity:                           scheme.Capacity,
 	}
 
 	if scheme.EquipmentFurniture != nil {
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"schedule-generator/internal/application/acl/exporter"
+)
+
+type CsvProfile struct {
+	Name            string               `gorm:"column:name;primaryKey"`
+	Encoding        string               `gorm:"column:encoding;not null;default:''"`
+	Delimiter       string               `gorm:"column:delimiter;not null;default:''"`
+	DateFormat      string               `gorm:"column:date_format;not null;default:''"`
+	LessonTypes     map[string]string    `gorm:"column:lesson_types;type:jsonb;serializer:json"`
+	OddWeek         string               `gorm:"column:odd_week;not null;default:''"`
+	EvenWeek        string               `gorm:"column:even_week;not null;default:''"`
+	CycledColumns   []exporter.CsvColumn `gorm:"column:cycled_columns;type:jsonb;serializer:json"`
+	CalendarColumns []exporter.CsvColumn `gorm:"column:calendar_columns;type:jsonb;serializer:json"`
+}
+
+// CsvProfileToSchema
+func CsvProfileToSchema(model *exporter.CsvProfile) *CsvProfile {
+	return &CsvProfile{
+		Name:            model.Name,
+		Encoding:        model.Encoding,
+		Delimiter:       model.Delimiter,
+		DateFormat:      model.DateFormat,
+		LessonTypes:     model.LessonTypes,
+		OddWeek:         model.OddWeek,
+		EvenWeek:        model.EvenWeek,
+		CycledColumns:   model.CycledColumns,
+		CalendarColumns: model.CalendarColumns,
+	}
+}
+
+// CsvProfileFromSchema
+func CsvProfileFromSchema(scheme *CsvProfile) *exporter.CsvProfile {
+	return &exporter.CsvProfile{
+		Name:            scheme.Name,
+		Encoding:        scheme.Encoding,
+		Delimiter:       scheme.Delimiter,
+		DateFormat:      scheme.DateFormat,
+		LessonTypes:     scheme.LessonTypes,
+		OddWeek:         scheme.OddWeek,
+		EvenWeek:        scheme.EvenWeek,
+		CycledColumns:   scheme.CycledColumns,
+		CalendarColumns: scheme.CalendarColumns,
+	}
+}
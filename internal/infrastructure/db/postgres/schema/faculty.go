@@ -1,6 +1,10 @@
 package schema
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"schedule-generator/internal/domain/faculties"
 
 	"github.com/google/uuid"
@@ -9,20 +13,46 @@ import (
 type Faculty struct {
 	ID   uuid.UUID `gorm:"column:id;type:string;primaryKey"`
 	Name string    `gorm:"column:name;not null"`
+	// WorkingWeek bitmask of working days by time.Weekday, monday to saturday by default
+	WorkingWeek uint8 `gorm:"column:working_week;not null;default:126"`
 }
 
 // FacultyToSchema
-func FacultyToSchema(model *faculties.Faculty) *Faculty {
-	return &Faculty{
-		ID:   model.ID,
-		Name: model.Name,
+func FacultyToSchema(model *faculties.Faculty) (*Faculty, error) {
+	var workingWeek uint8
+	for _, d := range model.WorkingDays {
+		if d < time.Sunday || d > time.Saturday {
+			return nil, fmt.Errorf("unknown working day %d", d)
+		}
+
+		workingWeek |= 1 << d
+	}
+
+	if workingWeek == 0 {
+		return nil, errors.New("faculty has no working days")
 	}
+
+	return &Faculty{
+		ID:          model.ID,
+		Name:        model.Name,
+		WorkingWeek: workingWeek,
+	}, nil
 }
 
 // FacultyFromSchema
 func FacultyFromSchema(scheme *Faculty) *faculties.Faculty {
+	var workingDays []time.Weekday
+	// monday first
+	for i := range 7 {
+		d := time.Weekday((i + 1) % 7)
+		if scheme.WorkingWeek&(1<<d) != 0 {
+			workingDays = append(workingDays, d)
+		}
+	}
+
 	return &faculties.Faculty{
-		ID:   scheme.ID,
-		Name: scheme.Name,
+		ID:          scheme.ID,
+		Name:        scheme.Name,
+		WorkingDays: workingDays,
 	}
 }
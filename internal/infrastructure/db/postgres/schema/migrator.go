@@ -39,16 +39,52 @@ func (m *Migrator) Migrate(ctx context.Context) error {
 		&EduPlan{},
 		&Schedule{},
 		&ScheduleItem{},
+		&ScheduleItemTeacher{},
 		&Cabinet{},
+		&AcademicYear{},
+		&CsvProfile{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("make auto migration error: %w", err)
 	}
 
-	err = tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_cycled_schedule_item_weekday_lesson_subgroup_weektype ON schedule_items (schedule_id, weekday, lesson_number, subgroup, weektype) WHERE date IS NULL").Error
+	// constraint of item teachers was created without cascade delete, recreate it as declared on relation
+	err = tx.Exec(`
+		ALTER TABLE schedule_item_teachers
+			DROP CONSTRAINT IF EXISTS fk_schedule_items_teachers,
+			ADD CONSTRAINT fk_schedule_items_teachers FOREIGN KEY (schedule_item_id)
+				REFERENCES schedule_items (id) ON UPDATE CASCADE ON DELETE CASCADE
+	`).Error
 	if err != nil {
-		return fmt.Errorf("create unique index idx_cycled_schedule_item_weekday_lesson_subgroup_weektype error: %w", err)
+		return fmt.Errorf("recreate constraint fk_schedule_items_teachers error: %w", err)
+	}
+
+	// items used to store copy of cabinet address, link them to cabinets by address. Legacy columns are kept
+	// for items which address does not match any cabinet, they are linked when cabinet with the address is saved
+	if tx.Migrator().HasColumn(&ScheduleItem{}, "cabinet_building") {
+		err = tx.Exec(`
+			UPDATE schedule_items SET cabinet_id = cabinets.id
+			FROM cabinets
+			WHERE schedule_items.cabinet_id IS NULL
+				AND schedule_items.location_kind = 0
+				AND schedule_items.cabinet_building = cabinets.building
+				AND schedule_items.cabinet_auditorium = cabinets.auditorium
+		`).Error
+		if err != nil {
+			return fmt.Errorf("backfill schedule_items cabinet_id error: %w", err)
+		}
+	}
+
+	// replaced by index which takes week rotation into account
+	err = tx.Exec("DROP INDEX IF EXISTS idx_cycled_schedule_item_weekday_lesson_subgroup_weektype").Error
+	if err != nil {
+		return fmt.Errorf("drop index idx_cycled_schedule_item_weekday_lesson_subgroup_weektype error: %w", err)
+	}
+
+	err = tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_cycled_schedule_item_weekday_lesson_subgroup_weeks ON schedule_items (schedule_id, weekday, lesson_number, subgroup, weektype, cycle_weeks, weeks) WHERE date IS NULL").Error
+	if err != nil {
+		return fmt.Errorf("create unique index idx_cycled_schedule_item_weekday_lesson_subgroup_weeks error: %w", err)
 	}
 
 	err = tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_schedule_item_lesson_subgroup_date ON schedule_items (schedule_id, lesson_number, subgroup, date) WHERE weektype IS NULL").Error
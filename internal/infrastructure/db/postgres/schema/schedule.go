@@ -1,40 +1,68 @@
 package schema
 
 import (
+	"fmt"
 	"schedule-generator/internal/domain/schedules"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ScheduleItemTeacher struct {
+	ScheduleItemID int64     `gorm:"column:schedule_item_id;primaryKey"`
+	TeacherID      uuid.UUID `gorm:"column:teacher_id;type:string;primaryKey;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Teacher        *Teacher  `gorm:"foreignKey:teacher_id"`
+	Role           int8      `gorm:"column:role;not null;default:0"`
+}
+
 type ScheduleItem struct {
-	ID                int64        `gorm:"column:id;autoIncrement;primaryKey"`
-	ScheduleID        uuid.UUID    `gorm:"column:schedule_id;type:string;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Discipline        string       `gorm:"column:discipline;not null"`
-	TeacherID         uuid.UUID    `gorm:"column:teacher_id;type:string;not null;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
-	Teacher           *Teacher     `gorm:"foreignKey:teacher_id"`
-	Weekday           time.Weekday `gorm:"column:weekday;not null;default:1"`
-	StudentsCount     int16        `gorm:"column:students_count;not null;default:0"`
-	Date              *time.Time   `gorm:"column:date"`
-	LessonNumber      int8         `gorm:"column:lesson_number;not null;default:0"`
-	Subgroup          int8         `gorm:"column:subgroup;not null;default:0"`
-	Weektype          *int8        `gorm:"column:weektype"`
-	Weeknum           *int         `gorm:"column:weeknum"`
-	LessonType        int8         `gorm:"column:lesson_type;not null"`
-	CabinetAuditorium string       `gorm:"foreignKey:cabinet_auditorium"`
-	CabinetBuilding   string       `gorm:"foreignKey:cabinet_building"`
+	ID int64 `gorm:"column:id;autoIncrement;primaryKey"`
+	// ItemID identity of item in domain model
+	ItemID     uuid.UUID `gorm:"column:item_id;type:string;not null;uniqueIndex;default:gen_random_uuid()"`
+	ScheduleID uuid.UUID `gorm:"column:schedule_id;type:string;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Discipline string    `gorm:"column:discipline;not null"`
+	// TeacherID main teacher of lesson, full list of teachers is stored in Teachers
+	TeacherID     uuid.UUID             `gorm:"column:teacher_id;type:string;not null;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Teacher       *Teacher              `gorm:"foreignKey:teacher_id"`
+	Teachers      []ScheduleItemTeacher `gorm:"foreignKey:schedule_item_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Weekday       time.Weekday          `gorm:"column:weekday;not null;default:1"`
+	StudentsCount int16                 `gorm:"column:students_count;not null;default:0"`
+	Date          *time.Time            `gorm:"column:date"`
+	LessonNumber  int8                  `gorm:"column:lesson_number;not null;default:0"`
+	Duration      int8                  `gorm:"column:duration;not null;default:1"`
+	Subgroup      int8                  `gorm:"column:subgroup;not null;default:0"`
+	Weektype      *int8                 `gorm:"column:weektype"`
+	CycleWeeks    string                `gorm:"column:cycle_weeks;not null;default:''"`
+	Weeks         string                `gorm:"column:weeks;not null;default:''"`
+	Weeknum       *int                  `gorm:"column:weeknum"`
+	LessonType    int8                  `gorm:"column:lesson_type;not null"`
+	LocationKind  int8                  `gorm:"column:location_kind;not null;default:0"`
+	MeetingURL    string                `gorm:"column:meeting_url;not null;default:''"`
+	Address       string                `gorm:"column:address;not null;default:''"`
+	// CabinetID is empty for lessons outside of cabinet
+	CabinetID *uuid.UUID `gorm:"column:cabinet_id;type:string;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Cabinet   *Cabinet   `gorm:"foreignKey:cabinet_id"`
+	// LegacyCabinetBuilding and LegacyCabinetAuditorium address copied to items before cabinets were referenced by id.
+	// Read only for items which address did not match any cabinet on migration, until cabinet with the address is saved
+	LegacyCabinetBuilding   *string `gorm:"column:cabinet_building;->;-:migration"`
+	LegacyCabinetAuditorium *string `gorm:"column:cabinet_auditorium;->;-:migration"`
 }
 
 type Schedule struct {
 	ID         uuid.UUID `gorm:"column:id;type:string;primaryKey"`
-	EduGroupID uuid.UUID `gorm:"column:edu_group_id;type:string;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	EduGroupID uuid.UUID `gorm:"column:edu_group_id;type:string;uniqueIndex:schedule_edu_group_semester;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	EduGroup   *EduGroup `gorm:"foreignKey:edu_group_id"`
-	Semester   int       `gorm:"column:semester;not null"`
+	Semester   int       `gorm:"column:semester;uniqueIndex:schedule_edu_group_semester;not null"`
 	Type       int8      `gorm:"column:type;not null"`
 
+	WorkingWeek uint8 `gorm:"column:working_week;not null;default:126"`
+	Version     int   `gorm:"column:version;not null;default:1"`
+
 	// Cycled schedule specific
-	StartDate *time.Time `gorm:"column:start_date"`
-	EndDate   *time.Time `gorm:"column:end_date"`
+	StartDate   *time.Time `gorm:"column:start_date"`
+	EndDate     *time.Time `gorm:"column:end_date"`
+	CycleLength int        `gorm:"column:cycle_length;not null;default:2"`
 
 	Items []ScheduleItem `gorm:"foreignKey:schedule_id"`
 }
@@ -44,32 +72,46 @@ func ScheduleToSchema(model *schedules.Schedule) *Schedule {
 	items := model.ListItem()
 
 	schema := Schedule{
-		ID:         model.ID,
-		EduGroupID: model.EduGroupID,
-		Semester:   model.Semester,
-		Type:       int8(model.Type),
-		Items:      make([]ScheduleItem, len(items)),
+		ID:          model.ID,
+		EduGroupID:  model.EduGroupID,
+		Semester:    model.Semester,
+		Type:        int8(model.Type),
+		WorkingWeek: uint8(model.GetWorkingWeek()),
+		Version:     model.Version,
+		Items:       make([]ScheduleItem, len(items)),
 	}
 
 	if model.Type == schedules.ScheduleTypeCycled {
 		schema.StartDate = &model.Cycled.StartDate
 		schema.EndDate = &model.Cycled.EndDate
+		schema.CycleLength = model.Cycled.CycleLength
 	}
 
 	for i, item := range items {
 		si := ScheduleItem{
-			ScheduleID:        model.ID,
-			Discipline:        item.Discipline,
-			TeacherID:         item.TeacherID,
-			Weekday:           item.Weekday,
-			StudentsCount:     item.StudentsCount,
-			Date:              item.Date,
-			LessonNumber:      item.LessonNumber,
-			Subgroup:          item.Subgroup,
-			Weeknum:           item.Weeknum,
-			LessonType:        int8(item.LessonType),
-			CabinetAuditorium: item.Cabinet.Auditorium,
-			CabinetBuilding:   item.Cabinet.Building,
+			ItemID:        item.ID,
+			ScheduleID:    model.ID,
+			Discipline:    item.Discipline,
+			TeacherID:     item.MainTeacherID(),
+			Teachers:      make([]ScheduleItemTeacher, len(item.Teachers)),
+			Weekday:       item.Weekday,
+			StudentsCount: item.StudentsCount,
+			Date:          item.Date,
+			LessonNumber:  item.LessonNumber,
+			Duration:      item.Span(),
+			Subgroup:      item.Subgroup,
+			CycleWeeks:    item.CycleWeeks.String(),
+			Weeks:         item.Weeks.String(),
+			Weeknum:       item.Weeknum,
+			LessonType:    int8(item.LessonType),
+			LocationKind:  int8(item.Location.Kind),
+			MeetingURL:    item.Location.MeetingURL,
+			Address:       item.Location.Address,
+		}
+
+		if item.InCabinet() && item.Cabinet.ID != uuid.Nil {
+			cabinetID := item.Cabinet.ID
+			si.CabinetID = &cabinetID
 		}
 
 		if item.Weektype != nil {
@@ -77,6 +119,13 @@ func ScheduleToSchema(model *schedules.Schedule) *Schedule {
 			si.Weektype = &wt
 		}
 
+		for j, t := range item.Teachers {
+			si.Teachers[j] = ScheduleItemTeacher{
+				TeacherID: t.TeacherID,
+				Role:      int8(t.Role),
+			}
+		}
+
 		schema.Items[i] = si
 	}
 
@@ -84,82 +133,182 @@ func ScheduleToSchema(model *schedules.Schedule) *Schedule {
 }
 
 // ScheduleFromSchema
-func ScheduleFromSchema(schema *Schedule) *schedules.Schedule {
+func ScheduleFromSchema(schema *Schedule) (*schedules.Schedule, error) {
 	model := schedules.Schedule{
 		ID:         schema.ID,
 		EduGroupID: schema.EduGroupID,
 		Semester:   schema.Semester,
 		Type:       schedules.ScheduleType(schema.Type),
+		Version:    schema.Version,
 	}
 
 	switch model.Type {
 	case schedules.ScheduleTypeCycled:
 		if schema.StartDate == nil || schema.EndDate == nil {
-			return &model
+			return &model, nil
 		}
 
 		model.Cycled = &schedules.CycledSchedule{
-			StartDate: *schema.StartDate,
-			EndDate:   *schema.EndDate,
-			Items:     make(map[time.Weekday][]schedules.ScheduleItem),
+			StartDate:   *schema.StartDate,
+			EndDate:     *schema.EndDate,
+			CycleLength: schema.CycleLength,
+			WorkingWeek: schedules.WorkingWeek(schema.WorkingWeek),
+			Items:       make(map[time.Weekday][]schedules.ScheduleItem),
 		}
 
 		for _, item := range schema.Items {
 			if item.Weektype == nil {
-				continue
+				return nil, fmt.Errorf("schedule item %s of cycled schedule has no weektype", item.ItemID)
 			}
 
-			err := model.Cycled.AddItem(
+			cycleWeeks, err := schedules.ParseWeeks(item.CycleWeeks, schedules.MaxCycleLength)
+			if err != nil {
+				return nil, fmt.Errorf("parse cycle weeks of schedule item %s error: %w", item.ItemID, err)
+			}
+
+			weeks, err := schedules.ParseWeeks(item.Weeks, schedules.MaxWeekNumber)
+			if err != nil {
+				return nil, fmt.Errorf("parse weeks of schedule item %s error: %w", item.ItemID, err)
+			}
+
+			err = model.Cycled.AddItem(
 				item.Discipline,
-				item.TeacherID,
+				itemTeachersFromSchema(&item),
 				item.Weekday,
 				item.StudentsCount,
 				item.LessonNumber,
+				item.Duration,
 				item.Subgroup,
 				*item.Weektype,
+				cycleWeeks,
+				weeks,
 				item.LessonType,
-				schedules.Cabinet{
-					Auditorium: item.CabinetAuditorium,
-					Building:   item.CabinetBuilding,
+				schedules.Location{
+					Kind:       schedules.LocationKind(item.LocationKind),
+					MeetingURL: item.MeetingURL,
+					Address:    item.Address,
 				},
+				itemCabinetFromSchema(&item),
 			)
 
+			// items which are not loaded would be deleted on next save
 			if err != nil {
-				// ignore invalid data from db
-				continue
+				return nil, fmt.Errorf("load schedule item %s error: %w", item.ItemID, err)
 			}
+
+			items := model.Cycled.Items[item.Weekday]
+			items[len(items)-1].ID = item.ItemID
 		}
 	case schedules.ScheduleTypeCalendar:
 		model.Calendar = &schedules.CalendarSchedule{
-			Items: make([]schedules.ScheduleItem, 0, len(schema.Items)),
+			WorkingWeek: schedules.WorkingWeek(schema.WorkingWeek),
+			Items:       make([]schedules.ScheduleItem, 0, len(schema.Items)),
 		}
 
 		for _, item := range schema.Items {
 			if item.Weeknum == nil || item.Date == nil {
-				continue
+				return nil, fmt.Errorf("schedule item %s of calendar schedule has no date", item.ItemID)
 			}
 
 			err := model.Calendar.AddItem(
 				item.Discipline,
-				item.TeacherID,
+				itemTeachersFromSchema(&item),
 				*item.Date,
 				item.StudentsCount,
 				item.LessonNumber,
+				item.Duration,
 				item.Subgroup,
 				*item.Weeknum,
 				item.LessonType,
-				schedules.Cabinet{
-					Auditorium: item.CabinetAuditorium,
-					Building:   item.CabinetBuilding,
+				schedules.Location{
+					Kind:       schedules.LocationKind(item.LocationKind),
+					MeetingURL: item.MeetingURL,
+					Address:    item.Address,
 				},
+				itemCabinetFromSchema(&item),
 			)
 
 			if err != nil {
-				// ignore invalid data from db
-				continue
+				return nil, fmt.Errorf("load schedule item %s error: %w", item.ItemID, err)
 			}
+
+			model.Calendar.Items[len(model.Calendar.Items)-1].ID = item.ItemID
+		}
+	}
+
+	return &model, nil
+}
+
+// Equal reports whether items have the same stored values. Row ids and preloaded associations are not compared
+func (i *ScheduleItem) Equal(other *ScheduleItem) bool {
+	return i.ItemID == other.ItemID &&
+		i.ScheduleID == other.ScheduleID &&
+		i.Discipline == other.Discipline &&
+		i.TeacherID == other.TeacherID &&
+		slices.EqualFunc(i.Teachers, other.Teachers, func(a, b ScheduleItemTeacher) bool {
+			return a.TeacherID == b.TeacherID && a.Role == b.Role
+		}) &&
+		i.Weekday == other.Weekday &&
+		i.StudentsCount == other.StudentsCount &&
+		equalPtrFunc(i.Date, other.Date, time.Time.Equal) &&
+		i.LessonNumber == other.LessonNumber &&
+		i.Duration == other.Duration &&
+		i.Subgroup == other.Subgroup &&
+		equalPtrFunc(i.Weektype, other.Weektype, func(a, b int8) bool { return a == b }) &&
+		i.CycleWeeks == other.CycleWeeks &&
+		i.Weeks == other.Weeks &&
+		equalPtrFunc(i.Weeknum, other.Weeknum, func(a, b int) bool { return a == b }) &&
+		i.LessonType == other.LessonType &&
+		i.LocationKind == other.LocationKind &&
+		i.MeetingURL == other.MeetingURL &&
+		i.Address == other.Address &&
+		equalPtrFunc(i.CabinetID, other.CabinetID, func(a, b uuid.UUID) bool { return a == b })
+}
+
+func equalPtrFunc[T any](a, b *T, eq func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return eq(*a, *b)
+}
+
+// itemTeachersFromSchema returns teachers of item. Items saved before teachers list was introduced have main teacher only
+func itemTeachersFromSchema(item *ScheduleItem) []schedules.ItemTeacher {
+	if len(item.Teachers) == 0 {
+		return []schedules.ItemTeacher{{TeacherID: item.TeacherID, Role: schedules.TeacherRoleMain}}
+	}
+
+	teachers := make([]schedules.ItemTeacher, len(item.Teachers))
+	for i, t := range item.Teachers {
+		teachers[i] = schedules.ItemTeacher{
+			TeacherID: t.TeacherID,
+			Role:      schedules.TeacherRole(t.Role),
 		}
 	}
 
-	return &model
+	return teachers
+}
+
+// itemCabinetFromSchema returns cabinet of item with actual address from preloaded cabinet. Items not linked
+// to cabinet keep legacy address
+func itemCabinetFromSchema(item *ScheduleItem) schedules.Cabinet {
+	if item.CabinetID == nil {
+		if item.LocationKind != int8(schedules.LocationKindCabinet) || item.LegacyCabinetBuilding == nil || item.LegacyCabinetAuditorium == nil {
+			return schedules.Cabinet{}
+		}
+
+		return schedules.Cabinet{
+			Auditorium: *item.LegacyCabinetAuditorium,
+			Building:   *item.LegacyCabinetBuilding,
+		}
+	}
+
+	cabinet := schedules.Cabinet{ID: *item.CabinetID}
+	if item.Cabinet != nil {
+		cabinet.Auditorium = item.Cabinet.Auditorium
+		cabinet.Building = item.Cabinet.Building
+	}
+
+	return cabinet
 }